@@ -2,26 +2,34 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+	_ "time/tzdata" // the Gemini quota time zone must not depend on the host's zoneinfo
 
 	"golang-stock-scryper/internal/executor/config"
 	"golang-stock-scryper/internal/executor/delivery/consumer"
+	delivery "golang-stock-scryper/internal/executor/delivery/http"
 	"golang-stock-scryper/internal/executor/repository"
 	"golang-stock-scryper/internal/executor/service"
 	"golang-stock-scryper/internal/executor/strategy"
 	"golang-stock-scryper/pkg/common"
 	"golang-stock-scryper/pkg/decoder"
 	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/metrics"
 	"golang-stock-scryper/pkg/postgres"
 	"golang-stock-scryper/pkg/redis"
 	"golang-stock-scryper/pkg/telegram"
+	"golang-stock-scryper/pkg/tracing"
 
 	"google.golang.org/genai"
 
+	"github.com/labstack/echo/v4"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
@@ -53,6 +61,11 @@ func runServe(cmd *cobra.Command, args []string) {
 
 	appLogger.Info("Starting Execution Service", zap.String("name", cfg.App.Name))
 
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing, cfg.App)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
+
 	// Initialize database
 	postgresCfg := postgres.Config{
 		Host:            cfg.Database.Host,
@@ -113,10 +126,12 @@ func runServe(cmd *cobra.Command, args []string) {
 	stockNewsSummaryRepo := repository.NewStockNewsSummaryRepository(db.DB)
 	stockPositionsRepo := repository.NewStockPositionsRepository(db.DB)
 	stocksRepo := repository.NewStocksRepository(db.DB)
-	yahooFinanceRepo, err := repository.NewYahooFinanceRepository(cfg, appLogger)
+	yahooFinanceRepo, err := repository.NewYahooFinanceRepository(cfg, appLogger, redisClient.Client)
 	stockSignalRepo := repository.NewStockSignalRepository(db.DB)
 	stockPositionMonitoringRepo := repository.NewStockPositionsMonitoringsRepository(db.DB)
-	tradingViewRepo := repository.NewTradingViewRepository(cfg, appLogger)
+	tradingViewRepo := repository.NewTradingViewRepository(cfg, appLogger, redisClient.Client)
+	retentionRepo := repository.NewRetentionRepository(db.DB)
+	webhookRepo := repository.NewWebhookRepository(db.DB)
 
 	if err != nil {
 		appLogger.Fatal("Failed to initialize Yahoo Finance repository", zap.Error(err))
@@ -127,12 +142,13 @@ func runServe(cmd *cobra.Command, args []string) {
 	switch cfg.AI.Provider {
 	case "gemini":
 		genAiClient, err := genai.NewClient(context.Background(), &genai.ClientConfig{
-			APIKey: cfg.Gemini.APIKey,
+			APIKey:     cfg.Gemini.APIKey,
+			HTTPClient: &http.Client{Transport: metrics.NewTransport(metrics.ProviderGemini, tracing.NewTransport(metrics.ProviderGemini, nil))},
 		})
 		if err != nil {
 			appLogger.Fatal("Failed to initialize Gemini AI client", zap.Error(err))
 		}
-		repo, err := repository.NewGeminiAIRepository(cfg, appLogger, genAiClient)
+		repo, err := repository.NewGeminiAIRepository(cfg, appLogger, genAiClient, redisClient.Client)
 		if err != nil {
 			appLogger.Fatal("Failed to initialize Gemini AI repository", zap.Error(err))
 		}
@@ -184,17 +200,23 @@ func runServe(cmd *cobra.Command, args []string) {
 			redisClient,
 			stockPositionsRepo,
 		),
+		strategy.NewDataRetentionStrategy(cfg, appLogger, retentionRepo),
 	}
 
 	// Initialize executor service
-	executorSvc := service.NewExecutorService(cfg, redisClient.Client, jobRepo, historyRepo, appLogger, strategies)
+	executorSvc := service.NewExecutorService(cfg, redisClient.Client, jobRepo, historyRepo, webhookRepo, appLogger, strategies)
 	stockAnalyzerMultiTimeframeSvc := service.NewStockAnalyzerMultiTimeframeService(cfg, appLogger, redisClient.Client, aiRepo, yahooFinanceRepo, stockNewsSummaryRepo, stockSignalRepo, telegramNotifier)
 	stockPositionMonitoringSvc := service.NewStockPositionMonitoringMultiTimeframeService(cfg, appLogger, redisClient.Client, aiRepo, yahooFinanceRepo, stockPositionsRepo, stockNewsSummaryRepo, stockPositionMonitoringRepo, telegramNotifier)
 
+	webhookDispatcher := service.NewWebhookDispatcher(cfg.Webhook, webhookRepo, appLogger)
+
 	// Initialize and start the Redis consumer
-	redisConsumer := consumer.NewRedisConsumer(cfg, redisClient.Client, executorSvc, stockAnalyzerMultiTimeframeSvc, stockPositionMonitoringSvc, appLogger)
+	redisConsumer := consumer.NewRedisConsumer(cfg, redisClient.Client, executorSvc, stockAnalyzerMultiTimeframeSvc, stockPositionMonitoringSvc, webhookDispatcher, appLogger)
 	redisConsumer.Start(ctx)
 
+	healthSvc := service.NewHealthService(db.DB, redisClient.Client, telegramNotifier, executorSvc, redisConsumer, appLogger)
+	httpServer := startHTTPServer(cfg.HTTP, healthSvc, appLogger)
+
 	appLogger.Info("Execution service started. Waiting for tasks...")
 
 	// Wait for interrupt signal to gracefully shut down the service
@@ -203,11 +225,47 @@ func runServe(cmd *cobra.Command, args []string) {
 	<-quit
 
 	appLogger.Info("Shutting down execution service...")
+	if httpServer != nil {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			appLogger.Error("Failed to shut down HTTP server", logger.ErrorField(err))
+		}
+		cancelShutdown()
+	}
 	cancel()
+	executorSvc.Close()
 	redisConsumer.Stop()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", logger.ErrorField(err))
+	}
+	cancelShutdown()
 	appLogger.Info("Execution service stopped.")
 }
 
+// startHTTPServer serves /metrics, the health probes and the admin view on the configured port,
+// unless it is 0, and returns the server so it can be shut down.
+func startHTTPServer(cfg config.HTTP, healthSvc service.HealthService, appLogger *logger.Logger) *echo.Echo {
+	if cfg.Port <= 0 {
+		return nil
+	}
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+	delivery.NewHealthHandler(healthSvc).RegisterRoutes(e)
+
+	go func() {
+		addr := fmt.Sprintf(":%d", cfg.Port)
+		appLogger.Info("HTTP server starting", logger.Field("address", addr))
+		if err := e.Start(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			appLogger.Error("HTTP server failed", logger.ErrorField(err))
+		}
+	}()
+	return e
+}
+
 func main() {
 	rootCmd := &cobra.Command{Use: "execution-service"}
 
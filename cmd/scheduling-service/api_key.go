@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"golang-stock-scryper/internal/scheduler/config"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/internal/scheduler/repository"
+	"golang-stock-scryper/internal/scheduler/service"
+	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/postgres"
+
+	"github.com/spf13/cobra"
+)
+
+// cliActor is recorded as the creator of API keys managed from the command line.
+const cliActor = "cli"
+
+// newAPIKeyCmd returns the commands that manage API keys directly in the database, so the first
+// admin key can be created before anyone can authenticate against the API.
+func newAPIKeyCmd() *cobra.Command {
+	apiKeyCmd := &cobra.Command{
+		Use:   "api-key",
+		Short: "Manage API keys of the scheduling API",
+	}
+	apiKeyCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "configs/config-scheduler.yaml", "Path to the configuration file")
+
+	var name, role string
+	var expiresIn time.Duration
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an API key and print it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req := &dto.CreateAPIKeyRequest{Name: name, Role: role}
+			if expiresIn > 0 {
+				expiresAt := time.Now().Add(expiresIn)
+				req.ExpiresAt = &expiresAt
+			}
+			return withAuthService(func(ctx context.Context, authSvc service.AuthService) error {
+				key, err := authSvc.CreateAPIKey(ctx, req)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Created API key %d (%s, %s). Store it now, it cannot be shown again:\n%s\n", key.ID, key.Name, key.Role, key.Key)
+				return nil
+			})
+		},
+	}
+	createCmd.Flags().StringVar(&name, "name", "", "Name of the caller the key belongs to")
+	createCmd.Flags().StringVar(&role, "role", "viewer", "Role of the key: viewer, operator or admin")
+	createCmd.Flags().DurationVar(&expiresIn, "expires-in", 0, "Lifetime of the key, e.g. 720h; 0 for a key that does not expire")
+	_ = createCmd.MarkFlagRequired("name")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List API keys",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withAuthService(func(ctx context.Context, authSvc service.AuthService) error {
+				keys, err := authSvc.ListAPIKeys(ctx)
+				if err != nil {
+					return err
+				}
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tNAME\tROLE\tPREFIX\tCREATED\tLAST USED\tEXPIRES\tREVOKED")
+				for _, key := range keys {
+					fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.Role, key.Prefix,
+						key.CreatedAt.Format(time.RFC3339), formatCLITime(key.LastUsedAt.Time, key.LastUsedAt.Valid),
+						formatCLITime(key.ExpiresAt.Time, key.ExpiresAt.Valid), formatCLITime(key.RevokedAt.Time, key.RevokedAt.Valid))
+				}
+				return w.Flush()
+			})
+		},
+	}
+
+	revokeCmd := &cobra.Command{
+		Use:   "revoke <id>",
+		Short: "Revoke an API key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := strconv.ParseUint(args[0], 10, 32)
+			if err != nil {
+				return fmt.Errorf("invalid API key ID %q", args[0])
+			}
+			return withAuthService(func(ctx context.Context, authSvc service.AuthService) error {
+				if err := authSvc.RevokeAPIKey(ctx, uint(id)); err != nil {
+					return err
+				}
+				fmt.Printf("Revoked API key %d\n", id)
+				return nil
+			})
+		},
+	}
+
+	apiKeyCmd.AddCommand(createCmd, listCmd, revokeCmd)
+	return apiKeyCmd
+}
+
+// withAuthService runs fn with an auth service connected to the configured database.
+func withAuthService(fn func(ctx context.Context, authSvc service.AuthService) error) error {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	appLogger, err := logger.New(cfg.Logger.Level, cfg.Logger.Encoding)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer func() { _ = appLogger.Sync() }()
+
+	db, err := postgres.NewDB(newPostgresConfig(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	if sqlDB, err := db.DB.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	authSvc := service.NewAuthService(repository.NewAPIKeyRepository(db.DB), cfg.Auth, appLogger)
+	return fn(service.WithActor(context.Background(), cliActor), authSvc)
+}
+
+// formatCLITime formats an optional time for the API key list.
+func formatCLITime(t time.Time, valid bool) string {
+	if !valid {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
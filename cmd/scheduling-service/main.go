@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // schedule time zones must not depend on the host's zoneinfo
 
 	"golang-stock-scryper/internal/scheduler/config"
 	delivery "golang-stock-scryper/internal/scheduler/delivery/http"
@@ -16,8 +17,11 @@ import (
 	"golang-stock-scryper/internal/scheduler/repository"
 	"golang-stock-scryper/internal/scheduler/service"
 	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/metrics"
+	"golang-stock-scryper/pkg/middleware"
 	"golang-stock-scryper/pkg/postgres"
 	"golang-stock-scryper/pkg/redis"
+	"golang-stock-scryper/pkg/tracing"
 
 	"github.com/labstack/echo/v4"
 	"github.com/spf13/cobra"
@@ -52,19 +56,13 @@ func runServe(cmd *cobra.Command, args []string) {
 
 	appLogger.Info("Starting Scheduling Service", logger.Field("name", cfg.App.Name))
 
-	// Initialize database
-	postgresCfg := postgres.Config{
-		Host:            cfg.Database.Host,
-		Port:            cfg.Database.Port,
-		User:            cfg.Database.User,
-		Password:        cfg.Database.Password,
-		DBName:          cfg.Database.DBName,
-		SSLMode:         cfg.Database.SSLMode,
-		MaxIdleConns:    cfg.Database.MaxIdleConns,
-		MaxOpenConns:    cfg.Database.MaxOpenConns,
-		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing, cfg.App)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize tracing", logger.ErrorField(err))
 	}
-	db, err := postgres.NewDB(postgresCfg)
+
+	// Initialize database
+	db, err := postgres.NewDB(newPostgresConfig(cfg))
 	if err != nil {
 		appLogger.Fatal("Failed to initialize database", logger.ErrorField(err))
 	}
@@ -90,19 +88,36 @@ func runServe(cmd *cobra.Command, args []string) {
 	jobRepo := repository.NewJobRepository(db.DB)
 	scheduleRepo := repository.NewTaskScheduleRepository(db.DB)
 	historyRepo := repository.NewTaskExecutionHistoryRepository(db.DB)
+	versionRepo := repository.NewJobVersionRepository(db.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(db.DB)
+	webhookRepo := repository.NewWebhookRepository(db.DB)
+	txManager := repository.NewTransactionManager(db.DB)
 
 	// Initialize services
 	pollingInterval, err := time.ParseDuration(cfg.Scheduler.PollingInterval)
 	if err != nil {
 		appLogger.Fatal("Invalid polling interval", logger.ErrorField(err))
 	}
-	schedulerSvc := service.NewSchedulerService(jobRepo, scheduleRepo, historyRepo, redisClient.Client, appLogger, pollingInterval, cfg)
-	jobSvc := service.NewJobService(jobRepo, appLogger)
-	scheduleSvc := service.NewScheduleService(scheduleRepo, appLogger)
-	historySvc := service.NewExecutionHistoryService(historyRepo, appLogger)
+	reaperSettings, err := service.NewReaperSettings(cfg.Reaper)
+	if err != nil {
+		appLogger.Fatal("Invalid reaper configuration", logger.ErrorField(err))
+	}
+	taskPublisher := service.NewTaskPublisher(historyRepo, webhookRepo, redisClient.Client, appLogger, cfg)
+	schedulerSvc := service.NewSchedulerService(jobRepo, scheduleRepo, historyRepo, taskPublisher, appLogger, pollingInterval, cfg)
+	jobSvc := service.NewJobService(jobRepo, versionRepo, txManager, taskPublisher, appLogger)
+	scheduleSvc := service.NewScheduleService(scheduleRepo, txManager, appLogger)
+	historySvc := service.NewExecutionHistoryService(historyRepo, webhookRepo, taskPublisher, appLogger)
+	reaper := service.NewExecutionReaper(historyRepo, webhookRepo, appLogger, reaperSettings)
+	authSvc := service.NewAuthService(apiKeyRepo, cfg.Auth, appLogger)
+	webhookSvc := service.NewWebhookService(webhookRepo, jobRepo, appLogger)
+	deadLetterSvc := service.NewDeadLetterService(redisClient.Client, appLogger)
+	if !cfg.Auth.Enabled {
+		appLogger.Warn("API authentication is disabled, every caller can use every route")
+	}
 
 	// Start scheduler service
 	go schedulerSvc.Start(ctx)
+	go reaper.Start(ctx)
 
 	// Initialize Echo server
 	e := echo.New()
@@ -110,9 +125,10 @@ func runServe(cmd *cobra.Command, args []string) {
 
 	// Initialize handlers and routes
 	jobHandler := delivery.NewJobHandler(jobSvc, appLogger)
-	apiV1 := e.Group("/api/v1")
+	apiV1 := e.Group("/api/v1", middleware.NewTracingMiddleware(), delivery.ActorMiddleware, delivery.NewAuthMiddleware(authSvc, cfg.Auth.Enabled, appLogger))
 	jobsGroup := apiV1.Group("/jobs")
 	jobHandler.RegisterRoutes(jobsGroup)
+	jobHandler.RegisterJobTypeRoutes(apiV1.Group("/job-types"))
 
 	scheduleHandler := delivery.NewScheduleHandler(scheduleSvc, appLogger)
 	schedulesGroup := apiV1.Group("/schedules")
@@ -123,7 +139,17 @@ func runServe(cmd *cobra.Command, args []string) {
 	historyHandler.RegisterRoutes(executionsGroup)
 	historyHandler.RegisterJobRoutes(jobsGroup)
 
+	apiKeyHandler := delivery.NewAPIKeyHandler(authSvc, appLogger)
+	apiKeyHandler.RegisterRoutes(apiV1.Group("/api-keys"))
+
+	webhookHandler := delivery.NewWebhookHandler(webhookSvc, appLogger)
+	webhookHandler.RegisterRoutes(apiV1.Group("/webhooks"))
+
+	deadLetterHandler := delivery.NewDeadLetterHandler(deadLetterSvc, appLogger)
+	deadLetterHandler.RegisterRoutes(apiV1.Group("/dead-letters"))
+
 	e.GET("/swagger/*", swagger.WrapHandler)
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
 
 	// Start server
 	go func() {
@@ -146,10 +172,28 @@ func runServe(cmd *cobra.Command, args []string) {
 	if err := e.Shutdown(shutdownCtx); err != nil {
 		appLogger.Fatal("Server forced to shutdown", logger.ErrorField(err))
 	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush traces", logger.ErrorField(err))
+	}
 
 	appLogger.Info("Server exiting")
 }
 
+// newPostgresConfig returns the database settings of the scheduling service.
+func newPostgresConfig(cfg *config.Config) postgres.Config {
+	return postgres.Config{
+		Host:            cfg.Database.Host,
+		Port:            cfg.Database.Port,
+		User:            cfg.Database.User,
+		Password:        cfg.Database.Password,
+		DBName:          cfg.Database.DBName,
+		SSLMode:         cfg.Database.SSLMode,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+	}
+}
+
 // @title Job Scheduler API
 // @version 1.0
 // @description This is a sample server for a job scheduler.
@@ -160,12 +204,19 @@ func runServe(cmd *cobra.Command, args []string) {
 // @license.name Apache 2.0
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 // @BasePath /api/v1
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description API key or JWT as "Bearer <token>"
 func main() {
 	rootCmd := &cobra.Command{Use: "scheduling-service"}
 
 	serveCmd.Flags().StringVarP(&configPath, "config", "c", "configs/config-scheduler.yaml", "Path to the configuration file")
 
-	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(serveCmd, newAPIKeyCmd())
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error executing scheduling-service CLI: %s\n", err)
 		os.Exit(1)
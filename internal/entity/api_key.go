@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Role controls which scheduling API routes a caller may use. Each role may use the routes of
+// the roles below it.
+type Role string
+
+const (
+	// RoleViewer may read jobs, schedules, versions, executions and dead letters.
+	RoleViewer Role = "viewer"
+	// RoleOperator may also trigger, pause and resume jobs, cancel executions and replay dead
+	// letters.
+	RoleOperator Role = "operator"
+	// RoleAdmin may also create, update, delete and roll back jobs and schedules, trigger jobs with
+	// a payload override, purge dead letters and manage API keys.
+	RoleAdmin Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer:   1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// IsValid reports whether r is a known role.
+func (r Role) IsValid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows reports whether a caller with role r may use routes that require the given role.
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
+// APIKey is a credential for the scheduling API. Only the SHA-256 hash of the key is stored;
+// the key itself is shown once, when it is created.
+type APIKey struct {
+	ID         uint      `gorm:"primaryKey"`
+	Name       string    `gorm:"type:varchar(100);not null"` // identifies the caller in logs and job versions
+	Role       Role      `gorm:"type:varchar(20);not null"`
+	Prefix     string    `gorm:"type:varchar(20);not null"` // first characters of the key, to tell keys apart
+	KeyHash    string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedBy  string    `gorm:"type:varchar(255);not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	LastUsedAt sql.NullTime
+	ExpiresAt  sql.NullTime
+	RevokedAt  sql.NullTime
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsUsableAt reports whether the key may authenticate requests at the given time.
+func (k *APIKey) IsUsableAt(t time.Time) bool {
+	if k.RevokedAt.Valid {
+		return false
+	}
+	return !k.ExpiresAt.Valid || t.Before(k.ExpiresAt.Time)
+}
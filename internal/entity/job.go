@@ -15,22 +15,57 @@ const (
 	JobTypeStockPriceAlert      JobType = "stock_price_alert"
 	JobTypeStockAnalyzer        JobType = "stock_analyzer"
 	JobTypeStockPositionMonitor JobType = "stock_position_monitor"
+	JobTypeDataRetention        JobType = "data_retention"
 )
 
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyPolicyAllow runs executions of the same job side by side.
+	ConcurrencyPolicyAllow ConcurrencyPolicy = "allow"
+	// ConcurrencyPolicyForbid skips an execution while an earlier one of the same job is still running.
+	ConcurrencyPolicyForbid ConcurrencyPolicy = "forbid"
+	// ConcurrencyPolicyReplace cancels earlier running executions of the same job.
+	ConcurrencyPolicyReplace ConcurrencyPolicy = "replace"
+	// ConcurrencyPolicyQueue waits until earlier executions of the same job have finished.
+	ConcurrencyPolicyQueue ConcurrencyPolicy = "queue"
+)
+
+// IsValid reports whether the policy is a known concurrency policy.
+func (p ConcurrencyPolicy) IsValid() bool {
+	switch p {
+	case ConcurrencyPolicyAllow, ConcurrencyPolicyForbid, ConcurrencyPolicyReplace, ConcurrencyPolicyQueue:
+		return true
+	}
+	return false
+}
+
 type Job struct {
-	ID          uint                   `gorm:"primaryKey"`
-	Name        string                 `gorm:"type:varchar(255);not null"`
-	Description string                 `gorm:"type:text"`
-	Type        JobType                `gorm:"type:varchar(50);not null"`
-	Payload     datatypes.JSON         `gorm:"type:jsonb;not null"`
-	RetryPolicy datatypes.JSON         `gorm:"type:jsonb"`
-	Timeout     int                    `gorm:"default:60"`
-	CreatedAt   time.Time              `gorm:"autoCreateTime"`
-	UpdatedAt   time.Time              `gorm:"autoUpdateTime"`
-	Schedules   []TaskSchedule         `gorm:"foreignKey:JobID"`
-	Histories   []TaskExecutionHistory `gorm:"foreignKey:JobID"`
+	ID                uint                   `gorm:"primaryKey"`
+	Name              string                 `gorm:"type:varchar(255);not null"`
+	Description       string                 `gorm:"type:text"`
+	Type              JobType                `gorm:"type:varchar(50);not null"`
+	Payload           datatypes.JSON         `gorm:"type:jsonb;not null"`
+	RetryPolicy       datatypes.JSON         `gorm:"type:jsonb"`
+	Timeout           int                    `gorm:"default:60"`
+	ConcurrencyPolicy ConcurrencyPolicy      `gorm:"type:varchar(20);not null;default:allow"`
+	CreatedAt         time.Time              `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time              `gorm:"autoUpdateTime"`
+	Schedules         []TaskSchedule         `gorm:"foreignKey:JobID"`
+	Histories         []TaskExecutionHistory `gorm:"foreignKey:JobID"`
+	Dependencies      []JobDependency        `gorm:"foreignKey:JobID"`
+	PauseState
 }
 
 func (Job) TableName() string {
 	return "jobs"
 }
+
+// DependsOnJobIDs returns the IDs of the upstream jobs this job depends on.
+func (j *Job) DependsOnJobIDs() []uint {
+	ids := make([]uint, 0, len(j.Dependencies))
+	for _, dependency := range j.Dependencies {
+		ids = append(ids, dependency.DependsOnJobID)
+	}
+	return ids
+}
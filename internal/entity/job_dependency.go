@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// JobDependency declares that JobID runs after a successful execution of DependsOnJobID.
+type JobDependency struct {
+	JobID          uint      `gorm:"primaryKey"`
+	DependsOnJobID uint      `gorm:"primaryKey"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+func (JobDependency) TableName() string {
+	return "job_dependencies"
+}
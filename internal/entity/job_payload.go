@@ -0,0 +1,111 @@
+package entity
+
+import "encoding/json"
+
+// HTTPJobDetails defines the structure for HTTP job payloads.
+type HTTPJobDetails struct {
+	URL     string            `json:"url" schema:"required"`
+	Method  string            `json:"method" schema:"enum=GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// StockNewsScraperPayload defines the payload for the stock news scraper job.
+type StockNewsScraperPayload struct {
+	AdditionalStockCodes []string       `json:"additional_stock_codes"`
+	DelayInterval        int            `json:"delay_interval" schema:"min=0"`
+	MaxNews              int            `json:"max_news" schema:"min=0"`
+	MaxNewsAgeInDays     int            `json:"max_news_age_in_days" schema:"min=0"`
+	BlackListedDomains   []string       `json:"blacklisted_domains"`
+	MaxConcurrent        int            `json:"max_concurrent" schema:"min=0"`
+	AdditionalKeywords   []string       `json:"additional_keywords"`
+	UseStockList         bool           `json:"use_stock_list"`
+	DefaultQueryParam    string         `json:"default_query_param"`
+	SourcePriority       map[string]int `json:"source_priority"`
+	UseStockPosition     bool           `json:"use_stock_position"`
+}
+
+// StockNewsSummaryPayload defines the payload for the stock news summary job.
+type StockNewsSummaryPayload struct {
+	MinToSummarizeNews int     `json:"min_to_summarize_news" schema:"min=0"`
+	MinConfidenceScore float64 `json:"min_confidence_score" schema:"min=0"`
+	MaxNewsAgeInDays   int     `json:"max_news_age_in_days" schema:"min=0"`
+	MaxNewsEachStock   int     `json:"max_news_each_stock" schema:"min=0"`
+}
+
+// StockPriceAlertPayload defines the payload for stock price alert.
+type StockPriceAlertPayload struct {
+	DataInterval                string  `json:"data_interval"`
+	DataRange                   string  `json:"data_range"`
+	AlertTriggerWindowDuration  string  `json:"alert_trigger_window_duration"`
+	AlertCacheDuration          string  `json:"alert_cache_duration"`
+	AlertResendThresholdPercent float64 `json:"alert_resend_threshold_percent" schema:"min=0"`
+}
+
+// StockAnalyzerPayload defines the payload for the stock analyzer job.
+type StockAnalyzerPayload struct {
+	SkipStocks       []string               `json:"skip_stocks"`
+	UseTradingView   bool                   `json:"use_trading_view"`
+	UseStockList     bool                   `json:"use_stock_list"`
+	AdditionalStocks []string               `json:"additional_stocks"`
+	TradingViewData  map[string]interface{} `json:"trading_view_data"`
+}
+
+// StockPositionMonitorPayload defines the payload for the stock position monitor job.
+// The monitor currently reads its settings from each stock position, so these fields
+// are accepted but not used.
+type StockPositionMonitorPayload struct {
+	Range     string `json:"range"`
+	Interval  string `json:"interval"`
+	SendNotif bool   `json:"send_notif"`
+}
+
+const (
+	// RetentionActionDelete deletes expired rows.
+	RetentionActionDelete = "delete"
+	// RetentionActionArchive writes expired rows to a compressed archive file and then deletes them.
+	RetentionActionArchive = "archive"
+	// RetentionActionClear keeps expired rows but empties their bulky column.
+	RetentionActionClear = "clear"
+)
+
+// DataRetentionPayload defines the payload for the data retention job.
+type DataRetentionPayload struct {
+	BatchSize int               `json:"batch_size" schema:"min=0"` // rows per batch, defaults to retention.default_batch_size
+	Policies  []RetentionPolicy `json:"policies" schema:"required"`
+}
+
+// RetentionPolicy defines how long the rows of a single table are kept.
+type RetentionPolicy struct {
+	Table         string `json:"table" schema:"required,enum=task_execution_history|stock_news|stock_signals|stock_position_monitorings"`
+	RetentionDays int    `json:"retention_days" schema:"required,min=1"`
+	Action        string `json:"action" schema:"required,enum=delete|archive|clear"`
+}
+
+// JobTypeSpec describes a job type and the shape of its payload.
+type JobTypeSpec struct {
+	Type        JobType
+	Description string
+	Payload     interface{} // zero value of the payload struct the strategy decodes
+}
+
+// JobTypeSpecs lists every job type the executor can run, in display order.
+var JobTypeSpecs = []JobTypeSpec{
+	{Type: JobTypeHTTP, Description: "Sends an HTTP request", Payload: HTTPJobDetails{}},
+	{Type: JobTypeStockNewsScraper, Description: "Scrapes stock news from Google News RSS and analyzes it", Payload: StockNewsScraperPayload{}},
+	{Type: JobTypeStockNewsSummary, Description: "Summarizes the scraped news of each stock", Payload: StockNewsSummaryPayload{}},
+	{Type: JobTypeStockPriceAlert, Description: "Sends alerts when stock prices reach their take profit or stop loss", Payload: StockPriceAlertPayload{}},
+	{Type: JobTypeStockAnalyzer, Description: "Analyzes stocks to produce buy and sell signals", Payload: StockAnalyzerPayload{}},
+	{Type: JobTypeStockPositionMonitor, Description: "Enqueues monitoring of active user stock positions", Payload: StockPositionMonitorPayload{}},
+	{Type: JobTypeDataRetention, Description: "Deletes, archives or trims old rows of tables that grow without bound", Payload: DataRetentionPayload{}},
+}
+
+// LookupJobType returns the spec of a job type.
+func LookupJobType(jobType JobType) (JobTypeSpec, bool) {
+	for _, spec := range JobTypeSpecs {
+		if spec.Type == jobType {
+			return spec, true
+		}
+	}
+	return JobTypeSpec{}, false
+}
@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type JobVersionAction string
+
+const (
+	JobVersionCreated         JobVersionAction = "created"
+	JobVersionUpdated         JobVersionAction = "updated"
+	JobVersionDeleted         JobVersionAction = "deleted"
+	JobVersionRolledBack      JobVersionAction = "rolled_back"
+	JobVersionScheduleCreated JobVersionAction = "schedule_created"
+	JobVersionScheduleUpdated JobVersionAction = "schedule_updated"
+	JobVersionScheduleDeleted JobVersionAction = "schedule_deleted"
+)
+
+// JobVersion is an immutable record of a change to a job definition or one of its schedules.
+// Versions are numbered per job and outlive the job, so a deleted job can be restored.
+type JobVersion struct {
+	ID        uint             `gorm:"primaryKey"`
+	JobID     uint             `gorm:"not null"`
+	Version   int              `gorm:"not null"` // 1 for the first version of a job
+	Action    JobVersionAction `gorm:"type:varchar(30);not null"`
+	Actor     string           `gorm:"type:varchar(255);not null"` // who made the change
+	Before    datatypes.JSON   `gorm:"type:jsonb"`                 // JobSnapshot before the change, null when the job was created
+	After     datatypes.JSON   `gorm:"type:jsonb"`                 // JobSnapshot after the change, null when the job was deleted
+	CreatedAt time.Time        `gorm:"autoCreateTime"`
+}
+
+func (JobVersion) TableName() string {
+	return "job_versions"
+}
+
+// JobSnapshot is the definition of a job as recorded in its versions. Execution times and
+// pause state are runtime state and are not part of it.
+type JobSnapshot struct {
+	Name              string             `json:"name"`
+	Description       string             `json:"description"`
+	Type              JobType            `json:"type"`
+	Payload           json.RawMessage    `json:"payload"`
+	RetryPolicy       json.RawMessage    `json:"retry_policy"`
+	Timeout           int                `json:"timeout"`
+	ConcurrencyPolicy ConcurrencyPolicy  `json:"concurrency_policy"`
+	Schedules         []ScheduleSnapshot `json:"schedules"`
+	DependsOn         []uint             `json:"depends_on"`
+}
+
+// ScheduleSnapshot is the definition of a schedule within a JobSnapshot.
+type ScheduleSnapshot struct {
+	ID                  uint          `json:"id"`
+	CronExpression      string        `json:"cron_expression"`
+	IsActive            bool          `json:"is_active"`
+	Timezone            string        `json:"timezone"`
+	MisfirePolicy       MisfirePolicy `json:"misfire_policy"`
+	MisfireGraceSeconds int           `json:"misfire_grace_seconds"`
+	MisfireMaxRuns      int           `json:"misfire_max_runs"`
+}
+
+// NewJobSnapshot captures the definition of a job with its schedules and dependencies.
+func NewJobSnapshot(job *Job) JobSnapshot {
+	snapshot := JobSnapshot{
+		Name:              job.Name,
+		Description:       job.Description,
+		Type:              job.Type,
+		Payload:           json.RawMessage(job.Payload),
+		RetryPolicy:       json.RawMessage(job.RetryPolicy),
+		Timeout:           job.Timeout,
+		ConcurrencyPolicy: job.ConcurrencyPolicy,
+		Schedules:         make([]ScheduleSnapshot, 0, len(job.Schedules)),
+		DependsOn:         job.DependsOnJobIDs(),
+	}
+	if len(snapshot.Payload) == 0 {
+		snapshot.Payload = json.RawMessage("{}")
+	}
+	if len(snapshot.RetryPolicy) == 0 {
+		snapshot.RetryPolicy = json.RawMessage("null")
+	}
+	for _, schedule := range job.Schedules {
+		snapshot.Schedules = append(snapshot.Schedules, ScheduleSnapshot{
+			ID:                  schedule.ID,
+			CronExpression:      schedule.CronExpression,
+			IsActive:            schedule.IsActive,
+			Timezone:            schedule.Timezone,
+			MisfirePolicy:       schedule.MisfirePolicy,
+			MisfireGraceSeconds: schedule.MisfireGraceSeconds,
+			MisfireMaxRuns:      schedule.MisfireMaxRuns,
+		})
+	}
+	return snapshot
+}
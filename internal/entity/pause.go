@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PauseState suspends a job or a schedule. A paused job or schedule stays paused until it
+// is resumed or, when PausedUntil is set, until that time has passed.
+type PauseState struct {
+	Paused      bool `gorm:"not null;default:false"`
+	PausedUntil sql.NullTime
+}
+
+// IsPausedAt reports whether the pause is in effect at now.
+func (p PauseState) IsPausedAt(now time.Time) bool {
+	return p.Paused && (!p.PausedUntil.Valid || p.PausedUntil.Time.After(now))
+}
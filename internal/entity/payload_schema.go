@@ -0,0 +1,202 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PayloadSchema is the subset of JSON Schema used to describe job payloads. It is derived
+// from the payload structs, so the schema and the strategies never drift apart.
+type PayloadSchema struct {
+	Type       string                    `json:"type,omitempty"` // empty means any JSON value
+	Properties map[string]*PayloadSchema `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+	Items      *PayloadSchema            `json:"items,omitempty"`
+	// AdditionalProperties is false for structs and the value schema for maps.
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	Enum                 []string    `json:"enum,omitempty"`
+	Minimum              *float64    `json:"minimum,omitempty"`
+}
+
+// PayloadFieldError describes why a single payload field is invalid.
+type PayloadFieldError struct {
+	Field   string // JSON path of the field, e.g. policies[0].table; empty for the payload itself
+	Message string
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// SchemaFor derives the payload schema of a payload struct. Struct fields are described by
+// their json tag and an optional schema tag with comma separated rules: required, min=N
+// and enum=a|b.
+func SchemaFor(payload interface{}) *PayloadSchema {
+	return schemaForType(reflect.TypeOf(payload))
+}
+
+func schemaForType(t reflect.Type) *PayloadSchema {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == rawMessageType {
+		return &PayloadSchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &PayloadSchema{Type: "object", Properties: map[string]*PayloadSchema{}, AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			property := schemaForType(field.Type)
+			if applySchemaTag(property, field.Tag.Get("schema")) {
+				schema.Required = append(schema.Required, name)
+			}
+			schema.Properties[name] = property
+		}
+		return schema
+	case reflect.Map:
+		return &PayloadSchema{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		return &PayloadSchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.String:
+		return &PayloadSchema{Type: "string"}
+	case reflect.Bool:
+		return &PayloadSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &PayloadSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &PayloadSchema{Type: "number"}
+	default:
+		return &PayloadSchema{}
+	}
+}
+
+// applySchemaTag applies the rules of a schema struct tag and reports whether the field is required.
+func applySchemaTag(schema *PayloadSchema, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "min":
+			if minimum, err := strconv.ParseFloat(value, 64); err == nil {
+				schema.Minimum = &minimum
+			}
+		case "enum":
+			schema.Enum = strings.Split(value, "|")
+		}
+	}
+	return required
+}
+
+// Validate checks a JSON payload against the schema and returns every invalid field.
+// A JSON null is treated like an absent field.
+func (s *PayloadSchema) Validate(payload []byte) []PayloadFieldError {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []PayloadFieldError{{Message: "must be valid JSON"}}
+	}
+
+	var fieldErrors []PayloadFieldError
+	s.validate(value, "", &fieldErrors)
+	return fieldErrors
+}
+
+func (s *PayloadSchema) validate(value interface{}, path string, fieldErrors *[]PayloadFieldError) {
+	if value == nil || s.Type == "" {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*fieldErrors = append(*fieldErrors, PayloadFieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if object[name] == nil {
+				*fieldErrors = append(*fieldErrors, PayloadFieldError{Field: joinPath(path, name), Message: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if property, ok := s.Properties[key]; ok {
+				property.validate(object[key], joinPath(path, key), fieldErrors)
+			} else if additional, ok := s.AdditionalProperties.(*PayloadSchema); ok {
+				additional.validate(object[key], joinPath(path, key), fieldErrors)
+			} else {
+				*fieldErrors = append(*fieldErrors, PayloadFieldError{Field: joinPath(path, key), Message: "is not a known field"})
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		for i, item := range array {
+			s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), fieldErrors)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			fail("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if s.Type == "integer" && ok {
+			_, err := number.Int64()
+			ok = err == nil
+		}
+		if !ok {
+			if s.Type == "integer" {
+				fail("must be an integer")
+			} else {
+				fail("must be a number")
+			}
+			return
+		}
+		if f, err := number.Float64(); err == nil && s.Minimum != nil && f < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+	}
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
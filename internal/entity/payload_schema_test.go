@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFor(t *testing.T) {
+	schema := SchemaFor(DataRetentionPayload{})
+
+	raw, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"additionalProperties": false,
+		"required": ["policies"],
+		"properties": {
+			"batch_size": {"type": "integer", "minimum": 0},
+			"policies": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"additionalProperties": false,
+					"required": ["table", "retention_days", "action"],
+					"properties": {
+						"table": {"type": "string", "enum": ["task_execution_history", "stock_news", "stock_signals", "stock_position_monitorings"]},
+						"retention_days": {"type": "integer", "minimum": 1},
+						"action": {"type": "string", "enum": ["delete", "archive", "clear"]}
+					}
+				}
+			}
+		}
+	}`, string(raw))
+}
+
+func TestPayloadSchema_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload interface{}
+		json    string
+		want    []PayloadFieldError
+	}{
+		{
+			name:    "valid payload",
+			payload: StockNewsScraperPayload{},
+			json:    `{"max_news": 5, "blacklisted_domains": ["example.com"], "source_priority": {"https://investor.id": 4}}`,
+		},
+		{
+			name:    "unknown field",
+			payload: StockNewsScraperPayload{},
+			json:    `{"max_new": 5}`,
+			want:    []PayloadFieldError{{Field: "max_new", Message: "is not a known field"}},
+		},
+		{
+			name:    "wrong types",
+			payload: StockNewsScraperPayload{},
+			json:    `{"max_news": 1.5, "use_stock_list": "yes", "source_priority": {"a": "high"}}`,
+			want: []PayloadFieldError{
+				{Field: "max_news", Message: "must be an integer"},
+				{Field: "source_priority.a", Message: "must be an integer"},
+				{Field: "use_stock_list", Message: "must be a boolean"},
+			},
+		},
+		{
+			name:    "nested required, enum and minimum",
+			payload: DataRetentionPayload{},
+			json:    `{"policies": [{"table": "users", "retention_days": 0}]}`,
+			want: []PayloadFieldError{
+				{Field: "policies[0].action", Message: "is required"},
+				{Field: "policies[0].retention_days", Message: "must be at least 1"},
+				{Field: "policies[0].table", Message: "must be one of task_execution_history, stock_news, stock_signals, stock_position_monitorings"},
+			},
+		},
+		{
+			name:    "null is treated as absent",
+			payload: HTTPJobDetails{},
+			json:    `{"url": null, "body": null}`,
+			want:    []PayloadFieldError{{Field: "url", Message: "is required"}},
+		},
+		{
+			name:    "raw fields accept any value",
+			payload: HTTPJobDetails{},
+			json:    `{"url": "https://example.com", "method": "POST", "body": [1, {"a": true}]}`,
+		},
+		{
+			name:    "payload must be an object",
+			payload: StockAnalyzerPayload{},
+			json:    `[]`,
+			want:    []PayloadFieldError{{Message: "must be an object"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SchemaFor(tt.payload).Validate([]byte(tt.json)))
+		})
+	}
+}
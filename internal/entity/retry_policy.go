@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type BackoffStrategy string
+
+const (
+	BackoffStrategyFixed       BackoffStrategy = "fixed"
+	BackoffStrategyExponential BackoffStrategy = "exponential"
+
+	DefaultRetryInitialInterval = 5 * time.Second
+	MaxRetryInterval            = 30 * time.Minute
+)
+
+// RetryPolicy is the decoded form of jobs.retry_policy.
+type RetryPolicy struct {
+	MaxRetries      int             `json:"max_retries"`
+	BackoffStrategy BackoffStrategy `json:"backoff_strategy"`
+	InitialInterval string          `json:"initial_interval"`
+}
+
+// GetRetryPolicy decodes the job's retry policy. A missing or malformed policy means no retries.
+func (j *Job) GetRetryPolicy() RetryPolicy {
+	var policy RetryPolicy
+	if len(j.RetryPolicy) == 0 {
+		return policy
+	}
+	if err := json.Unmarshal(j.RetryPolicy, &policy); err != nil {
+		return RetryPolicy{}
+	}
+	return policy
+}
+
+// ShouldRetry reports whether another attempt is allowed after the given attempt (1-based) failed.
+func (p RetryPolicy) ShouldRetry(attempt int) bool {
+	return p.MaxRetries > 0 && attempt <= p.MaxRetries
+}
+
+// Backoff returns the delay before the attempt following the given failed attempt (1-based).
+// Unknown strategies fall back to a fixed interval, and unparsable intervals to DefaultRetryInitialInterval.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	interval, err := time.ParseDuration(p.InitialInterval)
+	if err != nil || interval <= 0 {
+		interval = DefaultRetryInitialInterval
+	}
+
+	if p.BackoffStrategy == BackoffStrategyExponential {
+		for i := 1; i < attempt; i++ {
+			interval *= 2
+			if interval >= MaxRetryInterval {
+				return MaxRetryInterval
+			}
+		}
+	}
+
+	if interval > MaxRetryInterval {
+		return MaxRetryInterval
+	}
+	return interval
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{
+			name:    "fixed strategy keeps the initial interval",
+			policy:  RetryPolicy{MaxRetries: 3, BackoffStrategy: BackoffStrategyFixed, InitialInterval: "10s"},
+			attempt: 3,
+			want:    10 * time.Second,
+		},
+		{
+			name:    "exponential strategy doubles every attempt",
+			policy:  RetryPolicy{MaxRetries: 5, BackoffStrategy: BackoffStrategyExponential, InitialInterval: "5s"},
+			attempt: 4,
+			want:    40 * time.Second,
+		},
+		{
+			name:    "exponential strategy is capped",
+			policy:  RetryPolicy{MaxRetries: 20, BackoffStrategy: BackoffStrategyExponential, InitialInterval: "1m"},
+			attempt: 10,
+			want:    MaxRetryInterval,
+		},
+		{
+			name:    "invalid interval falls back to default",
+			policy:  RetryPolicy{MaxRetries: 1, BackoffStrategy: "string", InitialInterval: "string"},
+			attempt: 1,
+			want:    DefaultRetryInitialInterval,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.Backoff(tt.attempt))
+		})
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2}
+	assert.True(t, policy.ShouldRetry(1))
+	assert.True(t, policy.ShouldRetry(2))
+	assert.False(t, policy.ShouldRetry(3))
+	assert.False(t, RetryPolicy{}.ShouldRetry(1))
+}
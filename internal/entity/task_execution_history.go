@@ -3,28 +3,60 @@ package entity
 import (
 	"database/sql"
 	"time"
+
+	"gorm.io/datatypes"
 )
 
 type TaskExecutionStatus string
 
 const (
+	// StatusQueued is an execution that was enqueued but has not started running yet.
+	StatusQueued    TaskExecutionStatus = "queued"
 	StatusRunning   TaskExecutionStatus = "running"
 	StatusCompleted TaskExecutionStatus = "completed"
 	StatusFailed    TaskExecutionStatus = "failed"
+	// StatusTimeout is an execution that ran longer than its job's timeout.
 	StatusTimeout   TaskExecutionStatus = "timeout"
+	StatusSkipped   TaskExecutionStatus = "skipped"
+	StatusCancelled TaskExecutionStatus = "cancelled"
+	// StatusLost is an execution whose executor stopped sending heartbeats, or that no executor picked up.
+	StatusLost TaskExecutionStatus = "lost"
+)
+
+// IsActive reports whether an execution with this status has not finished yet.
+func (s TaskExecutionStatus) IsActive() bool {
+	return s == StatusQueued || s == StatusRunning
+}
+
+type TriggerType string
+
+const (
+	TriggerTypeSchedule   TriggerType = "schedule"
+	TriggerTypeManual     TriggerType = "manual"
+	TriggerTypeDependency TriggerType = "dependency"
 )
 
 type TaskExecutionHistory struct {
-	ID           uint      `gorm:"primaryKey"`
-	JobID        uint      `gorm:"not null"`
-	ScheduleID   uint      `gorm:"not null"`
-	StartedAt    time.Time `gorm:"not null"`
-	CompletedAt  sql.NullTime
-	Status       TaskExecutionStatus `gorm:"type:varchar(50);not null"`
-	ExitCode     sql.NullInt32
-	Output       sql.NullString `gorm:"type:text"`
-	ErrorMessage sql.NullString `gorm:"type:text"`
-	CreatedAt    time.Time      `gorm:"autoCreateTime"`
+	ID              uint         `gorm:"primaryKey"`
+	JobID           uint         `gorm:"not null"`
+	ScheduleID      *uint        // nil when the execution was not started by a schedule
+	ScheduledAt     sql.NullTime // the fire time this run belongs to, for scheduled runs
+	StartedAt       time.Time    `gorm:"not null"` // enqueue time while queued, then the time it started running
+	CompletedAt     sql.NullTime
+	Status          TaskExecutionStatus `gorm:"type:varchar(50);not null"`
+	ExitCode        sql.NullInt32
+	Output          sql.NullString `gorm:"type:text"`
+	ErrorMessage    sql.NullString `gorm:"type:text"`
+	Attempt         int            `gorm:"not null;default:1"`
+	RetryOfID       *uint          // ID of the original execution when this row is a retry attempt
+	TriggerType     TriggerType    `gorm:"type:varchar(50);not null;default:schedule"`
+	TriggeredByID   *uint          // upstream execution that triggered this one, for dependency runs
+	PayloadOverride datatypes.JSON `gorm:"type:jsonb"` // replaces Job.Payload for this execution only
+	HeartbeatAt     sql.NullTime   // last sign of life from the executor holding this execution
+	CreatedAt       time.Time      `gorm:"autoCreateTime"`
+
+	// TraceContext carries the publisher's trace context in the stream payload. It is not stored.
+	TraceContext map[string]string `gorm:"-" json:",omitempty"`
 }
 
 func (TaskExecutionHistory) TableName() string {
@@ -5,17 +5,69 @@ import (
 	"time"
 )
 
+// DefaultScheduleTimezone is used for schedules without an explicit time zone.
+const DefaultScheduleTimezone = "Asia/Jakarta"
+
+type MisfirePolicy string
+
+const (
+	// MisfirePolicyRunOnce fires a single run for any number of missed runs.
+	MisfirePolicyRunOnce MisfirePolicy = "run_once"
+	// MisfirePolicySkip drops missed runs that are later than the grace period.
+	MisfirePolicySkip MisfirePolicy = "skip"
+	// MisfirePolicyRunAll fires every missed run, keeping at most MisfireMaxRuns of the latest ones.
+	MisfirePolicyRunAll MisfirePolicy = "run_all"
+)
+
+// IsValid reports whether the policy is a known misfire policy.
+func (p MisfirePolicy) IsValid() bool {
+	switch p {
+	case MisfirePolicyRunOnce, MisfirePolicySkip, MisfirePolicyRunAll:
+		return true
+	}
+	return false
+}
+
 type TaskSchedule struct {
-	ID             uint         `gorm:"primaryKey"`
-	JobID          uint         `gorm:"not null"`
-	CronExpression string       `gorm:"type:varchar(100)"`
-	NextExecution  sql.NullTime
-	LastExecution  sql.NullTime
-	IsActive       bool         `gorm:"default:true"`
-	CreatedAt      time.Time    `gorm:"autoCreateTime"`
-	UpdatedAt      time.Time    `gorm:"autoUpdateTime"`
+	ID                  uint   `gorm:"primaryKey"`
+	JobID               uint   `gorm:"not null"`
+	CronExpression      string `gorm:"type:varchar(100)"`
+	NextExecution       sql.NullTime
+	LastExecution       sql.NullTime
+	IsActive            bool          `gorm:"default:true"`
+	Timezone            string        `gorm:"type:varchar(64);not null;default:Asia/Jakarta"` // IANA zone the cron expression is evaluated in
+	MisfirePolicy       MisfirePolicy `gorm:"type:varchar(20);not null;default:run_once"`
+	MisfireGraceSeconds int           `gorm:"not null"` // a run later than this counts as missed
+	MisfireMaxRuns      int           `gorm:"not null"` // only used by MisfirePolicyRunAll
+	CreatedAt           time.Time     `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time     `gorm:"autoUpdateTime"`
+	PauseState
 }
 
 func (TaskSchedule) TableName() string {
 	return "task_schedules"
 }
+
+// Location returns the schedule's time zone, falling back to DefaultScheduleTimezone
+// when it is empty, the host's local zone or unknown.
+func (s *TaskSchedule) Location() *time.Location {
+	name := s.Timezone
+	if name == "" || name == "Local" {
+		name = DefaultScheduleTimezone
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	if loc, err := time.LoadLocation(DefaultScheduleTimezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
+
+// InLocation converts a nullable timestamp to the schedule's time zone.
+func (s *TaskSchedule) InLocation(t sql.NullTime) sql.NullTime {
+	if !t.Valid {
+		return t
+	}
+	return sql.NullTime{Time: t.Time.In(s.Location()), Valid: true}
+}
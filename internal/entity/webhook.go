@@ -0,0 +1,139 @@
+package entity
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// DefaultWebhookRetryPolicy is used for subscriptions that do not set a retry policy.
+var DefaultWebhookRetryPolicy = RetryPolicy{MaxRetries: 5, BackoffStrategy: BackoffStrategyExponential, InitialInterval: "30s"}
+
+// WebhookEventStatuses are the execution statuses that emit webhook events.
+var WebhookEventStatuses = []TaskExecutionStatus{StatusQueued, StatusRunning, StatusCompleted, StatusFailed, StatusTimeout, StatusSkipped, StatusCancelled, StatusLost}
+
+// WebhookSubscription asks for execution events of one job, or of every job when JobID is nil,
+// to be POSTed to a URL.
+type WebhookSubscription struct {
+	ID          uint           `gorm:"primaryKey"`
+	Name        string         `gorm:"type:varchar(100);not null"`
+	URL         string         `gorm:"type:text;not null"`
+	Secret      string         `gorm:"type:varchar(255);not null"` // signs every event sent to the URL
+	JobID       *uint          // nil for every job
+	Statuses    datatypes.JSON `gorm:"type:jsonb;not null"` // execution statuses to send events for; empty for every status
+	RetryPolicy datatypes.JSON `gorm:"type:jsonb"`          // retries of failed deliveries, DefaultWebhookRetryPolicy when empty
+	IsActive    bool           `gorm:"not null;default:true"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+}
+
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// GetRetryPolicy decodes the subscription's retry policy, falling back to DefaultWebhookRetryPolicy.
+func (s *WebhookSubscription) GetRetryPolicy() RetryPolicy {
+	if len(s.RetryPolicy) == 0 || string(s.RetryPolicy) == "null" {
+		return DefaultWebhookRetryPolicy
+	}
+	var policy RetryPolicy
+	if err := json.Unmarshal(s.RetryPolicy, &policy); err != nil {
+		return DefaultWebhookRetryPolicy
+	}
+	return policy
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // every attempt failed
+)
+
+// WebhookDelivery is an event to be sent, or sent, to one subscription. The rows double as the
+// delivery log: each attempt updates the attempt count and the last response or error.
+type WebhookDelivery struct {
+	ID             uint                  `gorm:"primaryKey"`
+	SubscriptionID uint                  `gorm:"not null"`
+	Subscription   *WebhookSubscription  `gorm:"foreignKey:SubscriptionID"`
+	EventID        string                `gorm:"type:varchar(100);not null"` // the same for every subscription receiving the event
+	EventType      string                `gorm:"type:varchar(50);not null"`
+	ExecutionID    uint                  `gorm:"not null"`
+	JobID          uint                  `gorm:"not null"`
+	Payload        datatypes.JSON        `gorm:"type:jsonb;not null"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null"`
+	Attempts       int                   `gorm:"not null"`
+	NextAttemptAt  time.Time             `gorm:"not null"`
+	LastAttemptAt  sql.NullTime
+	ResponseStatus sql.NullInt32 // HTTP status of the last attempt
+	LastError      sql.NullString
+	DeliveredAt    sql.NullTime
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// ExecutionEvent is the JSON body POSTed to webhook subscriptions when an execution changes status.
+type ExecutionEvent struct {
+	ID         string             `json:"id"`   // unique per execution and status, for deduplicating redeliveries
+	Type       string             `json:"type"` // e.g. execution.completed
+	OccurredAt time.Time          `json:"occurred_at"`
+	Execution  ExecutionEventData `json:"execution"`
+}
+
+// ExecutionEventData describes the execution an event is about.
+type ExecutionEventData struct {
+	ID           uint                `json:"id"`
+	JobID        uint                `json:"job_id"`
+	ScheduleID   *uint               `json:"schedule_id,omitempty"`
+	Status       TaskExecutionStatus `json:"status"`
+	Attempt      int                 `json:"attempt"`
+	RetryOfID    *uint               `json:"retry_of_id,omitempty"`
+	TriggerType  TriggerType         `json:"trigger_type"`
+	StartedAt    time.Time           `json:"started_at"`
+	CompletedAt  *time.Time          `json:"completed_at,omitempty"`
+	ErrorMessage string              `json:"error_message,omitempty"`
+}
+
+// ExecutionEventType returns the webhook event type emitted when an execution enters status.
+func ExecutionEventType(status TaskExecutionStatus) string {
+	switch status {
+	case StatusRunning:
+		return "execution.started"
+	case StatusTimeout:
+		return "execution.timed_out"
+	default:
+		return "execution." + string(status)
+	}
+}
+
+// NewExecutionEvent describes the current status of an execution as a webhook event.
+func NewExecutionEvent(history *TaskExecutionHistory, occurredAt time.Time) ExecutionEvent {
+	data := ExecutionEventData{
+		ID:           history.ID,
+		JobID:        history.JobID,
+		ScheduleID:   history.ScheduleID,
+		Status:       history.Status,
+		Attempt:      history.Attempt,
+		RetryOfID:    history.RetryOfID,
+		TriggerType:  history.TriggerType,
+		StartedAt:    history.StartedAt,
+		ErrorMessage: history.ErrorMessage.String,
+	}
+	if history.CompletedAt.Valid {
+		completedAt := history.CompletedAt.Time
+		data.CompletedAt = &completedAt
+	}
+	return ExecutionEvent{
+		ID:         fmt.Sprintf("execution-%d-%s", history.ID, history.Status),
+		Type:       ExecutionEventType(history.Status),
+		OccurredAt: occurredAt,
+		Execution:  data,
+	}
+}
@@ -1,26 +1,54 @@
 package config
 
 import (
-	"golang-stock-scryper/pkg/config"
+	"fmt"
+	"os"
 	"time"
+
+	"golang-stock-scryper/pkg/common"
+	"golang-stock-scryper/pkg/config"
 )
 
 // Executor holds executor-specific configuration.
 type Executor struct {
+	ConsumerName                    string        `mapstructure:"consumer_name"` // consumer name of this instance in the executor group, defaults to executor-consumer-<hostname>-<pid>
 	MaxConcurrentTasks              int           `mapstructure:"max_concurrent_tasks"`
 	RedisStreamTaskExecutionTimeout time.Duration `mapstructure:"redis_stream_task_execution_timeout"`
+	HeartbeatInterval               time.Duration `mapstructure:"heartbeat_interval"`  // how often held executions report they are alive, defaults to 15s
+	TaskClaimInterval               time.Duration `mapstructure:"task_claim_interval"` // how often tasks of dead executors are reclaimed, defaults to 30s
+	TaskClaimMinIdle                time.Duration `mapstructure:"task_claim_min_idle"` // how long a task must be untouched before it is reclaimed, defaults to 1m
 
 	// Stock Analyzer
 	RedisStreamStockAnalyzerTimeout         time.Duration `mapstructure:"redis_stream_stock_analyzer_timeout"`
 	RedisStreamStockAnalyzerRetryInterval   time.Duration `mapstructure:"redis_stream_stock_analyzer_retry_interval"`
-	RedisStreamStockAnalyzerMaxIdleDuration time.Duration `mapstructure:"redis_stream_stock_analyzer_max_idle_duration"`
-	RedisStreamStockAnalyzerMaxRetry        int           `mapstructure:"redis_stream_stock_analyzer_max_retry"`
+	RedisStreamStockAnalyzerMaxIdleDuration time.Duration `mapstructure:"redis_stream_stock_analyzer_max_idle_duration"` // idle time of a failed message before its first retry, doubled for every further retry
+	RedisStreamStockAnalyzerMaxRetry        int           `mapstructure:"redis_stream_stock_analyzer_max_retry"`         // retries after the first attempt before a message is dead-lettered
+	RedisStreamStockAnalyzerBatchSize       int64         `mapstructure:"redis_stream_stock_analyzer_batch_size"`        // messages read or retried at once, defaults to 1
 
 	// Stock Position Monitoring
 	RedisStreamStockPositionMonitorTimeout         time.Duration `mapstructure:"redis_stream_stock_position_monitor_timeout"`
 	RedisStreamStockPositionMonitorRetryInterval   time.Duration `mapstructure:"redis_stream_stock_position_monitor_retry_interval"`
-	RedisStreamStockPositionMonitorMaxIdleDuration time.Duration `mapstructure:"redis_stream_stock_position_monitor_max_idle_duration"`
-	RedisStreamStockPositionMonitorMaxRetry        int           `mapstructure:"redis_stream_stock_position_monitor_max_retry"`
+	RedisStreamStockPositionMonitorMaxIdleDuration time.Duration `mapstructure:"redis_stream_stock_position_monitor_max_idle_duration"` // idle time of a failed message before its first retry, doubled for every further retry
+	RedisStreamStockPositionMonitorMaxRetry        int           `mapstructure:"redis_stream_stock_position_monitor_max_retry"`         // retries after the first attempt before a message is dead-lettered
+	RedisStreamStockPositionMonitorBatchSize       int64         `mapstructure:"redis_stream_stock_position_monitor_batch_size"`        // messages read or retried at once, defaults to 1
+}
+
+// Retention holds configuration for data retention jobs.
+type Retention struct {
+	ArchiveDir       string `mapstructure:"archive_dir"`        // directory for compressed archives of deleted rows
+	DefaultBatchSize int    `mapstructure:"default_batch_size"` // rows per batch when the job payload does not set one
+}
+
+// Webhook holds configuration for sending webhook deliveries.
+type Webhook struct {
+	DispatchInterval time.Duration `mapstructure:"dispatch_interval"` // how often due deliveries are sent, defaults to 5s
+	BatchSize        int           `mapstructure:"batch_size"`        // deliveries claimed per dispatch, defaults to 50
+	RequestTimeout   time.Duration `mapstructure:"request_timeout"`   // timeout of a single delivery attempt, defaults to 10s
+}
+
+// HTTP holds configuration for the HTTP server serving metrics, health probes and the admin view.
+type HTTP struct {
+	Port int `mapstructure:"port"` // disabled when 0
 }
 
 // OpenRouter holds the configuration for the OpenRouter API.
@@ -35,6 +63,8 @@ type Gemini struct {
 	Model               string `mapstructure:"model"`
 	MaxRequestPerMinute int    `mapstructure:"max_request_per_minute"`
 	MaxTokenPerMinute   int    `mapstructure:"max_token_per_minute"`
+	MaxRequestPerDay    int    `mapstructure:"max_request_per_day"` // 0 for no daily limit
+	QuotaTimeZone       string `mapstructure:"quota_time_zone"`     // time zone whose midnight resets the daily limit, defaults to America/Los_Angeles
 	BaseURL             string `mapstructure:"base_url"`
 	NewsModel           string `mapstructure:"news_model"`
 }
@@ -86,6 +116,10 @@ type Config struct {
 	TradingView  TradingView     `mapstructure:"tradingview"`
 	YahooFinance YahooFinance    `mapstructure:"yahoo_finance"`
 	OpenAI       OpenAI          `mapstructure:"openai"`
+	Retention    Retention       `mapstructure:"retention"`
+	Webhook      Webhook         `mapstructure:"webhook"`
+	HTTP         HTTP            `mapstructure:"http"`
+	Tracing      config.Tracing  `mapstructure:"tracing"`
 }
 
 // Load loads the executor configuration from the given path.
@@ -94,5 +128,18 @@ func Load(path string) (*Config, error) {
 	if err := config.Load(path, &cfg); err != nil {
 		return nil, err
 	}
+	if cfg.Executor.ConsumerName == "" {
+		cfg.Executor.ConsumerName = defaultConsumerName()
+	}
 	return &cfg, nil
 }
+
+// defaultConsumerName names the consumer of this instance after its host, which is the pod or
+// container name when deployed, and its process, so instances sharing a host do not collide.
+func defaultConsumerName() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%s-%d", common.RedisStreamConsumer, hostname, os.Getpid())
+}
@@ -2,6 +2,7 @@ package consumer
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,9 +12,21 @@ import (
 	"golang-stock-scryper/pkg/logger"
 	"golang-stock-scryper/pkg/utils"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	// defaultHeartbeatInterval is used when executor.heartbeat_interval is not configured.
+	defaultHeartbeatInterval = 15 * time.Second
+	// defaultTaskClaimInterval is used when executor.task_claim_interval is not configured.
+	defaultTaskClaimInterval = 30 * time.Second
+	// defaultWebhookDispatchInterval is used when webhook.dispatch_interval is not configured.
+	defaultWebhookDispatchInterval = 5 * time.Second
+	// webhookDispatchTimeout bounds a single dispatch of webhook deliveries.
+	webhookDispatchTimeout = 5 * time.Minute
+)
+
 // RedisConsumer manages the consumption of tasks from a Redis stream.
 type RedisConsumer struct {
 	cfg                                *config.Config
@@ -21,9 +34,12 @@ type RedisConsumer struct {
 	executorService                    service.ExecutorService
 	stockAnalyzerMultiTimeframeService service.StockAnalyzerMultiTimeframeService
 	stockPositionMonitoringService     service.StockPositionMonitoringMultiTimeframeService
+	webhookDispatcher                  service.WebhookDispatcher
 	logger                             *logger.Logger
 	stopChan                           chan struct{}
 	wg                                 sync.WaitGroup
+	loops                              map[string]*service.StreamLoop // read loops by stream, for the health checks
+	loopsMu                            sync.Mutex
 }
 
 // NewRedisConsumer creates a new RedisConsumer.
@@ -33,6 +49,7 @@ func NewRedisConsumer(
 	executorService service.ExecutorService,
 	stockAnalyzerMultiTimeframeService service.StockAnalyzerMultiTimeframeService,
 	stockPositionMonitoringService service.StockPositionMonitoringMultiTimeframeService,
+	webhookDispatcher service.WebhookDispatcher,
 	log *logger.Logger,
 ) *RedisConsumer {
 	return &RedisConsumer{
@@ -41,18 +58,57 @@ func NewRedisConsumer(
 		executorService:                    executorService,
 		stockAnalyzerMultiTimeframeService: stockAnalyzerMultiTimeframeService,
 		stockPositionMonitoringService:     stockPositionMonitoringService,
+		webhookDispatcher:                  webhookDispatcher,
 		logger:                             log,
 		stopChan:                           make(chan struct{}),
+		loops:                              make(map[string]*service.StreamLoop),
 	}
 }
 
 // Start begins the consumer's task processing loop.
 func (c *RedisConsumer) Start(ctx context.Context) {
-	c.logger.Info("Redis consumer started")
+	c.logger.Info("Redis consumer started", logger.StringField("consumer", c.cfg.Executor.ConsumerName))
+	registerCtx, cancelRegister := context.WithTimeout(ctx, consumerRegistryTimeout)
+	if err := c.register(registerCtx); err != nil {
+		// Reading creates the consumer anyway, so the instance can still work.
+		c.logger.Error("Failed to register consumer", logger.ErrorField(err))
+	}
+	c.cleanupConsumers(registerCtx)
+	cancelRegister()
+	c.RegisterTickerHandler(ctx, c.refreshRegistration, consumerRefreshInterval, consumerRegistryTimeout, "consumer-registration")
+	c.RegisterTickerHandler(ctx, c.cleanupConsumers, consumerCleanupInterval, consumerRegistryTimeout, "consumer-cleanup")
+
 	c.RegisterStreamHandler(ctx, c.executorService.ProcessTask, common.RedisStreamSchedulerTaskExecution, c.cfg.Executor.RedisStreamTaskExecutionTimeout)
 	c.RegisterStreamHandler(ctx, c.stockAnalyzerMultiTimeframeService.ProcessTask, common.RedisStreamStockAnalyzer, c.cfg.Executor.RedisStreamStockAnalyzerTimeout)
 	c.RegisterStreamHandler(ctx, c.stockPositionMonitoringService.ProcessTask, common.RedisStreamStockPositionMonitor, c.cfg.Executor.RedisStreamStockPositionMonitorTimeout)
 
+	prometheus.MustRegister(&streamCollector{
+		redisClient:       c.redisClient,
+		streams:           common.RedisExecutorStreams,
+		deadLetterStreams: common.RedisDeadLetterStreams,
+		logger:            c.logger,
+	})
+
+	c.RegisterListener(ctx, c.executorService.ListenCancellations, common.RedisChannelTaskExecutionCancel)
+
+	heartbeatInterval := c.cfg.Executor.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = defaultHeartbeatInterval
+	}
+	c.RegisterTickerHandler(ctx, c.executorService.SendHeartbeats, heartbeatInterval, heartbeatInterval, "task-execution-heartbeat")
+
+	claimInterval := c.cfg.Executor.TaskClaimInterval
+	if claimInterval <= 0 {
+		claimInterval = defaultTaskClaimInterval
+	}
+	c.RegisterTickerHandler(ctx, c.executorService.ReclaimTasks, claimInterval, c.cfg.Executor.RedisStreamTaskExecutionTimeout, common.RedisStreamSchedulerTaskExecution+"-reclaim")
+
+	webhookInterval := c.cfg.Webhook.DispatchInterval
+	if webhookInterval <= 0 {
+		webhookInterval = defaultWebhookDispatchInterval
+	}
+	c.RegisterTickerHandler(ctx, c.webhookDispatcher.Dispatch, webhookInterval, webhookDispatchTimeout, "webhook-dispatch")
+
 	//handle retry
 	c.RegisterTickerHandler(ctx, c.stockAnalyzerMultiTimeframeService.ProcessRetries, c.cfg.Executor.RedisStreamStockAnalyzerRetryInterval, c.cfg.Executor.RedisStreamStockAnalyzerMaxIdleDuration, common.RedisStreamStockAnalyzer+"-retry")
 	c.RegisterTickerHandler(ctx, c.stockPositionMonitoringService.ProcessRetries, c.cfg.Executor.RedisStreamStockPositionMonitorRetryInterval, c.cfg.Executor.RedisStreamStockPositionMonitorMaxIdleDuration, common.RedisStreamStockPositionMonitor+"-retry")
@@ -60,6 +116,7 @@ func (c *RedisConsumer) Start(ctx context.Context) {
 
 func (c *RedisConsumer) RegisterStreamHandler(ctx context.Context, fn func(ctx context.Context), streamName string, timeout time.Duration) {
 	c.logger.Info("Registering stream handler", logger.Field("stream", streamName))
+	c.markPolled(streamName, timeout)
 	c.wg.Add(1)
 	utils.GoSafe(func() {
 		defer c.wg.Done()
@@ -73,8 +130,9 @@ func (c *RedisConsumer) RegisterStreamHandler(ctx context.Context, fn func(ctx c
 				return
 			default:
 				ctxTimeout, cancel := context.WithTimeout(ctx, timeout)
-				defer cancel()
 				fn(ctxTimeout)
+				cancel()
+				c.markPolled(streamName, timeout)
 			}
 
 		}
@@ -110,9 +168,49 @@ func (c *RedisConsumer) RegisterTickerHandler(ctx context.Context, fn func(ctx c
 	})
 }
 
+// RegisterListener runs a long-lived listener, such as a pub/sub subscription, until the consumer stops.
+func (c *RedisConsumer) RegisterListener(ctx context.Context, fn func(ctx context.Context), name string) {
+	c.logger.Info("Registering listener", logger.Field("name", name))
+	listenerCtx, cancel := context.WithCancel(ctx)
+	c.wg.Add(1)
+	utils.GoSafe(func() {
+		defer c.wg.Done()
+		defer cancel()
+		fn(listenerCtx)
+		c.logger.Info("Listener stopped", logger.Field("name", name))
+	})
+	utils.GoSafe(func() {
+		select {
+		case <-c.stopChan:
+			cancel()
+		case <-listenerCtx.Done():
+		}
+	})
+}
+
+// StreamLoops reports when each stream read loop last completed a read, so a loop stuck in its
+// handler can be told apart from an idle one.
+func (c *RedisConsumer) StreamLoops() []service.StreamLoop {
+	c.loopsMu.Lock()
+	defer c.loopsMu.Unlock()
+	loops := make([]service.StreamLoop, 0, len(c.loops))
+	for _, loop := range c.loops {
+		loops = append(loops, *loop)
+	}
+	sort.Slice(loops, func(i, j int) bool { return loops[i].Stream < loops[j].Stream })
+	return loops
+}
+
+func (c *RedisConsumer) markPolled(streamName string, timeout time.Duration) {
+	c.loopsMu.Lock()
+	defer c.loopsMu.Unlock()
+	c.loops[streamName] = &service.StreamLoop{Stream: streamName, Timeout: timeout, LastPollAt: time.Now()}
+}
+
 // Stop gracefully shuts down the consumer.
 func (c *RedisConsumer) Stop() {
 	close(c.stopChan)
 	c.wg.Wait()
+	c.unregister()
 	c.logger.Info("Redis consumer stopped")
 }
@@ -0,0 +1,74 @@
+package consumer
+
+import (
+	"context"
+	"time"
+
+	"golang-stock-scryper/pkg/common"
+	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/stream"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// streamMetricsTimeout bounds the Redis calls made for one scrape of the stream metrics.
+const streamMetricsTimeout = 5 * time.Second
+
+var (
+	streamPendingDesc = prometheus.NewDesc("redis_stream_pending_messages",
+		"Messages delivered to the executor consumer group but not acknowledged yet, per stream.", []string{"stream"}, nil)
+	streamLagDesc = prometheus.NewDesc("redis_stream_lag_messages",
+		"Messages in a stream not delivered to the executor consumer group yet, per stream.", []string{"stream"}, nil)
+	streamDeadLettersDesc = prometheus.NewDesc("redis_stream_dead_letter_messages",
+		"Messages in the dead-letter stream of a stream, per source stream.", []string{"stream"}, nil)
+)
+
+// streamCollector reads the pending and lag counts of the executor consumer group and the length
+// of the dead-letter streams from Redis on every scrape.
+type streamCollector struct {
+	redisClient       *redis.Client
+	streams           []string
+	deadLetterStreams []string // source streams whose dead-letter stream is measured
+	logger            *logger.Logger
+}
+
+// Describe implements prometheus.Collector.
+func (c *streamCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- streamPendingDesc
+	ch <- streamLagDesc
+	ch <- streamDeadLettersDesc
+}
+
+// Collect implements prometheus.Collector. Streams that cannot be read are left out.
+func (c *streamCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), streamMetricsTimeout)
+	defer cancel()
+
+	for _, stream := range c.streams {
+		groups, err := c.redisClient.XInfoGroups(ctx, stream).Result()
+		if err != nil {
+			c.logger.Warn("Failed to read stream metrics", logger.ErrorField(err), logger.StringField("stream", stream))
+			continue
+		}
+		for _, group := range groups {
+			if group.Name != common.RedisStreamGroup {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(streamPendingDesc, prometheus.GaugeValue, float64(group.Pending), stream)
+			// Redis reports -1 when it cannot tell the lag, e.g. after entries were trimmed.
+			if group.Lag >= 0 {
+				ch <- prometheus.MustNewConstMetric(streamLagDesc, prometheus.GaugeValue, float64(group.Lag), stream)
+			}
+		}
+	}
+
+	for _, source := range c.deadLetterStreams {
+		length, err := c.redisClient.XLen(ctx, stream.DeadLetterStream(source)).Result()
+		if err != nil {
+			c.logger.Warn("Failed to read dead-letter stream metrics", logger.ErrorField(err), logger.StringField("stream", source))
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(streamDeadLettersDesc, prometheus.GaugeValue, float64(length), source)
+	}
+}
@@ -0,0 +1,107 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang-stock-scryper/pkg/common"
+	"golang-stock-scryper/pkg/logger"
+)
+
+const (
+	// consumerTTL is how long the registration of a consumer outlives its last refresh. A consumer
+	// without a registration belongs to an instance that stopped.
+	consumerTTL = time.Minute
+	// consumerRefreshInterval is how often the registration of this instance is refreshed.
+	consumerRefreshInterval = 20 * time.Second
+	// consumerCleanupInterval is how often consumers of stopped instances are removed.
+	consumerCleanupInterval = 5 * time.Minute
+	// consumerRegistryTimeout bounds a single registration, refresh or cleanup.
+	consumerRegistryTimeout = 30 * time.Second
+)
+
+// register records the consumer of this instance as alive and creates it in the executor group
+// of every stream, so it is listed before it reads its first message.
+func (c *RedisConsumer) register(ctx context.Context) error {
+	c.refreshRegistration(ctx)
+	for _, stream := range common.RedisExecutorStreams {
+		if err := c.redisClient.XGroupCreateConsumer(ctx, stream, common.RedisStreamGroup, c.cfg.Executor.ConsumerName).Err(); err != nil {
+			return fmt.Errorf("create consumer on stream %s: %w", stream, err)
+		}
+	}
+	c.logger.Info("Consumer registered", logger.StringField("consumer", c.cfg.Executor.ConsumerName))
+	return nil
+}
+
+// refreshRegistration extends the registration of this instance.
+func (c *RedisConsumer) refreshRegistration(ctx context.Context) {
+	key := fmt.Sprintf(common.RedisKeyExecutorConsumer, c.cfg.Executor.ConsumerName)
+	if err := c.redisClient.Set(ctx, key, time.Now().Unix(), consumerTTL).Err(); err != nil {
+		c.logger.Error("Failed to refresh consumer registration", logger.ErrorField(err), logger.StringField("consumer", c.cfg.Executor.ConsumerName))
+	}
+}
+
+// cleanupConsumers removes the consumers of stopped instances from the executor group. A consumer
+// is only removed once it holds no pending messages, since removing it drops them; its messages
+// are reclaimed by the live instances first.
+func (c *RedisConsumer) cleanupConsumers(ctx context.Context) {
+	for _, stream := range common.RedisExecutorStreams {
+		consumers, err := c.redisClient.XInfoConsumers(ctx, stream, common.RedisStreamGroup).Result()
+		if err != nil {
+			c.logger.Error("Failed to list consumers", logger.ErrorField(err), logger.StringField("stream", stream))
+			continue
+		}
+		for _, consumer := range consumers {
+			if consumer.Name == c.cfg.Executor.ConsumerName || consumer.Pending > 0 || consumer.Idle < consumerTTL {
+				continue
+			}
+			alive, err := c.redisClient.Exists(ctx, fmt.Sprintf(common.RedisKeyExecutorConsumer, consumer.Name)).Result()
+			if err != nil {
+				c.logger.Error("Failed to check consumer registration", logger.ErrorField(err), logger.StringField("consumer", consumer.Name))
+				continue
+			}
+			if alive > 0 {
+				continue
+			}
+			if err := c.redisClient.XGroupDelConsumer(ctx, stream, common.RedisStreamGroup, consumer.Name).Err(); err != nil {
+				c.logger.Error("Failed to remove consumer", logger.ErrorField(err), logger.StringField("stream", stream), logger.StringField("consumer", consumer.Name))
+				continue
+			}
+			c.logger.Info("Removed consumer of stopped instance", logger.StringField("stream", stream), logger.StringField("consumer", consumer.Name))
+		}
+	}
+}
+
+// unregister removes the registration of this instance and its consumer from every stream where
+// it holds no pending messages. Pending messages are left to be reclaimed by other instances.
+func (c *RedisConsumer) unregister() {
+	ctx, cancel := context.WithTimeout(context.Background(), consumerRegistryTimeout)
+	defer cancel()
+
+	name := c.cfg.Executor.ConsumerName
+	if err := c.redisClient.Del(ctx, fmt.Sprintf(common.RedisKeyExecutorConsumer, name)).Err(); err != nil {
+		c.logger.Error("Failed to remove consumer registration", logger.ErrorField(err), logger.StringField("consumer", name))
+	}
+	for _, stream := range common.RedisExecutorStreams {
+		consumers, err := c.redisClient.XInfoConsumers(ctx, stream, common.RedisStreamGroup).Result()
+		if err != nil {
+			c.logger.Error("Failed to list consumers", logger.ErrorField(err), logger.StringField("stream", stream))
+			continue
+		}
+		for _, consumer := range consumers {
+			if consumer.Name != name {
+				continue
+			}
+			if consumer.Pending > 0 {
+				c.logger.Warn("Leaving consumer with pending messages to be reclaimed", logger.StringField("stream", stream), logger.StringField("consumer", name), logger.Field("pending", consumer.Pending))
+				break
+			}
+			if err := c.redisClient.XGroupDelConsumer(ctx, stream, common.RedisStreamGroup, name).Err(); err != nil {
+				c.logger.Error("Failed to remove consumer", logger.ErrorField(err), logger.StringField("stream", stream), logger.StringField("consumer", name))
+			}
+			break
+		}
+	}
+	c.logger.Info("Consumer unregistered", logger.StringField("consumer", name))
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang-stock-scryper/internal/executor/dto"
+	"golang-stock-scryper/internal/executor/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// readinessTimeout bounds the dependency checks of a single readiness probe.
+const readinessTimeout = 5 * time.Second
+
+// HealthHandler handles the health probes and the admin view of the execution service.
+type HealthHandler struct {
+	healthService service.HealthService
+}
+
+// NewHealthHandler creates a new HealthHandler.
+func NewHealthHandler(healthService service.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// RegisterRoutes registers the health and admin routes to the Echo instance.
+func (h *HealthHandler) RegisterRoutes(e *echo.Echo) {
+	e.GET("/healthz", h.Live)
+	e.GET("/readyz", h.Ready)
+	e.GET("/admin/status", h.AdminStatus)
+}
+
+// Live answers the liveness probe with 200 while every stream read loop makes progress and 503
+// once one is stuck.
+func (h *HealthHandler) Live(c echo.Context) error {
+	return healthJSON(c, h.healthService.Live())
+}
+
+// Ready answers the readiness probe with 200 when every dependency is reachable and 503 otherwise.
+func (h *HealthHandler) Ready(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), readinessTimeout)
+	defer cancel()
+	return healthJSON(c, h.healthService.Ready(ctx))
+}
+
+// AdminStatus returns the registered strategies, in-flight executions, semaphore occupancy and
+// the consumer state of every stream.
+func (h *HealthHandler) AdminStatus(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.healthService.AdminStatus(c.Request().Context()))
+}
+
+func healthJSON(c echo.Context, response dto.HealthResponse) error {
+	status := http.StatusOK
+	if response.Status != dto.HealthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, response)
+}
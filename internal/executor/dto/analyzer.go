@@ -16,6 +16,13 @@ type StreamDataStockAnalyzer struct {
 	StockCode  string `json:"stock_code"`
 	TelegramID int64  `json:"telegram_id"`
 	NotifyUser bool   `json:"notify_user"`
+
+	TraceContext map[string]string `json:"trace_context,omitempty"` // trace context of the publisher
+}
+
+// GetTraceContext returns the trace context of the publisher.
+func (d StreamDataStockAnalyzer) GetTraceContext() map[string]string {
+	return d.TraceContext
 }
 
 type StreamDataStockPositionMonitor struct {
@@ -23,4 +30,11 @@ type StreamDataStockPositionMonitor struct {
 	UserID          uint   `json:"user_id"`
 	StockCode       string `json:"stock_code"`
 	SendToTelegram  bool   `json:"send_to_telegram"`
+
+	TraceContext map[string]string `json:"trace_context,omitempty"` // trace context of the publisher
+}
+
+// GetTraceContext returns the trace context of the publisher.
+func (d StreamDataStockPositionMonitor) GetTraceContext() map[string]string {
+	return d.TraceContext
 }
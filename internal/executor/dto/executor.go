@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type ExecutorSummaryResult struct {
 	StockCode string `json:"stock_code"`
 	IsSuccess bool   `json:"is_success"`
@@ -13,3 +15,15 @@ type NewsSummaryTelegramResult struct {
 	Sentiment       string  `json:"sentiment"`
 	ConfidenceScore float64 `json:"confidence_score"`
 }
+
+// ExecutorRetentionResult reports what a data retention run did to a single table.
+type ExecutorRetentionResult struct {
+	Table         string    `json:"table"`
+	Action        string    `json:"action"`
+	RetentionDays int       `json:"retention_days"`
+	Cutoff        time.Time `json:"cutoff"`
+	Rows          int64     `json:"rows"`
+	Batches       int       `json:"batches"`
+	ArchiveFile   string    `json:"archive_file,omitempty"`
+	Error         string    `json:"error,omitempty"`
+}
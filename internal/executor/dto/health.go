@@ -0,0 +1,64 @@
+package dto
+
+import "time"
+
+// Health statuses reported by the liveness and readiness probes and by each of their checks.
+const (
+	HealthStatusOK    = "ok"
+	HealthStatusError = "error"
+)
+
+// HealthCheckResult is the outcome of a single liveness or readiness check.
+type HealthCheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthResponse is returned by the liveness and readiness probes. Status is ok only when every
+// check is ok.
+type HealthResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks"`
+}
+
+// InFlightExecution is an execution held by this executor instance, either running or waiting
+// for a retry or a free slot.
+type InFlightExecution struct {
+	HistoryID uint      `json:"history_id"`
+	JobID     uint      `json:"job_id"`
+	Since     time.Time `json:"since"` // when this instance took the current attempt
+}
+
+// ExecutorState describes the job executions of this executor instance.
+type ExecutorState struct {
+	Consumer          string              `json:"consumer"` // consumer name of this instance in the executor group
+	Strategies        []string            `json:"strategies"`
+	InFlight          []InFlightExecution `json:"in_flight"`
+	SemaphoreInUse    int                 `json:"semaphore_in_use"`
+	SemaphoreCapacity int                 `json:"semaphore_capacity"`
+}
+
+// StreamConsumerState is a consumer of the executor consumer group as Redis reports it.
+type StreamConsumerState struct {
+	Name    string `json:"name"`
+	Pending int64  `json:"pending"`
+	IdleMs  int64  `json:"idle_ms"` // time since the consumer last read from the stream
+}
+
+// StreamState describes the executor consumer group of one stream.
+type StreamState struct {
+	Stream          string                `json:"stream"`
+	Group           string                `json:"group"`
+	Pending         int64                 `json:"pending"`
+	Lag             int64                 `json:"lag"` // -1 when Redis cannot tell
+	LastDeliveredID string                `json:"last_delivered_id"`
+	Consumers       []StreamConsumerState `json:"consumers"`
+	LastPollAt      *time.Time            `json:"last_poll_at,omitempty"` // last completed read of this instance
+	Error           string                `json:"error,omitempty"`
+}
+
+// AdminStatusResponse is the admin view of an executor instance.
+type AdminStatusResponse struct {
+	Executor ExecutorState `json:"executor"`
+	Streams  []StreamState `json:"streams"`
+}
@@ -14,37 +14,61 @@ import (
 	"golang-stock-scryper/internal/executor/config"
 	"golang-stock-scryper/internal/executor/dto"
 	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/metrics"
 	"golang-stock-scryper/pkg/ratelimit"
+	"golang-stock-scryper/pkg/tracing"
 	"golang-stock-scryper/pkg/utils"
 
-	"golang.org/x/time/rate"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 	"google.golang.org/genai"
 )
 
+var geminiTokensTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "gemini_tokens_total",
+	Help: "Prompt tokens sent to the Gemini API, as counted before each request, by model.",
+}, []string{"model"})
+
+// defaultGeminiQuotaTimeZone is used when gemini.quota_time_zone is not configured. Gemini resets
+// its daily quotas at midnight Pacific time.
+const defaultGeminiQuotaTimeZone = "America/Los_Angeles"
+
 // geminiAIRepository is an implementation of NewsAnalyzerRepository that uses the Google Gemini API.
 type geminiAIRepository struct {
-	client         *http.Client
-	cfg            *config.Config
-	logger         *logger.Logger
-	tokenLimiter   *ratelimit.TokenLimiter
-	requestLimiter *rate.Limiter
-	genAiClient    *genai.Client
+	client      *http.Client
+	cfg         *config.Config
+	logger      *logger.Logger
+	limiter     *ratelimit.SharedLimiter
+	genAiClient *genai.Client
 }
 
-// NewGeminiAIRepository creates a new instance of geminiAIRepository.
-func NewGeminiAIRepository(cfg *config.Config, log *logger.Logger, genAiClient *genai.Client) (AIRepository, error) {
-	secondsPerRequest := time.Minute / time.Duration(cfg.Gemini.MaxRequestPerMinute)
-	requestLimiter := rate.NewLimiter(rate.Every(secondsPerRequest), 1)
+// NewGeminiAIRepository creates a new instance of geminiAIRepository. Its request and token
+// limits are shared with every executor instance through Redis.
+func NewGeminiAIRepository(cfg *config.Config, log *logger.Logger, genAiClient *genai.Client, redisClient *redis.Client) (AIRepository, error) {
+	timeZone := cfg.Gemini.QuotaTimeZone
+	if timeZone == "" {
+		timeZone = defaultGeminiQuotaTimeZone
+	}
+	quotaLocation, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gemini quota time zone: %w", err)
+	}
 
-	tokenLimiter := ratelimit.NewTokenLimiter(cfg.Gemini.MaxTokenPerMinute)
+	limiter := ratelimit.NewSharedLimiter(redisClient, metrics.ProviderGemini,
+		ratelimit.PerMinute(ratelimit.UnitRequests, int64(cfg.Gemini.MaxRequestPerMinute)),
+		ratelimit.PerMinute(ratelimit.UnitTokens, int64(cfg.Gemini.MaxTokenPerMinute)),
+		ratelimit.PerDay(ratelimit.UnitRequests, int64(cfg.Gemini.MaxRequestPerDay), quotaLocation),
+	)
+	prometheus.MustRegister(limiter)
 
 	return &geminiAIRepository{
-		client:         &http.Client{},
-		cfg:            cfg,
-		logger:         log,
-		requestLimiter: requestLimiter,
-		tokenLimiter:   tokenLimiter,
-		genAiClient:    genAiClient,
+		client:      &http.Client{Transport: metrics.NewTransport(metrics.ProviderGemini, tracing.NewTransport(metrics.ProviderGemini, nil))},
+		cfg:         cfg,
+		logger:      log,
+		limiter:     limiter,
+		genAiClient: genAiClient,
 	}, nil
 }
 
@@ -91,21 +115,20 @@ func (r *geminiAIRepository) executeGeminiAIRequest(ctx context.Context, prompt
 		return nil, fmt.Errorf("failed to count tokens: %w", err)
 	}
 
-	r.logger.Debug("Gemini token count",
-		logger.IntField("total_tokens", int(geminiTokenResp.TotalTokens)),
-		logger.IntField("remaining", r.tokenLimiter.GetRemaining()),
-	)
-
-	if err := r.tokenLimiter.Wait(ctx, int(geminiTokenResp.TotalTokens)); err != nil {
-		return nil, fmt.Errorf("failed to wait for token limit: %w", err)
+	quotas, err := r.limiter.Wait(ctx, int64(geminiTokenResp.TotalTokens))
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for rate limit: %w", err)
 	}
+	geminiTokensTotal.WithLabelValues(selectedModel).Add(float64(geminiTokenResp.TotalTokens))
 
-	if err := r.requestLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("failed to wait for request limit: %w", err)
+	fields := []zap.Field{logger.IntField("total_tokens", int(geminiTokenResp.TotalTokens))}
+	for _, quota := range quotas {
+		fields = append(fields, logger.Field(quota.Window.Name()+"_remaining", quota.Remaining))
 	}
+	r.logger.Debug("Gemini token count", fields...)
 
 	if int(geminiTokenResp.TotalTokens) > r.cfg.Gemini.MaxTokenPerMinute/2 {
-		r.logger.Warn("Token has exceeded 50% of the limit", logger.IntField("remaining", r.tokenLimiter.GetRemaining()))
+		r.logger.Warn("Token has exceeded 50% of the limit", fields...)
 	}
 
 	payload := dto.GeminiAPIRequest{
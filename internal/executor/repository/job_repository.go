@@ -11,6 +11,7 @@ import (
 // JobRepository defines the interface for job data operations.
 type JobRepository interface {
 	FindByID(ctx context.Context, id uint) (*entity.Job, error)
+	FindDependents(ctx context.Context, jobID uint) ([]entity.Job, error)
 }
 
 // NewJobRepository creates a new GORM-based job repository.
@@ -30,3 +31,17 @@ func (r *jobRepository) FindByID(ctx context.Context, id uint) (*entity.Job, err
 	}
 	return &job, nil
 }
+
+// FindDependents retrieves the jobs that depend on the given job, with all of their dependencies.
+func (r *jobRepository) FindDependents(ctx context.Context, jobID uint) ([]entity.Job, error) {
+	var jobs []entity.Job
+	err := r.db.WithContext(ctx).
+		Preload("Dependencies").
+		Joins("JOIN job_dependencies jd ON jd.job_id = jobs.id").
+		Where("jd.depends_on_job_id = ?", jobID).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
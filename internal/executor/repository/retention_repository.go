@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RetentionTarget describes the rows of a table that a retention run may act on.
+type RetentionTarget struct {
+	Table      string
+	TimeColumn string // rows older than the cutoff in this column are expired
+	Condition  string // optional static SQL condition, e.g. to exclude unfinished executions
+}
+
+// RetentionRepository provides batched, table-agnostic access for data retention jobs.
+type RetentionRepository interface {
+	FindExpired(ctx context.Context, target RetentionTarget, cutoff time.Time, limit int, columns ...string) ([]map[string]interface{}, error)
+	DeleteByIDs(ctx context.Context, table string, ids []interface{}) (int64, error)
+	ClearColumn(ctx context.Context, table, column string, value interface{}, ids []interface{}) (int64, error)
+}
+
+type retentionRepository struct {
+	db *gorm.DB
+}
+
+// NewRetentionRepository creates a new GORM-based retention repository.
+func NewRetentionRepository(db *gorm.DB) RetentionRepository {
+	return &retentionRepository{db: db}
+}
+
+// FindExpired returns up to limit expired rows of the target, oldest IDs first. When no
+// columns are given every column is selected.
+func (r *retentionRepository) FindExpired(ctx context.Context, target RetentionTarget, cutoff time.Time, limit int, columns ...string) ([]map[string]interface{}, error) {
+	query := r.db.WithContext(ctx).Table(target.Table).
+		Where("? < ?", clause.Column{Name: target.TimeColumn}, cutoff)
+	if target.Condition != "" {
+		query = query.Where(target.Condition)
+	}
+	if len(columns) > 0 {
+		query = query.Select(columns)
+	}
+
+	var rows []map[string]interface{}
+	if err := query.Order("id ASC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// DeleteByIDs deletes the rows with the given IDs and returns the number of rows deleted.
+func (r *retentionRepository) DeleteByIDs(ctx context.Context, table string, ids []interface{}) (int64, error) {
+	result := r.db.WithContext(ctx).Exec("DELETE FROM ? WHERE id IN ?", clause.Table{Name: table}, ids)
+	return result.RowsAffected, result.Error
+}
+
+// ClearColumn replaces column with value, e.g. NULL or an empty JSON document, for the rows with
+// the given IDs and returns the number of rows updated.
+func (r *retentionRepository) ClearColumn(ctx context.Context, table, column string, value interface{}, ids []interface{}) (int64, error) {
+	result := r.db.WithContext(ctx).Exec("UPDATE ? SET ? = ? WHERE id IN ?", clause.Table{Name: table}, clause.Column{Name: column}, value, ids)
+	return result.RowsAffected, result.Error
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"golang-stock-scryper/internal/entity"
 
@@ -10,10 +12,21 @@ import (
 
 // TaskExecutionHistoryRepository defines the interface for task execution history data operations.
 type TaskExecutionHistoryRepository interface {
+	Create(ctx context.Context, history *entity.TaskExecutionHistory) error
 	FindByID(ctx context.Context, id uint) (*entity.TaskExecutionHistory, error)
 	Update(ctx context.Context, history *entity.TaskExecutionHistory) error
+	MarkRunning(ctx context.Context, history *entity.TaskExecutionHistory, staleBefore time.Time) (bool, error)
+	Heartbeat(ctx context.Context, ids []uint) error
+	FindLatestByJobID(ctx context.Context, jobID uint) (*entity.TaskExecutionHistory, error)
+	FindLatestCompletedByJobID(ctx context.Context, jobID uint) (*entity.TaskExecutionHistory, error)
+	FindRunningBefore(ctx context.Context, jobID uint, beforeID uint, startedAfter time.Time) ([]entity.TaskExecutionHistory, error)
+	WithJobLock(ctx context.Context, jobID uint, fn func(repo TaskExecutionHistoryRepository) error) error
 }
 
+// jobLockNamespace is the first key of the advisory locks taken by WithJobLock, so they do not
+// collide with advisory locks taken for other purposes.
+const jobLockNamespace = 1001
+
 // NewTaskExecutionHistoryRepository creates a new GORM-based task execution history repository.
 func NewTaskExecutionHistoryRepository(db *gorm.DB) TaskExecutionHistoryRepository {
 	return &taskExecutionHistoryRepository{db: db}
@@ -23,6 +36,11 @@ type taskExecutionHistoryRepository struct {
 	db *gorm.DB
 }
 
+// Create creates a new task execution history record.
+func (r *taskExecutionHistoryRepository) Create(ctx context.Context, history *entity.TaskExecutionHistory) error {
+	return r.db.WithContext(ctx).Create(history).Error
+}
+
 // FindByID retrieves a task execution history by its ID.
 func (r *taskExecutionHistoryRepository) FindByID(ctx context.Context, id uint) (*entity.TaskExecutionHistory, error) {
 	var history entity.TaskExecutionHistory
@@ -36,3 +54,92 @@ func (r *taskExecutionHistoryRepository) FindByID(ctx context.Context, id uint)
 func (r *taskExecutionHistoryRepository) Update(ctx context.Context, history *entity.TaskExecutionHistory) error {
 	return r.db.WithContext(ctx).Save(history).Error
 }
+
+// MarkRunning moves a queued execution to running, setting its start time and first heartbeat,
+// and reports whether it did. It returns false when the execution was cancelled, reaped or picked
+// up by another executor in the meantime. Running rows whose last heartbeat is older than
+// staleBefore are taken over, since their executor died before finishing them. Running rows
+// without a heartbeat are accepted too, since they were published before executions were queued
+// first.
+func (r *taskExecutionHistoryRepository) MarkRunning(ctx context.Context, history *entity.TaskExecutionHistory, staleBefore time.Time) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&entity.TaskExecutionHistory{}).
+		Where("id = ? AND (status = ? OR (status = ? AND (heartbeat_at IS NULL OR heartbeat_at < ?)))", history.ID, entity.StatusQueued, entity.StatusRunning, staleBefore).
+		Updates(map[string]interface{}{"status": entity.StatusRunning, "started_at": now, "heartbeat_at": now})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	history.Status = entity.StatusRunning
+	history.StartedAt = now
+	history.HeartbeatAt = sql.NullTime{Time: now, Valid: true}
+	return true, nil
+}
+
+// Heartbeat records that the given executions are still held by a live executor. Executions that
+// have already finished are left alone.
+func (r *taskExecutionHistoryRepository) Heartbeat(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&entity.TaskExecutionHistory{}).
+		Where("id IN ? AND status IN ?", ids, []entity.TaskExecutionStatus{entity.StatusQueued, entity.StatusRunning}).
+		Update("heartbeat_at", time.Now()).Error
+}
+
+// FindLatestByJobID retrieves the most recently started execution of a job, or nil if it never ran.
+func (r *taskExecutionHistoryRepository) FindLatestByJobID(ctx context.Context, jobID uint) (*entity.TaskExecutionHistory, error) {
+	var histories []entity.TaskExecutionHistory
+	if err := r.db.WithContext(ctx).Where("job_id = ?", jobID).Order("started_at desc").Limit(1).Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, nil
+	}
+	return &histories[0], nil
+}
+
+// FindLatestCompletedByJobID retrieves the most recently completed successful execution of a job, or nil if there is none.
+func (r *taskExecutionHistoryRepository) FindLatestCompletedByJobID(ctx context.Context, jobID uint) (*entity.TaskExecutionHistory, error) {
+	var histories []entity.TaskExecutionHistory
+	err := r.db.WithContext(ctx).
+		Where("job_id = ? AND status = ?", jobID, entity.StatusCompleted).
+		Order("completed_at desc").
+		Limit(1).
+		Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, nil
+	}
+	return &histories[0], nil
+}
+
+// FindRunningBefore retrieves queued and running executions of a job that were created before the
+// given execution and started after startedAfter, so that rows left behind by a crashed executor are ignored.
+func (r *taskExecutionHistoryRepository) FindRunningBefore(ctx context.Context, jobID uint, beforeID uint, startedAfter time.Time) ([]entity.TaskExecutionHistory, error) {
+	var histories []entity.TaskExecutionHistory
+	err := r.db.WithContext(ctx).
+		Where("job_id = ? AND status IN ? AND id < ? AND started_at > ?", jobID, []entity.TaskExecutionStatus{entity.StatusQueued, entity.StatusRunning}, beforeID, startedAfter).
+		Order("id asc").
+		Find(&histories).Error
+	if err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
+
+// WithJobLock runs fn in a transaction that holds an exclusive advisory lock on the given job.
+// Callers that check the job's history and then insert into it are serialised across all
+// executor instances. The repository passed to fn is bound to the transaction.
+func (r *taskExecutionHistoryRepository) WithJobLock(ctx context.Context, jobID uint, fn func(repo TaskExecutionHistoryRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", jobLockNamespace, int32(jobID)).Error; err != nil {
+			return err
+		}
+		return fn(&taskExecutionHistoryRepository{db: tx})
+	})
+}
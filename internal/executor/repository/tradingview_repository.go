@@ -7,13 +7,17 @@ import (
 	"golang-stock-scryper/internal/executor/config"
 	"golang-stock-scryper/internal/executor/dto"
 	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/metrics"
+	"golang-stock-scryper/pkg/ratelimit"
+	"golang-stock-scryper/pkg/tracing"
 	"io"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
 )
 
 type TradingViewRepository interface {
@@ -24,17 +28,23 @@ type tradingViewRepository struct {
 	cfg            *config.Config
 	log            *logger.Logger
 	httpClient     *http.Client
-	requestLimiter *rate.Limiter
+	requestLimiter *ratelimit.SharedLimiter
 }
 
-func NewTradingViewRepository(cfg *config.Config, log *logger.Logger) TradingViewRepository {
-	secondsPerRequest := time.Minute / time.Duration(cfg.TradingView.MaxRequestPerMinute)
-	requestLimiter := rate.NewLimiter(rate.Every(secondsPerRequest), 1)
+// NewTradingViewRepository creates a new instance of tradingViewRepository. Its request limit is
+// shared with every executor instance through Redis.
+func NewTradingViewRepository(cfg *config.Config, log *logger.Logger, redisClient *redis.Client) TradingViewRepository {
+	requestLimiter := ratelimit.NewSharedLimiter(redisClient, metrics.ProviderTradingView,
+		ratelimit.PerMinute(ratelimit.UnitRequests, int64(cfg.TradingView.MaxRequestPerMinute)),
+	)
+	prometheus.MustRegister(requestLimiter)
+
 	return &tradingViewRepository{
 		cfg: cfg,
 		log: log,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: metrics.NewTransport(metrics.ProviderTradingView, tracing.NewTransport(metrics.ProviderTradingView, nil)),
 		},
 		requestLimiter: requestLimiter,
 	}
@@ -89,11 +99,10 @@ func (r *tradingViewRepository) sendRequest(ctx context.Context, method string,
 	fields := []zap.Field{
 		zap.String("url", url),
 		zap.Int("max_request_per_minute", r.cfg.TradingView.MaxRequestPerMinute),
-		zap.Int("delay", int(r.requestLimiter.Reserve().Delay())),
 		zap.String("payload", jsonStr),
 	}
 
-	if err := r.requestLimiter.Wait(ctx); err != nil {
+	if _, err := r.requestLimiter.Wait(ctx, 0); err != nil {
 		fields = append(fields, zap.Error(err))
 		r.log.ErrorContext(ctx, "Failed to wait for request limit", fields...)
 		return nil, err
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository defines the interface for webhook delivery data operations.
+type WebhookRepository interface {
+	EnqueueExecutionEvent(ctx context.Context, event entity.ExecutionEvent) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+}
+
+// NewWebhookRepository creates a new GORM-based webhook repository.
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// EnqueueExecutionEvent records a pending delivery of the event for every active subscription
+// of the execution's job, or of every job, that asks for the execution's status. An event that
+// was already enqueued for a subscription is not enqueued again.
+func (r *webhookRepository) EnqueueExecutionEvent(ctx context.Context, event entity.ExecutionEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO webhook_deliveries
+			(subscription_id, event_id, event_type, execution_id, job_id, payload, status, attempts, next_attempt_at, created_at)
+		SELECT id, ?, ?, ?, ?, ?, ?, 0, ?, ?
+		FROM webhook_subscriptions
+		WHERE is_active
+			AND (job_id IS NULL OR job_id = ?)
+			AND (jsonb_array_length(statuses) = 0 OR statuses @> jsonb_build_array(?::text))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+		event.ID, event.Type, event.Execution.ID, event.Execution.JobID, string(payload), entity.WebhookDeliveryPending, now, now,
+		event.Execution.JobID, string(event.Execution.Status),
+	).Error
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries that are due, with their subscription,
+// and postpones them by lease so other executor instances do not send them at the same time.
+// The caller records the outcome with UpdateDelivery before the lease ends.
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entity.WebhookDeliveryPending, now).
+			Order("next_attempt_at asc").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		if err := tx.Model(&entity.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error; err != nil {
+			return err
+		}
+		return tx.Preload("Subscription").Where("id IN ?", ids).Order("next_attempt_at asc, id asc").Find(&deliveries).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery records the outcome of a delivery attempt.
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(delivery).Error
+}
@@ -9,13 +9,16 @@ import (
 	"golang-stock-scryper/internal/executor/config"
 	"golang-stock-scryper/internal/executor/dto"
 	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/metrics"
+	"golang-stock-scryper/pkg/ratelimit"
+	"golang-stock-scryper/pkg/tracing"
 	"golang-stock-scryper/pkg/utils"
 	"io"
 	"net/http"
 	"net/url"
-	"time"
 
-	"golang.org/x/time/rate"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 )
 
 type YahooFinanceRepository interface {
@@ -28,16 +31,19 @@ type yahooFinanceRepository struct {
 	client         *http.Client
 	cfg            *config.Config
 	logger         *logger.Logger
-	requestLimiter *rate.Limiter
+	requestLimiter *ratelimit.SharedLimiter
 }
 
-// NewYahooFinanceRepository creates a new instance of yahooFinanceRepository.
-func NewYahooFinanceRepository(cfg *config.Config, log *logger.Logger) (YahooFinanceRepository, error) {
-	secondsPerRequest := time.Minute / time.Duration(cfg.YahooFinance.MaxRequestPerMinute)
-	requestLimiter := rate.NewLimiter(rate.Every(secondsPerRequest), 1)
+// NewYahooFinanceRepository creates a new instance of yahooFinanceRepository. Its request limit
+// is shared with every executor instance through Redis.
+func NewYahooFinanceRepository(cfg *config.Config, log *logger.Logger, redisClient *redis.Client) (YahooFinanceRepository, error) {
+	requestLimiter := ratelimit.NewSharedLimiter(redisClient, metrics.ProviderYahooFinance,
+		ratelimit.PerMinute(ratelimit.UnitRequests, int64(cfg.YahooFinance.MaxRequestPerMinute)),
+	)
+	prometheus.MustRegister(requestLimiter)
 
 	return &yahooFinanceRepository{
-		client:         &http.Client{},
+		client:         &http.Client{Transport: metrics.NewTransport(metrics.ProviderYahooFinance, tracing.NewTransport(metrics.ProviderYahooFinance, nil))},
 		cfg:            cfg,
 		logger:         log,
 		requestLimiter: requestLimiter,
@@ -78,7 +84,7 @@ func (r *yahooFinanceRepository) GetMultiTimeframe(ctx context.Context, stockCod
 }
 
 func (r *yahooFinanceRepository) Get(ctx context.Context, param dto.GetStockDataParam) (*dto.StockData, error) {
-	if err := r.requestLimiter.Wait(ctx); err != nil {
+	if _, err := r.requestLimiter.Wait(ctx, 0); err != nil {
 		return nil, err
 	}
 	// Add .JK suffix for Indonesian stocks
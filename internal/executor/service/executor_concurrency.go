@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/pkg/common"
+	"golang-stock-scryper/pkg/logger"
+)
+
+const (
+	// concurrencyQueuePollInterval is how often a queued execution checks whether earlier runs have finished.
+	concurrencyQueuePollInterval = 5 * time.Second
+	// replacePollInterval is how often a replacing execution checks whether cancelled runs have stopped.
+	replacePollInterval = time.Second
+	// replaceMaxWait bounds how long a replacing execution waits for cancelled runs to stop.
+	replaceMaxWait = 30 * time.Second
+	// staleExecutionGrace is added to the job timeout before a running execution is considered abandoned.
+	staleExecutionGrace = time.Minute
+)
+
+var errExecutionCancelled = errors.New("execution cancelled")
+
+// applyConcurrencyPolicy enforces the job's concurrency policy against earlier executions of the
+// same job that are still running. It returns false when the execution must not run, in which
+// case its history has already been updated. ctx is the execution's cancellation context.
+func (s *executorService) applyConcurrencyPolicy(ctx context.Context, job *entity.Job, history *entity.TaskExecutionHistory) bool {
+	if job.ConcurrencyPolicy == "" || job.ConcurrencyPolicy == entity.ConcurrencyPolicyAllow {
+		return true
+	}
+
+	// A queued execution never waits longer than an earlier run may take before it is
+	// considered abandoned.
+	queueDeadline := time.Now().Add(time.Duration(job.Timeout)*time.Second + staleExecutionGrace)
+	for {
+		running, err := s.findRunningBefore(ctx, job, history)
+		if err != nil {
+			// Fail open: a concurrency check failure should not block the job.
+			s.logger.Error("Failed to check running executions", logger.ErrorField(err), logger.Field("job_id", job.ID))
+			return true
+		}
+		if len(running) == 0 {
+			return true
+		}
+
+		switch job.ConcurrencyPolicy {
+		case entity.ConcurrencyPolicyForbid:
+			s.logger.Info("Skipping execution, previous run still in progress",
+				logger.Field("job_id", job.ID),
+				logger.Field("history_id", history.ID),
+				logger.Field("running_history_id", running[0].ID))
+			s.markSkipped(history, fmt.Sprintf("skipped: execution %d of this job is still running", running[0].ID))
+			return false
+		case entity.ConcurrencyPolicyReplace:
+			for _, previous := range running {
+				s.logger.Info("Cancelling previous execution", logger.Field("job_id", job.ID), logger.Field("history_id", previous.ID), logger.Field("replaced_by_id", history.ID))
+				s.requestCancel(ctx, previous.ID)
+			}
+			return s.awaitReplaced(ctx, job, history)
+		case entity.ConcurrencyPolicyQueue:
+			if time.Now().After(queueDeadline) {
+				s.logger.Warn("Skipping queued execution, previous run did not finish in time",
+					logger.Field("job_id", job.ID),
+					logger.Field("history_id", history.ID),
+					logger.Field("running_history_id", running[0].ID))
+				s.markSkipped(history, fmt.Sprintf("skipped: execution %d of this job did not finish while this one was queued", running[0].ID))
+				return false
+			}
+			s.logger.Debug("Execution queued behind previous run", logger.Field("job_id", job.ID), logger.Field("history_id", history.ID), logger.Field("running_history_id", running[0].ID))
+			if err := s.wait(ctx, concurrencyQueuePollInterval); err != nil {
+				s.markAborted(history, err)
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
+// awaitReplaced waits, for at most replaceMaxWait, until the executions cancelled by a replacing
+// execution have stopped. It returns false when the replacing execution itself was cancelled.
+func (s *executorService) awaitReplaced(ctx context.Context, job *entity.Job, history *entity.TaskExecutionHistory) bool {
+	deadline := time.Now().Add(replaceMaxWait)
+	for time.Now().Before(deadline) {
+		if err := s.wait(ctx, replacePollInterval); err != nil {
+			s.markAborted(history, err)
+			return false
+		}
+		running, err := s.findRunningBefore(ctx, job, history)
+		if err != nil {
+			s.logger.Error("Failed to check running executions", logger.ErrorField(err), logger.Field("job_id", job.ID))
+			return true
+		}
+		if len(running) == 0 {
+			return true
+		}
+	}
+	s.logger.Warn("Replaced execution did not stop in time, running anyway", logger.Field("job_id", job.ID), logger.Field("history_id", history.ID))
+	return true
+}
+
+// findRunningBefore returns the earlier executions of job that are still running and not yet abandoned.
+func (s *executorService) findRunningBefore(ctx context.Context, job *entity.Job, history *entity.TaskExecutionHistory) ([]entity.TaskExecutionHistory, error) {
+	return s.historyRepo.FindRunningBefore(ctx, job.ID, history.ID, time.Now().Add(-time.Duration(job.Timeout)*time.Second-staleExecutionGrace))
+}
+
+// markSkipped records an execution that was not run because of the concurrency policy.
+func (s *executorService) markSkipped(history *entity.TaskExecutionHistory, reason string) {
+	history.Status = entity.StatusSkipped
+	history.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	history.ErrorMessage = sql.NullString{String: reason, Valid: true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.historyRepo.Update(ctx, history); err != nil {
+		s.logger.Error("Failed to update task history", logger.ErrorField(err), logger.Field("history_id", history.ID))
+		return
+	}
+	s.notifyWebhooks(ctx, history)
+}
+
+// requestCancel asks every executor instance to cancel the given execution.
+func (s *executorService) requestCancel(ctx context.Context, historyID uint) {
+	if err := s.redisClient.Publish(ctx, common.RedisChannelTaskExecutionCancel, strconv.FormatUint(uint64(historyID), 10)).Err(); err != nil {
+		s.logger.Error("Failed to publish execution cancellation", logger.ErrorField(err), logger.Field("history_id", historyID))
+	}
+}
+
+// ListenCancellations cancels executions running on this instance when their ID is
+// published on the cancellation channel. It blocks until ctx is done.
+func (s *executorService) ListenCancellations(ctx context.Context) {
+	pubsub := s.redisClient.Subscribe(ctx, common.RedisChannelTaskExecutionCancel)
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			historyID, err := strconv.ParseUint(msg.Payload, 10, 64)
+			if err != nil {
+				s.logger.Warn("Invalid execution cancellation message", logger.StringField("payload", msg.Payload))
+				continue
+			}
+			if s.cancelRunning(uint(historyID)) {
+				s.logger.Info("Execution cancelled", logger.Field("history_id", historyID))
+			}
+		}
+	}
+}
+
+// trackedExecution is an execution held by this instance, from the moment it is taken until
+// it finishes or stops waiting.
+type trackedExecution struct {
+	jobID  uint
+	since  time.Time
+	cancel context.CancelCauseFunc
+}
+
+func (s *executorService) registerRunning(history *entity.TaskExecutionHistory, cancel context.CancelCauseFunc) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	s.running[history.ID] = &trackedExecution{jobID: history.JobID, since: time.Now(), cancel: cancel}
+}
+
+func (s *executorService) unregisterRunning(historyID uint) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	delete(s.running, historyID)
+}
+
+// cancelRunning cancels an execution running on this instance and reports whether it was found.
+func (s *executorService) cancelRunning(historyID uint) bool {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	execution, ok := s.running[historyID]
+	if ok {
+		execution.cancel(errExecutionCancelled)
+	}
+	return ok
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/executor/repository"
+	"golang-stock-scryper/pkg/common"
+	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/tracing"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// triggerDependents enqueues the jobs that depend on a successfully completed job.
+// A dependent job only runs once every one of its upstream jobs has completed
+// successfully since the dependent job last started.
+func (s *executorService) triggerDependents(ctx context.Context, job *entity.Job, history *entity.TaskExecutionHistory) {
+	dependents, err := s.jobRepo.FindDependents(ctx, job.ID)
+	if err != nil {
+		s.logger.Error("Failed to find dependent jobs", logger.ErrorField(err), logger.Field("job_id", job.ID))
+		return
+	}
+
+	for i := range dependents {
+		dependent := &dependents[i]
+		if dependent.IsPausedAt(time.Now()) {
+			s.logger.Info("Skipping paused dependent job", logger.Field("job_id", dependent.ID), logger.Field("upstream_job_id", job.ID))
+			continue
+		}
+		triggeredByID := history.ID
+		var child *entity.TaskExecutionHistory
+
+		// Checking the dependencies and recording the child run happen under a lock on the
+		// dependent job, so upstream jobs finishing at the same time trigger it only once.
+		err := s.historyRepo.WithJobLock(ctx, dependent.ID, func(historyRepo repository.TaskExecutionHistoryRepository) error {
+			ready, err := dependenciesSatisfied(ctx, historyRepo, dependent)
+			if err != nil {
+				return fmt.Errorf("failed to check job dependencies: %w", err)
+			}
+			if !ready {
+				return nil
+			}
+
+			child = &entity.TaskExecutionHistory{
+				JobID:         dependent.ID,
+				Status:        entity.StatusQueued,
+				StartedAt:     time.Now(),
+				Attempt:       1,
+				TriggerType:   entity.TriggerTypeDependency,
+				TriggeredByID: &triggeredByID,
+			}
+			if err := historyRepo.Create(ctx, child); err != nil {
+				return fmt.Errorf("failed to create dependent task history: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			s.logger.Error("Failed to trigger dependent job", logger.ErrorField(err), logger.Field("job_id", dependent.ID))
+			continue
+		}
+		if child == nil {
+			s.logger.Debug("Dependent job is still waiting for upstream jobs", logger.Field("job_id", dependent.ID), logger.Field("upstream_job_id", job.ID))
+			continue
+		}
+		s.notifyWebhooks(ctx, child)
+		s.publishDependent(ctx, child, triggeredByID)
+	}
+}
+
+// publishDependent enqueues the execution of a dependent job, continuing the trace of the
+// upstream execution.
+func (s *executorService) publishDependent(ctx context.Context, child *entity.TaskExecutionHistory, triggeredByID uint) {
+	ctx, span := tracing.StartPublish(ctx, common.RedisStreamSchedulerTaskExecution,
+		attribute.Int("job.id", int(child.JobID)),
+		attribute.Int("execution.id", int(child.ID)),
+		attribute.String("job.trigger_type", string(child.TriggerType)),
+	)
+	defer span.End()
+
+	child.TraceContext = tracing.Inject(ctx)
+	taskPayload, err := json.Marshal(child)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to marshal dependent task payload", logger.ErrorField(err), logger.Field("history_id", child.ID))
+		tracing.RecordError(span, err)
+		return
+	}
+
+	if err := s.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: common.RedisStreamSchedulerTaskExecution,
+		Values: map[string]interface{}{"payload": taskPayload},
+		MaxLen: s.cfg.Redis.StreamMaxLen,
+	}).Err(); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to enqueue dependent task", logger.ErrorField(err), logger.Field("history_id", child.ID))
+		tracing.RecordError(span, err)
+		s.markFailed(ctx, child, err)
+		return
+	}
+
+	s.logger.InfoContext(ctx, "Dependent job triggered",
+		logger.Field("job_id", child.JobID),
+		logger.Field("history_id", child.ID),
+		logger.Field("triggered_by_id", triggeredByID))
+}
+
+// dependenciesSatisfied reports whether every upstream job of job has a successful
+// execution that completed after the latest start of job.
+func dependenciesSatisfied(ctx context.Context, historyRepo repository.TaskExecutionHistoryRepository, job *entity.Job) (bool, error) {
+	latest, err := historyRepo.FindLatestByJobID(ctx, job.ID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, upstreamID := range job.DependsOnJobIDs() {
+		completed, err := historyRepo.FindLatestCompletedByJobID(ctx, upstreamID)
+		if err != nil {
+			return false, err
+		}
+		if completed == nil || !completed.CompletedAt.Valid {
+			return false, nil
+		}
+		if latest != nil && !completed.CompletedAt.Time.After(latest.StartedAt) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"golang-stock-scryper/pkg/common"
+	"golang-stock-scryper/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// defaultTaskClaimMinIdle is used when executor.task_claim_min_idle is not configured.
+	defaultTaskClaimMinIdle = time.Minute
+	// reclaimBatchSize is the number of orphaned task messages claimed at once.
+	reclaimBatchSize = 10
+)
+
+// ReclaimTasks claims task messages that nobody has touched for the claim min idle time and
+// handles them again. Messages held by a live executor are claimed again with every heartbeat,
+// so only messages of an executor that died or was redeployed mid-job are reclaimed. An
+// execution that already finished is acknowledged without running it again, and a running
+// execution whose heartbeat stopped is taken over.
+func (s *executorService) ReclaimTasks(ctx context.Context) {
+	messages, _, err := s.redisClient.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   common.RedisStreamSchedulerTaskExecution,
+		Group:    common.RedisStreamGroup,
+		Consumer: s.cfg.Executor.ConsumerName,
+		MinIdle:  s.claimMinIdle,
+		Start:    "0-0",
+		Count:    reclaimBatchSize,
+	}).Result()
+	if err != nil {
+		if err == context.Canceled || err == redis.Nil {
+			return
+		}
+		s.logger.Error("Failed to reclaim orphaned tasks", logger.ErrorField(err))
+		return
+	}
+
+	for _, message := range messages {
+		s.logger.Warn("Reclaimed orphaned task", logger.StringField("message_id", message.ID))
+		s.handleTask(ctx, message)
+	}
+}
+
+// holdMessage records that this instance handles a task message. It returns false when the
+// message is already held.
+func (s *executorService) holdMessage(messageID string) bool {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	if _, ok := s.held[messageID]; ok {
+		return false
+	}
+	s.held[messageID] = struct{}{}
+	return true
+}
+
+func (s *executorService) releaseMessage(messageID string) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	delete(s.held, messageID)
+}
+
+// ack acknowledges and deletes a task message and stops holding it.
+func (s *executorService) ack(messageID string) {
+	s.releaseMessage(messageID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.redisClient.XAck(ctx, common.RedisStreamSchedulerTaskExecution, common.RedisStreamGroup, messageID).Err(); err != nil {
+		s.logger.Error("Failed to acknowledge task", logger.ErrorField(err), logger.StringField("message_id", messageID))
+		return
+	}
+	if err := s.redisClient.XDel(ctx, common.RedisStreamSchedulerTaskExecution, messageID).Err(); err != nil {
+		s.logger.Error("Failed to delete task", logger.ErrorField(err), logger.StringField("message_id", messageID))
+	}
+}
+
+// refreshHeldMessages claims the held task messages again, which resets their idle time, so
+// ReclaimTasks leaves them alone while this instance is alive.
+func (s *executorService) refreshHeldMessages(ctx context.Context) {
+	s.runningMu.Lock()
+	ids := make([]string, 0, len(s.held))
+	for id := range s.held {
+		ids = append(ids, id)
+	}
+	s.runningMu.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	err := s.redisClient.XClaimJustID(ctx, &redis.XClaimArgs{
+		Stream:   common.RedisStreamSchedulerTaskExecution,
+		Group:    common.RedisStreamGroup,
+		Consumer: s.cfg.Executor.ConsumerName,
+		Messages: ids,
+	}).Err()
+	if err != nil {
+		s.logger.Error("Failed to refresh held tasks", logger.ErrorField(err), logger.IntField("tasks", len(ids)))
+	}
+}
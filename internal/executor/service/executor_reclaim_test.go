@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/executor/repository"
+	"golang-stock-scryper/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHistoryRepository struct {
+	repository.TaskExecutionHistoryRepository
+	histories map[uint]*entity.TaskExecutionHistory
+	err       error
+}
+
+func (r *fakeHistoryRepository) FindByID(ctx context.Context, id uint) (*entity.TaskExecutionHistory, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.histories[id], nil
+}
+
+// newTestExecutorService creates an executor whose Redis is unreachable and whose job repository
+// must not be used.
+func newTestExecutorService(t *testing.T, historyRepo repository.TaskExecutionHistoryRepository) *executorService {
+	log, err := logger.New("error", "json")
+	require.NoError(t, err)
+	redisClient := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0", DialTimeout: 10 * time.Millisecond, MaxRetries: -1})
+	t.Cleanup(func() { redisClient.Close() })
+	return &executorService{
+		redisClient: redisClient,
+		historyRepo: historyRepo,
+		logger:      log,
+		running:     make(map[uint]*trackedExecution),
+		held:        make(map[string]struct{}),
+	}
+}
+
+func TestHandleTaskDoesNotRunFinishedExecutionAgain(t *testing.T) {
+	svc := newTestExecutorService(t, &fakeHistoryRepository{histories: map[uint]*entity.TaskExecutionHistory{
+		7: {ID: 7, JobID: 1, Status: entity.StatusCompleted},
+	}})
+
+	svc.handleTask(context.Background(), redis.XMessage{ID: "1-0", Values: map[string]interface{}{"payload": `{"ID":7,"JobID":1}`}})
+
+	assert.Empty(t, svc.held)
+	assert.Empty(t, svc.running)
+}
+
+func TestHandleTaskLeavesTaskPendingWhenHistoryLookupFails(t *testing.T) {
+	svc := newTestExecutorService(t, &fakeHistoryRepository{err: errors.New("connection refused")})
+
+	svc.handleTask(context.Background(), redis.XMessage{ID: "1-0", Values: map[string]interface{}{"payload": `{"ID":7,"JobID":1}`}})
+
+	assert.Empty(t, svc.held, "the task is released so it can be reclaimed")
+	assert.Empty(t, svc.running)
+}
+
+func TestHoldMessageRejectsMessageAlreadyHeld(t *testing.T) {
+	svc := newTestExecutorService(t, nil)
+
+	require.True(t, svc.holdMessage("1-0"))
+	assert.False(t, svc.holdMessage("1-0"))
+	svc.releaseMessage("1-0")
+	assert.True(t, svc.holdMessage("1-0"))
+}
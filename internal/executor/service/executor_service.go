@@ -4,33 +4,60 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"golang-stock-scryper/internal/entity"
 	"golang-stock-scryper/internal/executor/config"
+	"golang-stock-scryper/internal/executor/dto"
 	"golang-stock-scryper/internal/executor/repository"
 	"golang-stock-scryper/internal/executor/strategy"
 	"golang-stock-scryper/pkg/common"
 	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/tracing"
 	"golang-stock-scryper/pkg/utils"
 
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 )
 
 // ExecutorService manages the execution of tasks.
 type ExecutorService interface {
 	ProcessTask(ctx context.Context)
+	ReclaimTasks(ctx context.Context)
+	ListenCancellations(ctx context.Context)
+	SendHeartbeats(ctx context.Context)
+	State() dto.ExecutorState
+	Close()
 }
 
+// errExecutorShutdown is the cause recorded for executions that were still waiting when the executor stopped.
+var errExecutorShutdown = errors.New("executor shutting down")
+
+// errExecutionNotQueued is returned when an execution is no longer queued by the time it would
+// start, because it was cancelled, reaped or picked up by another executor.
+var errExecutionNotQueued = errors.New("execution is no longer queued")
+
 type executorService struct {
 	cfg                *config.Config
 	redisClient        *redis.Client
 	jobRepo            repository.JobRepository
 	historyRepo        repository.TaskExecutionHistoryRepository
+	webhookRepo        repository.WebhookRepository
 	logger             *logger.Logger
 	executorStrategies map[entity.JobType]strategy.JobExecutionStrategy
 	semaphore          chan struct{}
+	running            map[uint]*trackedExecution // in-flight executions on this instance, by history ID
+	held               map[string]struct{}        // task messages this instance has not acknowledged yet, by message ID
+	runningMu          sync.Mutex
+	claimMinIdle       time.Duration
+	ctx                context.Context // done when the executor is closed, ending every wait
+	close              context.CancelFunc
 }
 
 // NewExecutorService creates a new ExecutorService.
@@ -39,6 +66,7 @@ func NewExecutorService(
 	redisClient *redis.Client,
 	jobRepo repository.JobRepository,
 	historyRepo repository.TaskExecutionHistoryRepository,
+	webhookRepo repository.WebhookRepository,
 	log *logger.Logger,
 	strategies []strategy.JobExecutionStrategy,
 ) ExecutorService {
@@ -47,22 +75,40 @@ func NewExecutorService(
 		strategyMap[s.GetType()] = s
 	}
 
+	claimMinIdle := cfg.Executor.TaskClaimMinIdle
+	if claimMinIdle <= 0 {
+		claimMinIdle = defaultTaskClaimMinIdle
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	return &executorService{
 		cfg:                cfg,
 		redisClient:        redisClient,
 		jobRepo:            jobRepo,
 		historyRepo:        historyRepo,
+		webhookRepo:        webhookRepo,
 		logger:             log,
 		executorStrategies: strategyMap,
 		semaphore:          make(chan struct{}, cfg.Executor.MaxConcurrentTasks),
+		running:            make(map[uint]*trackedExecution),
+		held:               make(map[string]struct{}),
+		claimMinIdle:       claimMinIdle,
+		ctx:                ctx,
+		close:              cancel,
 	}
 }
 
+// Close stops executions that are waiting for a retry or for a free slot. Executions that are
+// already running are left to finish.
+func (s *executorService) Close() {
+	s.close()
+}
+
 // ProcessTask dequeues and executes a single task.
 func (s *executorService) ProcessTask(ctx context.Context) {
 	streams, err := s.redisClient.XReadGroup(ctx, &redis.XReadGroupArgs{
 		Group:    common.RedisStreamGroup,
-		Consumer: common.RedisStreamConsumer,
+		Consumer: s.cfg.Executor.ConsumerName,
 		Streams:  []string{common.RedisStreamSchedulerTaskExecution, ">"}, // ">" means only new messages
 		Count:    1,
 		Block:    2 * time.Second, // Block for 2 seconds to allow graceful shutdown
@@ -81,63 +127,278 @@ func (s *executorService) ProcessTask(ctx context.Context) {
 		return
 	}
 
-	message := streams[0].Messages[0]
+	s.handleTask(ctx, streams[0].Messages[0])
+}
 
-	// Fire and forget
+// handleTask starts the execution carried by a task message. The message is acknowledged once the
+// execution and its retries are finished, or right away when there is nothing to execute. It is
+// left pending when the execution cannot be looked up, so ReclaimTasks hands it out again.
+func (s *executorService) handleTask(ctx context.Context, message redis.XMessage) {
+	// A message already held by this instance is still executing here.
+	if !s.holdMessage(message.ID) {
+		return
+	}
+	handedOver := false
 	defer func() {
-		s.redisClient.XAck(ctx, common.RedisStreamSchedulerTaskExecution, common.RedisStreamGroup, message.ID)
-		s.redisClient.XDel(ctx, common.RedisStreamSchedulerTaskExecution, message.ID)
+		if !handedOver {
+			s.releaseMessage(message.ID)
+		}
 	}()
 
 	// The task data is expected to be a JSON string in the 'payload' field.
 	taskData, ok := message.Values["payload"].(string)
 	if !ok {
 		s.logger.Error("field 'payload' not found or not a string in stream message", logger.Field("message_id", message.ID))
+		s.ack(message.ID)
 		return
 	}
 
 	var taskHistory entity.TaskExecutionHistory
 	if err := json.Unmarshal([]byte(taskData), &taskHistory); err != nil {
 		s.logger.Error("Failed to unmarshal task data", logger.ErrorField(err), logger.Field("message_id", message.ID))
-
+		s.ack(message.ID)
 		return
 	}
 
-	s.logger.Info("Processing job", logger.Field("job_id", taskHistory.JobID), logger.Field("history_id", taskHistory.ID))
+	ctx, span := tracing.StartProcess(ctx, common.RedisStreamSchedulerTaskExecution, taskHistory.TraceContext,
+		attribute.Int("job.id", int(taskHistory.JobID)),
+		attribute.Int("execution.id", int(taskHistory.ID)),
+	)
+	defer span.End()
+
+	s.logger.InfoContext(ctx, "Processing job", logger.Field("job_id", taskHistory.JobID), logger.Field("history_id", taskHistory.ID))
+
+	// An execution that is already finished, because it was cancelled or reaped while it waited in
+	// the stream or because it completed before its message was acknowledged, is not run again.
+	current, err := s.historyRepo.FindByID(ctx, taskHistory.ID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.ErrorContext(ctx, "Dropping task of unknown execution", logger.Field("history_id", taskHistory.ID))
+			s.ack(message.ID)
+			return
+		}
+		s.logger.ErrorContext(ctx, "Failed to find task history, leaving the task to be reclaimed", logger.ErrorField(err), logger.Field("history_id", taskHistory.ID))
+		return
+	}
+	if !current.Status.IsActive() {
+		s.logger.InfoContext(ctx, "Dropping execution that is no longer queued", logger.Field("history_id", taskHistory.ID), logger.StringField("status", string(current.Status)))
+		s.ack(message.ID)
+		return
+	}
 
 	job, err := s.jobRepo.FindByID(ctx, taskHistory.JobID)
 	if err != nil {
-		s.logger.Error("Failed to find job", logger.ErrorField(err), logger.Field("job_id", taskHistory.JobID))
+		tracing.RecordError(span, err)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.ErrorContext(ctx, "Failed to find job", logger.ErrorField(err), logger.Field("job_id", taskHistory.JobID))
+			s.ack(message.ID)
+			return
+		}
+		s.logger.ErrorContext(ctx, "Failed to find job, leaving the task to be reclaimed", logger.ErrorField(err), logger.Field("job_id", taskHistory.JobID))
 		return
 	}
 
+	if len(taskHistory.PayloadOverride) > 0 {
+		job.Payload = taskHistory.PayloadOverride
+	}
+
+	// The execution outlives this message, so it keeps the trace but not the read context.
+	traceCtx := trace.ContextWithSpan(context.Background(), span)
+	handedOver = true
 	utils.GoSafe(func() {
-		s.semaphore <- struct{}{}
-		defer func() { <-s.semaphore }()
-		executionCtx, cancelExec := context.WithTimeout(context.Background(), time.Duration(job.Timeout)*time.Second)
-		defer cancelExec()
+		defer s.ack(message.ID)
+		s.executeWithRetry(traceCtx, job, &taskHistory)
+	})
 
-		s.executeAndUpdate(executionCtx, job, &taskHistory)
+}
 
-	})
+// executeWithRetry runs the job and, while the job's retry policy allows it, re-runs failed
+// executions after the configured backoff. Every attempt is recorded as its own history row
+// linked to the original execution through RetryOfID. An attempt can be cancelled from the
+// moment its row exists, including while it waits for its backoff or for a free slot.
+// traceCtx carries the trace the attempts are recorded in and is never cancelled.
+func (s *executorService) executeWithRetry(traceCtx context.Context, job *entity.Job, history *entity.TaskExecutionHistory) {
+	policy := job.GetRetryPolicy()
+	if history.Attempt == 0 {
+		history.Attempt = 1
+	}
+
+	cancelCtx, cancel := s.track(traceCtx, history)
+	if !s.applyConcurrencyPolicy(cancelCtx, job, history) {
+		s.untrack(history.ID, cancel)
+		return
+	}
+
+	for {
+		err := s.start(cancelCtx, history)
+		if errors.Is(err, errExecutionNotQueued) {
+			s.logger.Info("Execution is no longer queued, not running it", logger.Field("job_id", job.ID), logger.Field("history_id", history.ID))
+		} else if err != nil {
+			s.markAborted(history, err)
+		} else {
+			executionCtx, cancelExec := context.WithTimeout(cancelCtx, time.Duration(job.Timeout)*time.Second)
+			err = s.executeAndUpdate(executionCtx, job, history)
+			cancelExec()
+			<-s.semaphore
+		}
+		s.untrack(history.ID, cancel)
+
+		if err == nil {
+			s.triggerDependents(traceCtx, job, history)
+			return
+		}
+		if errors.Is(err, errExecutionCancelled) || errors.Is(err, errExecutorShutdown) || errors.Is(err, errExecutionNotQueued) || !policy.ShouldRetry(history.Attempt) {
+			return
+		}
+
+		delay := policy.Backoff(history.Attempt)
+		s.logger.Info("Retrying failed job execution",
+			logger.Field("job_id", job.ID),
+			logger.IntField("history_id", int(history.ID)),
+			logger.IntField("attempt", history.Attempt),
+			logger.IntField("max_retries", policy.MaxRetries),
+			logger.Field("backoff", delay.String()),
+		)
+
+		originalID := history.ID
+		if history.RetryOfID != nil {
+			originalID = *history.RetryOfID
+		}
+
+		// The retry row is created before the backoff so that the retry can be cancelled while it waits.
+		retry := &entity.TaskExecutionHistory{
+			JobID:           history.JobID,
+			ScheduleID:      history.ScheduleID,
+			Status:          entity.StatusQueued,
+			StartedAt:       time.Now(),
+			Attempt:         history.Attempt + 1,
+			RetryOfID:       &originalID,
+			TriggerType:     history.TriggerType,
+			PayloadOverride: history.PayloadOverride,
+		}
+		if err := s.historyRepo.Create(context.Background(), retry); err != nil {
+			s.logger.Error("Failed to create retry task history", logger.ErrorField(err), logger.Field("job_id", job.ID), logger.IntField("retry_of_id", int(originalID)))
+			return
+		}
+		s.notifyWebhooks(context.Background(), retry)
+		history = retry
+
+		cancelCtx, cancel = s.track(traceCtx, history)
+		if err := s.wait(cancelCtx, delay); err != nil {
+			s.markAborted(history, err)
+			s.untrack(history.ID, cancel)
+			return
+		}
+	}
+}
+
+// start takes a slot of the concurrency semaphore and moves the execution from queued to
+// running. The slot is released again when the execution must not run.
+func (s *executorService) start(ctx context.Context, history *entity.TaskExecutionHistory) error {
+	if err := s.acquire(ctx); err != nil {
+		return err
+	}
+
+	markCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	started, err := s.historyRepo.MarkRunning(markCtx, history, time.Now().Add(-s.claimMinIdle))
+	if err != nil {
+		// Fail open: a failed status update should not block the job.
+		s.logger.Error("Failed to mark execution as running", logger.ErrorField(err), logger.Field("history_id", history.ID))
+		history.Status = entity.StatusRunning
+		history.StartedAt = time.Now()
+		return nil
+	}
+	if !started {
+		<-s.semaphore
+		return errExecutionNotQueued
+	}
+	s.notifyWebhooks(markCtx, history)
+	return nil
+}
+
+// track registers an execution as cancellable on this instance and returns a context derived from
+// parent that is cancelled with errExecutionCancelled when a cancellation for it is received.
+func (s *executorService) track(parent context.Context, history *entity.TaskExecutionHistory) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	s.registerRunning(history, cancel)
+	return ctx, cancel
+}
+
+// untrack removes an execution registered with track and releases its context.
+func (s *executorService) untrack(historyID uint, cancel context.CancelCauseFunc) {
+	s.unregisterRunning(historyID)
+	cancel(nil)
+}
+
+// wait blocks for d. It returns early with the cancellation cause when ctx is cancelled, or
+// with errExecutorShutdown when the executor is closed.
+func (s *executorService) wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-s.ctx.Done():
+		return errExecutorShutdown
+	}
+}
 
+// acquire takes a slot of the concurrency semaphore, giving up like wait does.
+func (s *executorService) acquire(ctx context.Context) error {
+	select {
+	case s.semaphore <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	case <-s.ctx.Done():
+		return errExecutorShutdown
+	}
 }
 
-func (s *executorService) executeAndUpdate(ctx context.Context, job *entity.Job, history *entity.TaskExecutionHistory) {
+func (s *executorService) executeAndUpdate(ctx context.Context, job *entity.Job, history *entity.TaskExecutionHistory) error {
+	ctx, span := tracing.Tracer().Start(ctx, "execute "+string(job.Type), trace.WithAttributes(
+		attribute.Int("job.id", int(job.ID)),
+		attribute.Int("execution.id", int(history.ID)),
+		attribute.Int("execution.attempt", history.Attempt),
+	))
+	defer span.End()
+
+	var execErr error
 	strategy, ok := s.executorStrategies[job.Type]
 	if !ok {
-		err := fmt.Errorf("no executor strategy found for task type: %s", job.Type)
-		s.logger.Error("Job execution failed", logger.ErrorField(err), logger.Field("job_id", job.ID))
+		execErr = fmt.Errorf("no executor strategy found for task type: %s", job.Type)
+		s.logger.ErrorContext(ctx, "Job execution failed", logger.ErrorField(execErr), logger.Field("job_id", job.ID))
 		history.Status = entity.StatusFailed
-		history.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+		history.ErrorMessage = sql.NullString{String: execErr.Error(), Valid: true}
 	} else {
 		output, err := strategy.Execute(ctx, job)
-		if err != nil {
-			s.logger.Error("Job execution failed", logger.ErrorField(err), logger.Field("job_id", job.ID), logger.IntField("history_id", int(history.ID)))
+		// A strategy may return normally with the results gathered so far once its context is
+		// cancelled, so the cancellation is detected from the context rather than from err.
+		if errors.Is(context.Cause(ctx), errExecutionCancelled) {
+			s.logger.InfoContext(ctx, "Job execution cancelled", logger.Field("job_id", job.ID), logger.IntField("history_id", int(history.ID)))
+			history.Status = entity.StatusCancelled
+			history.ErrorMessage = sql.NullString{String: errExecutionCancelled.Error(), Valid: true}
+			execErr = errExecutionCancelled
+		} else if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			s.logger.ErrorContext(ctx, "Job execution timed out", logger.ErrorField(err), logger.Field("job_id", job.ID), logger.IntField("history_id", int(history.ID)), logger.IntField("attempt", history.Attempt))
+			history.Status = entity.StatusTimeout
+			execErr = fmt.Errorf("execution exceeded the job timeout of %ds", job.Timeout)
+			if err != nil {
+				execErr = fmt.Errorf("%w: %v", execErr, err)
+			}
+			history.ErrorMessage = sql.NullString{String: execErr.Error(), Valid: true}
+		} else if err != nil {
+			s.logger.ErrorContext(ctx, "Job execution failed", logger.ErrorField(err), logger.Field("job_id", job.ID), logger.IntField("history_id", int(history.ID)), logger.IntField("attempt", history.Attempt))
 			history.Status = entity.StatusFailed
 			history.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+			execErr = err
 		} else {
-			s.logger.Info("Job executed successfully", logger.Field("job_id", job.ID), logger.IntField("history_id", int(history.ID)))
+			s.logger.InfoContext(ctx, "Job executed successfully", logger.Field("job_id", job.ID), logger.IntField("history_id", int(history.ID)), logger.IntField("attempt", history.Attempt))
 			history.Status = entity.StatusCompleted
 		}
 		history.Output = sql.NullString{String: output, Valid: true}
@@ -145,9 +406,101 @@ func (s *executorService) executeAndUpdate(ctx context.Context, job *entity.Job,
 
 	history.CompletedAt.Time = time.Now()
 	history.CompletedAt.Valid = true
+	recordExecution(job, history)
+	span.SetAttributes(attribute.String("execution.status", string(history.Status)))
+	tracing.RecordError(span, execErr)
 
+	// Drop the cancellation so the final status is persisted even if the execution timed out.
+	updateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := s.historyRepo.Update(updateCtx, history); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update task history", logger.ErrorField(err), logger.Field("history_id", history.ID))
+	} else {
+		s.notifyWebhooks(updateCtx, history)
+	}
+	s.logger.InfoContext(ctx, "Job execution completed", logger.Field("job_id", job.ID), logger.IntField("history_id", int(history.ID)))
+	return execErr
+}
+
+// markAborted records an execution that stopped before it started running, either because it
+// was cancelled or because the executor shut down.
+func (s *executorService) markAborted(history *entity.TaskExecutionHistory, cause error) {
+	history.Status = entity.StatusFailed
+	if errors.Is(cause, errExecutionCancelled) {
+		history.Status = entity.StatusCancelled
+	}
+	history.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	history.ErrorMessage = sql.NullString{String: cause.Error(), Valid: true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 	if err := s.historyRepo.Update(ctx, history); err != nil {
 		s.logger.Error("Failed to update task history", logger.ErrorField(err), logger.Field("history_id", history.ID))
+	} else {
+		s.notifyWebhooks(ctx, history)
+	}
+	s.logger.Info("Job execution aborted before running", logger.Field("job_id", history.JobID), logger.Field("history_id", history.ID), logger.StringField("reason", cause.Error()))
+}
+
+// SendHeartbeats records a heartbeat for every execution this instance holds, whether it is
+// running or still waiting for a retry or a free slot, so the scheduler can tell them apart
+// from executions whose executor died. It also keeps the task messages of these executions
+// from being reclaimed by other executors.
+func (s *executorService) SendHeartbeats(ctx context.Context) {
+	s.runningMu.Lock()
+	ids := make([]uint, 0, len(s.running))
+	for id := range s.running {
+		ids = append(ids, id)
+	}
+	s.runningMu.Unlock()
+
+	if err := s.historyRepo.Heartbeat(ctx, ids); err != nil {
+		s.logger.Error("Failed to send execution heartbeats", logger.ErrorField(err), logger.IntField("executions", len(ids)))
+	}
+	s.refreshHeldMessages(ctx)
+}
+
+// State reports the strategies of this instance, the executions it holds and how many of its
+// concurrency slots are taken.
+func (s *executorService) State() dto.ExecutorState {
+	state := dto.ExecutorState{
+		Consumer:          s.cfg.Executor.ConsumerName,
+		Strategies:        make([]string, 0, len(s.executorStrategies)),
+		SemaphoreInUse:    len(s.semaphore),
+		SemaphoreCapacity: cap(s.semaphore),
+	}
+	for jobType := range s.executorStrategies {
+		state.Strategies = append(state.Strategies, string(jobType))
+	}
+	sort.Strings(state.Strategies)
+
+	s.runningMu.Lock()
+	state.InFlight = make([]dto.InFlightExecution, 0, len(s.running))
+	for id, execution := range s.running {
+		state.InFlight = append(state.InFlight, dto.InFlightExecution{HistoryID: id, JobID: execution.jobID, Since: execution.since})
+	}
+	s.runningMu.Unlock()
+	sort.Slice(state.InFlight, func(i, j int) bool { return state.InFlight[i].HistoryID < state.InFlight[j].HistoryID })
+
+	return state
+}
+
+// markFailed records a history that could not be handed over for execution as failed.
+func (s *executorService) markFailed(ctx context.Context, history *entity.TaskExecutionHistory, err error) {
+	history.Status = entity.StatusFailed
+	history.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	history.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+	if errUpdate := s.historyRepo.Update(ctx, history); errUpdate != nil {
+		s.logger.Error("Failed to update task history", logger.ErrorField(errUpdate), logger.Field("history_id", history.ID))
+		return
+	}
+	s.notifyWebhooks(ctx, history)
+}
+
+// notifyWebhooks enqueues the current status of an execution as an event for the webhook
+// subscriptions that ask for it. A failure is logged and does not affect the execution.
+func (s *executorService) notifyWebhooks(ctx context.Context, history *entity.TaskExecutionHistory) {
+	if err := s.webhookRepo.EnqueueExecutionEvent(ctx, entity.NewExecutionEvent(history, time.Now())); err != nil {
+		s.logger.Error("Failed to enqueue webhook event", logger.ErrorField(err), logger.Field("history_id", history.ID), logger.StringField("status", string(history.Status)))
 	}
-	s.logger.Info("Job execution completed", logger.Field("job_id", job.ID), logger.IntField("history_id", int(history.ID)))
 }
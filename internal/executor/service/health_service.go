@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang-stock-scryper/internal/executor/dto"
+	"golang-stock-scryper/pkg/common"
+	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/telegram"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// streamLoopGrace is added to the timeout of a stream read loop before the loop is
+	// considered stuck.
+	streamLoopGrace = 30 * time.Second
+	// telegramCheckInterval is how long the result of the Telegram readiness check is reused,
+	// so frequent probes do not call the Telegram API every time.
+	telegramCheckInterval = time.Minute
+)
+
+// StreamLoop is the state of a stream read loop of this instance.
+type StreamLoop struct {
+	Stream     string
+	Timeout    time.Duration // bound of a single read and its handling
+	LastPollAt time.Time     // when the loop last completed a read, or started
+}
+
+// StreamLoopMonitor reports the state of the stream read loops.
+type StreamLoopMonitor interface {
+	StreamLoops() []StreamLoop
+}
+
+// HealthCheck is a named dependency check of the readiness probe.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthService answers the liveness and readiness probes and describes this executor instance.
+type HealthService interface {
+	Live() dto.HealthResponse
+	Ready(ctx context.Context) dto.HealthResponse
+	AdminStatus(ctx context.Context) dto.AdminStatusResponse
+}
+
+type healthService struct {
+	redisClient *redis.Client
+	executor    ExecutorService
+	loops       StreamLoopMonitor
+	checks      []HealthCheck
+	logger      *logger.Logger
+	now         func() time.Time
+}
+
+// NewHealthService creates a new HealthService. Readiness requires Postgres and Redis to answer,
+// the executor consumer group to exist on every stream and the Telegram bot to be reachable.
+func NewHealthService(db *gorm.DB, redisClient *redis.Client, notifier telegram.Notifier, executor ExecutorService, loops StreamLoopMonitor, log *logger.Logger) HealthService {
+	return &healthService{
+		redisClient: redisClient,
+		executor:    executor,
+		loops:       loops,
+		checks: []HealthCheck{
+			{Name: "postgres", Check: postgresCheck(db)},
+			{Name: "redis", Check: func(ctx context.Context) error { return redisClient.Ping(ctx).Err() }},
+			{Name: "consumer_groups", Check: consumerGroupCheck(redisClient)},
+			{Name: "telegram", Check: cachedCheck(notifier.Ping, telegramCheckInterval)},
+		},
+		logger: log,
+		now:    time.Now,
+	}
+}
+
+// Live reports whether every stream read loop completed a read within its timeout and grace.
+// A loop that does not is stuck in its handler and only a restart recovers it.
+func (s *healthService) Live() dto.HealthResponse {
+	response := dto.HealthResponse{Status: dto.HealthStatusOK, Checks: make(map[string]dto.HealthCheckResult)}
+	now := s.now()
+	for _, loop := range s.loops.StreamLoops() {
+		result := dto.HealthCheckResult{Status: dto.HealthStatusOK}
+		if since := now.Sub(loop.LastPollAt); since > loop.Timeout+streamLoopGrace {
+			result = dto.HealthCheckResult{Status: dto.HealthStatusError, Error: fmt.Sprintf("no read completed for %s", since.Round(time.Second))}
+			response.Status = dto.HealthStatusError
+		}
+		response.Checks["stream:"+loop.Stream] = result
+	}
+	return response
+}
+
+// Ready runs every readiness check concurrently.
+func (s *healthService) Ready(ctx context.Context) dto.HealthResponse {
+	response := dto.HealthResponse{Status: dto.HealthStatusOK, Checks: make(map[string]dto.HealthCheckResult, len(s.checks))}
+	results := make([]error, len(s.checks))
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = check.Check(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for i, check := range s.checks {
+		if err := results[i]; err != nil {
+			s.logger.Warn("Readiness check failed", logger.StringField("check", check.Name), logger.ErrorField(err))
+			response.Checks[check.Name] = dto.HealthCheckResult{Status: dto.HealthStatusError, Error: err.Error()}
+			response.Status = dto.HealthStatusError
+			continue
+		}
+		response.Checks[check.Name] = dto.HealthCheckResult{Status: dto.HealthStatusOK}
+	}
+	return response
+}
+
+// AdminStatus describes the executions of this instance and the executor consumer group of every
+// stream. A stream that cannot be read from Redis is reported with its error.
+func (s *healthService) AdminStatus(ctx context.Context) dto.AdminStatusResponse {
+	lastPolls := make(map[string]time.Time)
+	for _, loop := range s.loops.StreamLoops() {
+		lastPolls[loop.Stream] = loop.LastPollAt
+	}
+
+	response := dto.AdminStatusResponse{Executor: s.executor.State()}
+	for _, stream := range common.RedisExecutorStreams {
+		state, err := s.streamState(ctx, stream)
+		if err != nil {
+			state = dto.StreamState{Stream: stream, Group: common.RedisStreamGroup, Error: err.Error()}
+		}
+		if lastPoll, ok := lastPolls[stream]; ok {
+			state.LastPollAt = &lastPoll
+		}
+		response.Streams = append(response.Streams, state)
+	}
+	return response
+}
+
+func (s *healthService) streamState(ctx context.Context, stream string) (dto.StreamState, error) {
+	group, err := findConsumerGroup(ctx, s.redisClient, stream)
+	if err != nil {
+		return dto.StreamState{}, err
+	}
+	consumers, err := s.redisClient.XInfoConsumers(ctx, stream, common.RedisStreamGroup).Result()
+	if err != nil {
+		return dto.StreamState{}, err
+	}
+
+	state := dto.StreamState{
+		Stream:          stream,
+		Group:           group.Name,
+		Pending:         group.Pending,
+		Lag:             group.Lag,
+		LastDeliveredID: group.LastDeliveredID,
+		Consumers:       make([]dto.StreamConsumerState, 0, len(consumers)),
+	}
+	for _, consumer := range consumers {
+		state.Consumers = append(state.Consumers, dto.StreamConsumerState{
+			Name:    consumer.Name,
+			Pending: consumer.Pending,
+			IdleMs:  consumer.Idle.Milliseconds(),
+		})
+	}
+	return state, nil
+}
+
+// errConsumerGroupNotFound is returned when a stream has no executor consumer group.
+var errConsumerGroupNotFound = errors.New("consumer group not found")
+
+// findConsumerGroup returns the executor consumer group of a stream.
+func findConsumerGroup(ctx context.Context, redisClient *redis.Client, stream string) (*redis.XInfoGroup, error) {
+	groups, err := redisClient.XInfoGroups(ctx, stream).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].Name == common.RedisStreamGroup {
+			return &groups[i], nil
+		}
+	}
+	return nil, errConsumerGroupNotFound
+}
+
+func postgresCheck(db *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+func consumerGroupCheck(redisClient *redis.Client) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for _, stream := range common.RedisExecutorStreams {
+			if _, err := findConsumerGroup(ctx, redisClient, stream); err != nil {
+				return fmt.Errorf("stream %s: %w", stream, err)
+			}
+		}
+		return nil
+	}
+}
+
+// cachedCheck runs check at most once per interval and returns its last result in between.
+func cachedCheck(check func(ctx context.Context) error, interval time.Duration) func(ctx context.Context) error {
+	var (
+		mu        sync.Mutex
+		checkedAt time.Time
+		lastErr   error
+	)
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !checkedAt.IsZero() && time.Since(checkedAt) < interval {
+			return lastErr
+		}
+		lastErr = check(ctx)
+		checkedAt = time.Now()
+		return lastErr
+	}
+}
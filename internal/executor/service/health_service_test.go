@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/executor/config"
+	"golang-stock-scryper/internal/executor/dto"
+	"golang-stock-scryper/internal/executor/strategy"
+	"golang-stock-scryper/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStreamLoopMonitor []StreamLoop
+
+func (m fakeStreamLoopMonitor) StreamLoops() []StreamLoop {
+	return m
+}
+
+func newTestHealthService(t *testing.T, loops []StreamLoop, checks []HealthCheck, now time.Time) *healthService {
+	log, err := logger.New("error", "json")
+	require.NoError(t, err)
+	return &healthService{
+		loops:  fakeStreamLoopMonitor(loops),
+		checks: checks,
+		logger: log,
+		now:    func() time.Time { return now },
+	}
+}
+
+func TestHealthServiceLiveFailsOnStuckLoop(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	svc := newTestHealthService(t, []StreamLoop{
+		{Stream: "schedule.task.execution", Timeout: 10 * time.Second, LastPollAt: now.Add(-5 * time.Second)},
+		{Stream: "stock.analyzer", Timeout: 10 * time.Second, LastPollAt: now.Add(-time.Minute)},
+	}, nil, now)
+
+	response := svc.Live()
+
+	assert.Equal(t, dto.HealthStatusError, response.Status)
+	assert.Equal(t, dto.HealthStatusOK, response.Checks["stream:schedule.task.execution"].Status)
+	assert.Equal(t, dto.HealthStatusError, response.Checks["stream:stock.analyzer"].Status)
+	assert.Equal(t, "no read completed for 1m0s", response.Checks["stream:stock.analyzer"].Error)
+}
+
+func TestHealthServiceReadyReportsEveryCheck(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	svc := newTestHealthService(t, nil, []HealthCheck{
+		{Name: "postgres", Check: ok},
+		{Name: "redis", Check: ok},
+	}, time.Now())
+
+	assert.Equal(t, dto.HealthResponse{Status: dto.HealthStatusOK, Checks: map[string]dto.HealthCheckResult{
+		"postgres": {Status: dto.HealthStatusOK},
+		"redis":    {Status: dto.HealthStatusOK},
+	}}, svc.Ready(context.Background()))
+
+	svc.checks = append(svc.checks, HealthCheck{Name: "telegram", Check: func(ctx context.Context) error { return errors.New("unauthorized") }})
+
+	response := svc.Ready(context.Background())
+	assert.Equal(t, dto.HealthStatusError, response.Status)
+	assert.Equal(t, dto.HealthCheckResult{Status: dto.HealthStatusError, Error: "unauthorized"}, response.Checks["telegram"])
+	assert.Equal(t, dto.HealthStatusOK, response.Checks["redis"].Status)
+}
+
+func TestCachedCheckReusesResultWithinInterval(t *testing.T) {
+	calls := 0
+	check := cachedCheck(func(ctx context.Context) error {
+		calls++
+		return errors.New("unreachable")
+	}, time.Hour)
+
+	require.Error(t, check(context.Background()))
+	require.Error(t, check(context.Background()))
+	assert.Equal(t, 1, calls)
+}
+
+func TestExecutorStateListsInFlightExecutions(t *testing.T) {
+	log, err := logger.New("error", "json")
+	require.NoError(t, err)
+	svc := &executorService{
+		cfg:                &config.Config{Executor: config.Executor{ConsumerName: "executor-consumer-pod-a-1"}},
+		executorStrategies: map[entity.JobType]strategy.JobExecutionStrategy{entity.JobTypeHTTP: strategy.NewHTTPStrategy(log)},
+		semaphore:          make(chan struct{}, 3),
+		running:            make(map[uint]*trackedExecution),
+	}
+	svc.semaphore <- struct{}{}
+	svc.registerRunning(&entity.TaskExecutionHistory{ID: 9, JobID: 2}, func(error) {})
+	svc.registerRunning(&entity.TaskExecutionHistory{ID: 4, JobID: 1}, func(error) {})
+
+	state := svc.State()
+
+	assert.Equal(t, "executor-consumer-pod-a-1", state.Consumer)
+	assert.Equal(t, []string{string(entity.JobTypeHTTP)}, state.Strategies)
+	assert.Equal(t, 1, state.SemaphoreInUse)
+	assert.Equal(t, 3, state.SemaphoreCapacity)
+	require.Len(t, state.InFlight, 2)
+	assert.Equal(t, uint(4), state.InFlight[0].HistoryID)
+	assert.Equal(t, uint(1), state.InFlight[0].JobID)
+	assert.Equal(t, uint(9), state.InFlight[1].HistoryID)
+}
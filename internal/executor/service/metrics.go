@@ -0,0 +1,28 @@
+package service
+
+import (
+	"golang-stock-scryper/internal/entity"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	executionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "executor_executions_total",
+		Help: "Executions run by this executor, by job type and final status.",
+	}, []string{"job_type", "status"})
+	executionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "executor_execution_duration_seconds",
+		Help: "Run time of executions, by job type and final status.",
+		// Jobs range from quick HTTP calls to scrapes that take many minutes.
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600},
+	}, []string{"job_type", "status"})
+)
+
+// recordExecution records a finished execution of job in the execution metrics.
+func recordExecution(job *entity.Job, history *entity.TaskExecutionHistory) {
+	jobType, status := string(job.Type), string(history.Status)
+	executionsTotal.WithLabelValues(jobType, status).Inc()
+	executionDuration.WithLabelValues(jobType, status).Observe(history.CompletedAt.Time.Sub(history.StartedAt).Seconds())
+}
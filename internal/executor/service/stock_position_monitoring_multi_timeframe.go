@@ -10,12 +10,15 @@ import (
 	"golang-stock-scryper/internal/executor/repository"
 	"golang-stock-scryper/pkg/common"
 	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/stream"
 	"golang-stock-scryper/pkg/telegram"
 	"golang-stock-scryper/pkg/utils"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -28,13 +31,13 @@ type StockPositionMonitoringMultiTimeframeService interface {
 type stockPositionMonitoringMultiTimeframeService struct {
 	cfg                         *config.Config
 	log                         *logger.Logger
-	redisClient                 *redis.Client
 	aiRepo                      repository.AIRepository
 	yahooFinance                repository.YahooFinanceRepository
 	stockPositionRepo           repository.StockPositionsRepository
 	stockNewsSummaryRepo        repository.StockNewsSummaryRepository
 	stockPositionMonitoringRepo repository.StockPositionsMonitoringsRepository
 	telegramBot                 telegram.Notifier
+	worker                      *stream.Worker[dto.StreamDataStockPositionMonitor]
 }
 
 func NewStockPositionMonitoringMultiTimeframeService(cfg *config.Config, log *logger.Logger,
@@ -45,10 +48,9 @@ func NewStockPositionMonitoringMultiTimeframeService(cfg *config.Config, log *lo
 	stockNewsSummaryRepo repository.StockNewsSummaryRepository,
 	stockPositionMonitoringRepo repository.StockPositionsMonitoringsRepository,
 	telegramBot telegram.Notifier) StockPositionMonitoringMultiTimeframeService {
-	return &stockPositionMonitoringMultiTimeframeService{
+	s := &stockPositionMonitoringMultiTimeframeService{
 		cfg:                         cfg,
 		log:                         log,
-		redisClient:                 redisClient,
 		aiRepo:                      aiRepo,
 		yahooFinance:                yahooFinance,
 		stockPositionRepo:           stockPositionRepo,
@@ -56,68 +58,56 @@ func NewStockPositionMonitoringMultiTimeframeService(cfg *config.Config, log *lo
 		stockPositionMonitoringRepo: stockPositionMonitoringRepo,
 		telegramBot:                 telegramBot,
 	}
+	s.worker = stream.NewWorker(redisClient, stream.Config{
+		Stream:       common.RedisStreamStockPositionMonitor,
+		Group:        common.RedisStreamGroup,
+		Consumer:     cfg.Executor.ConsumerName,
+		BatchSize:    cfg.Executor.RedisStreamStockPositionMonitorBatchSize,
+		RetryDelay:   cfg.Executor.RedisStreamStockPositionMonitorMaxIdleDuration,
+		MaxRetries:   cfg.Executor.RedisStreamStockPositionMonitorMaxRetry,
+		OnDeadLetter: s.alertDeadLetter,
+	}, s.handle, log)
+	return s
 }
 
+// ProcessTask reads and monitors new stock position monitor messages.
 func (s *stockPositionMonitoringMultiTimeframeService) ProcessTask(ctx context.Context) {
-	streams, err := s.redisClient.XReadGroup(ctx, &redis.XReadGroupArgs{
-		Group:    common.RedisStreamGroup,
-		Consumer: common.RedisStreamConsumer,
-		Streams:  []string{common.RedisStreamStockPositionMonitor, ">"}, // ">" means only new messages
-		Count:    1,
-		Block:    2 * time.Second, // Block for 2 seconds to allow graceful shutdown
-	}).Result()
-	if err != nil {
-
-		// Ignore context cancellation and timeout errors, as they are expected during shutdown or idle periods.
-		if err == context.Canceled || err == redis.Nil {
-			return
-		}
-		s.log.Error("Failed to read from stream", logger.ErrorField(err))
-
-		return
-	}
-
-	if len(streams) == 0 || len(streams[0].Messages) == 0 {
-		s.log.Debug("No messages found", logger.StringField("stream", common.RedisStreamStockPositionMonitor))
-		return
-	}
-
-	message := streams[0].Messages[0]
-
-	// The task data is expected to be a JSON string in the 'payload' field.
-	taskData, ok := message.Values["payload"].(string)
-	if !ok {
-		s.log.Error("field 'payload' not found or not a string in stream message", logger.Field("message_id", message.ID))
-		return
-	}
+	s.worker.Read(ctx)
+}
 
-	var streamData dto.StreamDataStockPositionMonitor
-	if err := json.Unmarshal([]byte(taskData), &streamData); err != nil {
-		s.log.Error("Failed to unmarshal task data", logger.ErrorField(err), logger.Field("message_id", message.ID))
-		return
-	}
+// ProcessRetries monitors failed stock position monitor messages again once their retry delay
+// has passed.
+func (s *stockPositionMonitoringMultiTimeframeService) ProcessRetries(ctx context.Context) {
+	s.worker.Retry(ctx)
+}
 
+func (s *stockPositionMonitoringMultiTimeframeService) handle(ctx context.Context, message stream.Message[dto.StreamDataStockPositionMonitor]) error {
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("stock.code", message.Payload.StockCode),
+		attribute.Int("stock_position.id", int(message.Payload.StockPositionID)),
+	)
 	loggerFields := []zap.Field{
-		logger.StringField("stock_code", streamData.StockCode),
+		logger.StringField("stock_code", message.Payload.StockCode),
 		logger.StringField("message_id", message.ID),
+		logger.Field("attempt", message.Attempt),
 	}
+	s.log.DebugContext(ctx, "Processing stock position monitor task", loggerFields...)
 
-	s.log.Debug("Processing stock position monitor task", loggerFields...)
-
-	if err := s.Execute(ctx, streamData); err != nil {
-		loggerFields = append(loggerFields, logger.ErrorField(err))
-		s.log.Error("Failed to execute stock position monitor task", loggerFields...)
-		return
-	}
-
-	if err := s.AckNDel(ctx, common.RedisStreamStockPositionMonitor, message.ID); err != nil {
-		loggerFields = append(loggerFields, logger.ErrorField(err))
-		s.log.Error("Failed to acknowledge and delete stock position monitor task", loggerFields...)
-		return
+	if err := s.Execute(ctx, message.Payload); err != nil {
+		return err
 	}
 
-	s.log.Debug("Stock position monitor task processed successfully", loggerFields...)
+	s.log.DebugContext(ctx, "Stock position monitor task processed successfully", loggerFields...)
+	return nil
+}
 
+// alertDeadLetter reports a stock position monitor message that exhausted its retries on Telegram.
+func (s *stockPositionMonitoringMultiTimeframeService) alertDeadLetter(ctx context.Context, letter stream.DeadLetter) {
+	errType := fmt.Sprintf("Retry count exceeded for event %s", common.RedisStreamStockPositionMonitor)
+	msgTelegram := telegram.FormatErrorAlertMessage(utils.TimeNowWIB(), errType, letter.Error, letter.Payload)
+	if err := s.telegramBot.SendMessage(ctx, msgTelegram); err != nil {
+		s.log.ErrorContext(ctx, "Failed to send telegram message retry exceeded ", logger.ErrorField(err), logger.StringField("message_id", letter.SourceID))
+	}
 }
 
 func (s *stockPositionMonitoringMultiTimeframeService) Execute(ctx context.Context, req dto.StreamDataStockPositionMonitor) error {
@@ -126,12 +116,12 @@ func (s *stockPositionMonitoringMultiTimeframeService) Execute(ctx context.Conte
 		IDs: []uint{req.StockPositionID},
 	})
 	if err != nil {
-		s.log.Error("Failed to get stock position", logger.ErrorField(err))
+		s.log.ErrorContext(ctx, "Failed to get stock position", logger.ErrorField(err))
 		return err
 	}
 
 	if len(stockPositions) == 0 {
-		s.log.Error("Stock position not found", logger.Field("id", req.StockPositionID))
+		s.log.ErrorContext(ctx, "Stock position not found", logger.Field("id", req.StockPositionID))
 		return fmt.Errorf("stock position not found")
 	}
 
@@ -143,19 +133,19 @@ func (s *stockPositionMonitoringMultiTimeframeService) Execute(ctx context.Conte
 		logger.IntField("id", int(stockPosition.ID)),
 	}
 	if !stockPosition.IsActive {
-		s.log.Warn("Stock position is not active", loggerFields...)
+		s.log.WarnContext(ctx, "Stock position is not active", loggerFields...)
 		return nil
 	}
 
 	lastSummary, err := s.stockNewsSummaryRepo.GetLast(ctx, time.Now().Add(-time.Hour*24), stockPosition.StockCode)
 	if err != nil {
-		s.log.Error("Failed to get last stock news summary", logger.ErrorField(err))
+		s.log.ErrorContext(ctx, "Failed to get last stock news summary", logger.ErrorField(err))
 		return err
 	}
 
 	stockDataMultiTimeframe, err := s.yahooFinance.GetMultiTimeframe(ctx, req.StockCode)
 	if err != nil {
-		s.log.Error("Failed to get stock data multi timeframe", logger.ErrorField(err))
+		s.log.ErrorContext(ctx, "Failed to get stock data multi timeframe", logger.ErrorField(err))
 		return err
 	}
 
@@ -169,13 +159,13 @@ func (s *stockPositionMonitoringMultiTimeframeService) Execute(ctx context.Conte
 	}, stockDataMultiTimeframe, lastSummary)
 
 	if err != nil {
-		s.log.Error("Failed to analyze stock", logger.ErrorField(err))
+		s.log.ErrorContext(ctx, "Failed to analyze stock", logger.ErrorField(err))
 		return err
 	}
 
 	dataJSON, err := json.Marshal(aiResp)
 	if err != nil {
-		s.log.Error("Failed to marshal gemini response", logger.ErrorField(err))
+		s.log.ErrorContext(ctx, "Failed to marshal gemini response", logger.ErrorField(err))
 		return err
 	}
 
@@ -194,7 +184,7 @@ func (s *stockPositionMonitoringMultiTimeframeService) Execute(ctx context.Conte
 	})
 
 	if err != nil {
-		s.log.Error("Failed to create stock signal", logger.ErrorField(err))
+		s.log.ErrorContext(ctx, "Failed to create stock signal", logger.ErrorField(err))
 		return err
 	}
 
@@ -203,135 +193,18 @@ func (s *stockPositionMonitoringMultiTimeframeService) Execute(ctx context.Conte
 			ParseMode: tgbotapi.ModeHTML,
 		}
 		msg := telegram.FormatPositionMonitoringMessage(aiResp)
-		if err := s.telegramBot.SendMessageUser(msg, int64(stockPosition.User.TelegramID), msgConfig); err != nil {
-			s.log.Error("Failed to send notification", logger.ErrorField(err))
+		if err := s.telegramBot.SendMessageUser(ctx, msg, int64(stockPosition.User.TelegramID), msgConfig); err != nil {
+			s.log.ErrorContext(ctx, "Failed to send notification", logger.ErrorField(err))
 			return nil
 		}
 
 		stockPosition.LastMonitorPositionAt = utils.ToPointer(utils.TimeNowWIB())
 		errSql := s.stockPositionRepo.Update(ctx, stockPosition)
 		if errSql != nil {
-			s.log.Error("Failed to update stock position", logger.ErrorField(errSql), logger.StringField("stock_code", stockPosition.StockCode))
-		}
-
-	}
-
-	return nil
-}
-
-func (s *stockPositionMonitoringMultiTimeframeService) ProcessRetries(ctx context.Context) {
-	msgs, _, err := s.redisClient.XAutoClaim(ctx, &redis.XAutoClaimArgs{
-		Stream:   common.RedisStreamStockPositionMonitor,
-		Group:    common.RedisStreamGroup,
-		Consumer: common.RedisStreamConsumer + "-retry",
-		MinIdle:  s.cfg.Executor.RedisStreamStockPositionMonitorMaxIdleDuration,
-		Start:    "0",
-		Count:    1,
-	}).Result()
-
-	if err != nil {
-		s.log.Error("Failed to claim stock position monitor task on retry", logger.ErrorField(err))
-		return
-	}
-
-	if len(msgs) == 0 {
-		s.log.Debug("Retry No pending messages found", logger.StringField("stream", common.RedisStreamStockPositionMonitor))
-		return
-	}
-
-	s.log.Info("Found pending messages", logger.StringField("stream", common.RedisStreamStockPositionMonitor))
-
-	pendingInfo, err := s.redisClient.XPendingExt(ctx, &redis.XPendingExtArgs{
-		Stream: common.RedisStreamStockPositionMonitor,
-		Group:  common.RedisStreamGroup,
-		Start:  msgs[0].ID,
-		End:    msgs[0].ID,
-		Count:  1,
-	}).Result()
-
-	if err != nil {
-		s.log.Error("Failed to get pending info", logger.ErrorField(err))
-		return
-	}
-
-	if len(pendingInfo) == 0 {
-		s.log.Warn("pending msg not found, but exist on xautoclaim",
-			logger.StringField("stream", common.RedisStreamStockAnalyzer),
-			logger.StringField("message_id", msgs[0].ID))
-		return
-	}
-
-	msg := msgs[0]
-	// The task data is expected to be a JSON string in the 'payload' field.
-	taskData, ok := msg.Values["payload"].(string)
-	if !ok {
-		s.log.Error("field 'payload' not found or not a string in stream message", logger.Field("message_id", msg.ID))
-		return
-	}
-
-	var streamData dto.StreamDataStockPositionMonitor
-	if err := json.Unmarshal([]byte(taskData), &streamData); err != nil {
-		s.log.Error("Failed to unmarshal task data", logger.ErrorField(err), logger.Field("message_id", msg.ID))
-		return
-	}
-
-	if err := s.Execute(ctx, dto.StreamDataStockPositionMonitor{
-		StockPositionID: streamData.StockPositionID,
-		StockCode:       streamData.StockCode,
-		SendToTelegram:  streamData.SendToTelegram,
-		UserID:          streamData.UserID,
-	}); err != nil {
-		s.log.Error("Failed to analyze stock", logger.ErrorField(err), logger.Field("message_id", msg.ID), logger.StringField("stock_code", streamData.StockCode))
-
-		if pendingInfo[0].RetryCount+1 >= int64(s.cfg.Executor.RedisStreamStockPositionMonitorMaxRetry) {
-			s.log.Error("pending msg retry count exceeded",
-				logger.StringField("stream", common.RedisStreamStockPositionMonitor),
-				logger.StringField("message_id", msg.ID),
-				logger.StringField("stock_code", streamData.StockCode),
-				logger.IntField("retry_count", int(pendingInfo[0].RetryCount+1)),
-				logger.IntField("max_retry", s.cfg.Executor.RedisStreamStockPositionMonitorMaxRetry),
-			)
-			errType := fmt.Sprintf("Retry count exceeded for event %s", common.RedisStreamStockPositionMonitor)
-			data := fmt.Sprintf("%s", streamData.StockCode)
-			msgTelegram := telegram.FormatErrorAlertMessage(utils.TimeNowWIB(), errType, err.Error(), data)
-			if err := s.telegramBot.SendMessage(msgTelegram); err != nil {
-				s.log.Error("Failed to send telegram message retry exceeded ", logger.ErrorField(err), logger.StringField("stock_code", streamData.StockCode))
-			}
-			if err := s.AckNDel(ctx, common.RedisStreamStockPositionMonitor, msg.ID); err != nil {
-				s.log.Error("Failed to acknowledge and delete stock position monitor task", logger.ErrorField(err), logger.Field("message_id", msg.ID))
-				return
-			}
-			return
+			s.log.ErrorContext(ctx, "Failed to update stock position", logger.ErrorField(errSql), logger.StringField("stock_code", stockPosition.StockCode))
 		}
-		return
-	}
 
-	if err := s.AckNDel(ctx, common.RedisStreamStockPositionMonitor, msg.ID); err != nil {
-		s.log.Error("Failed to acknowledge and delete stock position monitor task", logger.ErrorField(err), logger.Field("message_id", msg.ID))
-		return
 	}
-	s.log.Info("Retry Stock position monitor task processed successfully", logger.StringField("stock_code", streamData.StockCode))
-
-}
 
-func (s *stockPositionMonitoringMultiTimeframeService) AckNDel(ctx context.Context, streamName string, messageID string) error {
-	if err := s.redisClient.XAck(ctx, streamName, common.RedisStreamGroup, messageID).Err(); err != nil {
-		loggerFields := []zap.Field{
-			logger.StringField("stream_name", streamName),
-			logger.StringField("message_id", messageID),
-			logger.ErrorField(err),
-		}
-		s.log.Error("Failed to acknowledge stock position monitor task on retry", loggerFields...)
-		return err
-	}
-	if err := s.redisClient.XDel(ctx, streamName, messageID).Err(); err != nil {
-		loggerFields := []zap.Field{
-			logger.StringField("stream_name", streamName),
-			logger.StringField("message_id", messageID),
-			logger.ErrorField(err),
-		}
-		s.log.Error("Failed to delete stock position monitor task on retry", loggerFields...)
-		return err
-	}
 	return nil
 }
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/executor/config"
+	"golang-stock-scryper/internal/executor/repository"
+	"golang-stock-scryper/pkg/logger"
+)
+
+const (
+	defaultWebhookBatchSize      = 50
+	defaultWebhookRequestTimeout = 10 * time.Second
+
+	// maxWebhookErrorLength caps the response body or error recorded for a failed attempt.
+	maxWebhookErrorLength = 1000
+)
+
+// Headers sent with every webhook request.
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookDispatcher sends pending webhook deliveries to their subscriptions.
+type WebhookDispatcher interface {
+	Dispatch(ctx context.Context)
+}
+
+// NewWebhookDispatcher creates a new WebhookDispatcher. Unset settings use their defaults.
+func NewWebhookDispatcher(cfg config.Webhook, webhookRepo repository.WebhookRepository, log *logger.Logger) WebhookDispatcher {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultWebhookBatchSize
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = defaultWebhookRequestTimeout
+	}
+	return &webhookDispatcher{
+		cfg:         cfg,
+		webhookRepo: webhookRepo,
+		httpClient:  &http.Client{Timeout: cfg.RequestTimeout},
+		logger:      log,
+		now:         time.Now,
+	}
+}
+
+type webhookDispatcher struct {
+	cfg         config.Webhook
+	webhookRepo repository.WebhookRepository
+	httpClient  *http.Client
+	logger      *logger.Logger
+	now         func() time.Time
+}
+
+// Dispatch claims the deliveries that are due and sends them one by one. Each claimed delivery
+// is leased for longer than sending the whole batch can take, so another executor instance only
+// picks it up again if this one dies before recording the outcome. Deliveries left unsent when
+// ctx ends are picked up again once their lease expires.
+func (d *webhookDispatcher) Dispatch(ctx context.Context) {
+	lease := time.Duration(d.cfg.BatchSize+1) * d.cfg.RequestTimeout
+	deliveries, err := d.webhookRepo.ClaimDueDeliveries(ctx, d.now(), d.cfg.BatchSize, lease)
+	if err != nil {
+		d.logger.Error("Failed to claim webhook deliveries", logger.ErrorField(err))
+		return
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		delivery := &deliveries[i]
+		d.deliver(ctx, delivery)
+
+		// The outcome is recorded even when the dispatch context has ended.
+		updateCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := d.webhookRepo.UpdateDelivery(updateCtx, delivery); err != nil {
+			d.logger.Error("Failed to record webhook delivery", logger.ErrorField(err), logger.Field("delivery_id", delivery.ID))
+		}
+		cancel()
+	}
+}
+
+// deliver makes one attempt to send a delivery and updates it with the outcome, scheduling the
+// next attempt as the subscription's retry policy allows.
+func (d *webhookDispatcher) deliver(ctx context.Context, delivery *entity.WebhookDelivery) {
+	now := d.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = sql.NullTime{Time: now, Valid: true}
+
+	subscription := delivery.Subscription
+	if subscription == nil || !subscription.IsActive {
+		delivery.Status = entity.WebhookDeliveryFailed
+		delivery.LastError = sql.NullString{String: "subscription is inactive", Valid: true}
+		return
+	}
+
+	statusCode, err := d.send(ctx, subscription, delivery, now)
+	if statusCode > 0 {
+		delivery.ResponseStatus = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+	}
+	if err == nil {
+		delivery.Status = entity.WebhookDeliverySucceeded
+		delivery.DeliveredAt = sql.NullTime{Time: d.now(), Valid: true}
+		delivery.LastError = sql.NullString{}
+		d.logger.Debug("Webhook delivered", logger.Field("delivery_id", delivery.ID), logger.StringField("event_id", delivery.EventID))
+		return
+	}
+
+	delivery.LastError = sql.NullString{String: truncate(err.Error(), maxWebhookErrorLength), Valid: true}
+	policy := subscription.GetRetryPolicy()
+	if policy.ShouldRetry(delivery.Attempts) {
+		delivery.NextAttemptAt = now.Add(policy.Backoff(delivery.Attempts))
+	} else {
+		delivery.Status = entity.WebhookDeliveryFailed
+	}
+	d.logger.Warn("Webhook delivery failed",
+		logger.ErrorField(err),
+		logger.Field("delivery_id", delivery.ID),
+		logger.Field("subscription_id", subscription.ID),
+		logger.IntField("attempt", delivery.Attempts),
+		logger.StringField("status", string(delivery.Status)))
+}
+
+// send POSTs the delivery's payload to the subscription URL. It returns the response status,
+// if any, and an error unless the status is 2xx.
+func (d *webhookDispatcher) send(ctx context.Context, subscription *entity.WebhookSubscription, delivery *entity.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookIDHeader, delivery.EventID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(subscription.Secret, timestamp, body))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorLength))
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d: %s", resp.StatusCode, respBody)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// SignWebhook returns the signature header value of a webhook request: the hex-encoded
+// HMAC-SHA256, keyed with the subscription secret, of the timestamp, a dot and the body.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
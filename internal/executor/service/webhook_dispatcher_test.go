@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/executor/config"
+	"golang-stock-scryper/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+type fakeWebhookRepository struct {
+	due     []entity.WebhookDelivery
+	updated []entity.WebhookDelivery
+}
+
+func (r *fakeWebhookRepository) EnqueueExecutionEvent(ctx context.Context, event entity.ExecutionEvent) error {
+	return nil
+}
+
+func (r *fakeWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	due := r.due
+	r.due = nil
+	return due, nil
+}
+
+func (r *fakeWebhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	r.updated = append(r.updated, *delivery)
+	return nil
+}
+
+func newTestWebhookDispatcher(t *testing.T, repo *fakeWebhookRepository, now time.Time) *webhookDispatcher {
+	log, err := logger.New("error", "json")
+	require.NoError(t, err)
+	d := NewWebhookDispatcher(config.Webhook{}, repo, log).(*webhookDispatcher)
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func newTestDelivery(url string, attempts int, retryPolicy string) entity.WebhookDelivery {
+	return entity.WebhookDelivery{
+		ID:             1,
+		SubscriptionID: 1,
+		Subscription: &entity.WebhookSubscription{
+			ID:          1,
+			URL:         url,
+			Secret:      "whsec_test",
+			RetryPolicy: datatypes.JSON(retryPolicy),
+			IsActive:    true,
+		},
+		EventID:   "execution-7-completed",
+		EventType: "execution.completed",
+		Payload:   datatypes.JSON(`{"id":"execution-7-completed"}`),
+		Status:    entity.WebhookDeliveryPending,
+		Attempts:  attempts,
+	}
+}
+
+func TestWebhookDispatcherSignsAndDelivers(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	var got *http.Request
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := &fakeWebhookRepository{due: []entity.WebhookDelivery{newTestDelivery(server.URL, 0, "")}}
+	newTestWebhookDispatcher(t, repo, now).Dispatch(context.Background())
+
+	require.NotNil(t, got)
+	timestamp := "1709283600"
+	assert.Equal(t, `{"id":"execution-7-completed"}`, string(gotBody))
+	assert.Equal(t, "execution.completed", got.Header.Get(WebhookEventHeader))
+	assert.Equal(t, "execution-7-completed", got.Header.Get(WebhookIDHeader))
+	assert.Equal(t, timestamp, got.Header.Get(WebhookTimestampHeader))
+	assert.Equal(t, SignWebhook("whsec_test", timestamp, gotBody), got.Header.Get(WebhookSignatureHeader))
+
+	require.Len(t, repo.updated, 1)
+	delivery := repo.updated[0]
+	assert.Equal(t, entity.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, int32(http.StatusNoContent), delivery.ResponseStatus.Int32)
+	assert.True(t, delivery.DeliveredAt.Valid)
+	assert.False(t, delivery.LastError.Valid)
+}
+
+func TestWebhookDispatcherRetriesFailedDeliveries(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	policy := `{"max_retries":2,"backoff_strategy":"exponential","initial_interval":"10s"}`
+
+	t.Run("schedules the next attempt while retries remain", func(t *testing.T) {
+		repo := &fakeWebhookRepository{due: []entity.WebhookDelivery{newTestDelivery(server.URL, 1, policy)}}
+		newTestWebhookDispatcher(t, repo, now).Dispatch(context.Background())
+
+		require.Len(t, repo.updated, 1)
+		delivery := repo.updated[0]
+		assert.Equal(t, entity.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, now.Add(20*time.Second), delivery.NextAttemptAt)
+		assert.Equal(t, int32(http.StatusServiceUnavailable), delivery.ResponseStatus.Int32)
+		assert.Contains(t, delivery.LastError.String, "unavailable")
+	})
+
+	t.Run("fails once retries are exhausted", func(t *testing.T) {
+		repo := &fakeWebhookRepository{due: []entity.WebhookDelivery{newTestDelivery(server.URL, 2, policy)}}
+		newTestWebhookDispatcher(t, repo, now).Dispatch(context.Background())
+
+		require.Len(t, repo.updated, 1)
+		assert.Equal(t, entity.WebhookDeliveryFailed, repo.updated[0].Status)
+		assert.Equal(t, 3, repo.updated[0].Attempts)
+	})
+
+	t.Run("fails deliveries of inactive subscriptions without sending", func(t *testing.T) {
+		delivery := newTestDelivery("http://127.0.0.1:0", 0, policy)
+		delivery.Subscription.IsActive = false
+		repo := &fakeWebhookRepository{due: []entity.WebhookDelivery{delivery}}
+		newTestWebhookDispatcher(t, repo, now).Dispatch(context.Background())
+
+		require.Len(t, repo.updated, 1)
+		assert.Equal(t, entity.WebhookDeliveryFailed, repo.updated[0].Status)
+		assert.Equal(t, "subscription is inactive", repo.updated[0].LastError.String)
+	})
+}
@@ -0,0 +1,302 @@
+package strategy
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/executor/config"
+	"golang-stock-scryper/internal/executor/dto"
+	"golang-stock-scryper/internal/executor/repository"
+	"golang-stock-scryper/pkg/logger"
+)
+
+const defaultRetentionBatchSize = 1000
+
+// retentionTable describes a table the data retention job may act on. Only tables listed
+// in retentionTables can be targeted, so the job payload never reaches arbitrary SQL.
+type retentionTable struct {
+	timeColumn  string
+	condition   string      // rows matching this are never expired
+	clearColumn string      // bulky column emptied by entity.RetentionActionClear
+	clearValue  interface{} // value the bulky column is replaced with
+	uncleared   string      // matches rows whose bulky column still holds data
+}
+
+var retentionTables = map[string]retentionTable{
+	"task_execution_history": {
+		timeColumn:  "started_at",
+		condition:   "status NOT IN ('queued', 'running')",
+		clearColumn: "output",
+		uncleared:   "output IS NOT NULL",
+	},
+	"stock_news": {
+		timeColumn:  "created_at",
+		clearColumn: "raw_content",
+		uncleared:   "raw_content IS NOT NULL",
+	},
+	"stock_signals": {
+		timeColumn:  "created_at",
+		clearColumn: "data",
+		clearValue:  "{}",
+		uncleared:   "data <> '{}'::jsonb",
+	},
+	"stock_position_monitorings": {
+		timeColumn:  "created_at",
+		clearColumn: "data",
+		clearValue:  "{}",
+		uncleared:   "data <> '{}'::jsonb",
+	},
+}
+
+// DataRetentionStrategy deletes, archives or trims old rows of tables that grow without bound.
+type DataRetentionStrategy struct {
+	cfg           *config.Config
+	logger        *logger.Logger
+	retentionRepo repository.RetentionRepository
+}
+
+// NewDataRetentionStrategy creates a new instance of DataRetentionStrategy.
+func NewDataRetentionStrategy(cfg *config.Config, logger *logger.Logger, retentionRepo repository.RetentionRepository) *DataRetentionStrategy {
+	return &DataRetentionStrategy{
+		cfg:           cfg,
+		logger:        logger,
+		retentionRepo: retentionRepo,
+	}
+}
+
+// GetType returns the job type this strategy handles.
+func (s *DataRetentionStrategy) GetType() entity.JobType {
+	return entity.JobTypeDataRetention
+}
+
+// Execute applies every retention policy of the job in turn. A failing policy does not stop
+// the others; the output reports the number of rows processed per table.
+func (s *DataRetentionStrategy) Execute(ctx context.Context, job *entity.Job) (string, error) {
+	var payload entity.DataRetentionPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return "", fmt.Errorf("failed to unmarshal job payload: %w", err)
+	}
+	if err := validateRetentionPayload(&payload); err != nil {
+		return "", err
+	}
+
+	batchSize := payload.BatchSize
+	if batchSize <= 0 {
+		batchSize = s.cfg.Retention.DefaultBatchSize
+	}
+	if batchSize <= 0 {
+		batchSize = defaultRetentionBatchSize
+	}
+
+	var (
+		results []dto.ExecutorRetentionResult
+		failed  int
+	)
+	for _, policy := range payload.Policies {
+		result := s.applyPolicy(ctx, policy, batchSize, time.Now())
+		if result.Error != "" {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	resultJSON, err := json.Marshal(results)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal results: %w", err)
+	}
+	if failed > 0 {
+		return string(resultJSON), fmt.Errorf("%d of %d retention policies failed", failed, len(payload.Policies))
+	}
+	return string(resultJSON), nil
+}
+
+// validateRetentionPayload checks that every policy targets a supported table with a known action.
+func validateRetentionPayload(p *entity.DataRetentionPayload) error {
+	if len(p.Policies) == 0 {
+		return fmt.Errorf("data retention payload has no policies")
+	}
+	for _, policy := range p.Policies {
+		if _, ok := retentionTables[policy.Table]; !ok {
+			return fmt.Errorf("unsupported retention table: %s", policy.Table)
+		}
+		if policy.RetentionDays <= 0 {
+			return fmt.Errorf("retention_days for %s must be positive", policy.Table)
+		}
+		switch policy.Action {
+		case entity.RetentionActionDelete, entity.RetentionActionArchive, entity.RetentionActionClear:
+		default:
+			return fmt.Errorf("unsupported retention action for %s: %s", policy.Table, policy.Action)
+		}
+	}
+	return nil
+}
+
+// applyPolicy processes the expired rows of a single table in batches until none are left.
+func (s *DataRetentionStrategy) applyPolicy(ctx context.Context, policy entity.RetentionPolicy, batchSize int, now time.Time) dto.ExecutorRetentionResult {
+	table := retentionTables[policy.Table]
+	cutoff := now.AddDate(0, 0, -policy.RetentionDays)
+	result := dto.ExecutorRetentionResult{
+		Table:         policy.Table,
+		Action:        policy.Action,
+		RetentionDays: policy.RetentionDays,
+		Cutoff:        cutoff,
+	}
+
+	target := repository.RetentionTarget{
+		Table:      policy.Table,
+		TimeColumn: table.timeColumn,
+		Condition:  table.condition,
+	}
+	if policy.Action == entity.RetentionActionClear {
+		target.Condition = joinConditions(table.condition, table.uncleared)
+	}
+
+	var archive *retentionArchive
+	if policy.Action == entity.RetentionActionArchive {
+		archive = newRetentionArchive(s.cfg.Retention.ArchiveDir, policy.Table, now)
+	}
+
+	for ctx.Err() == nil {
+		var columns []string
+		if archive == nil {
+			columns = []string{"id"}
+		}
+		rows, err := s.retentionRepo.FindExpired(ctx, target, cutoff, batchSize, columns...)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to find expired rows: %v", err)
+			break
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		ids := make([]interface{}, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row["id"])
+		}
+
+		var affected int64
+		switch policy.Action {
+		case entity.RetentionActionClear:
+			affected, err = s.retentionRepo.ClearColumn(ctx, policy.Table, table.clearColumn, table.clearValue, ids)
+		case entity.RetentionActionArchive:
+			// Rows are only deleted once they are safely written to the archive.
+			if err = archive.Write(rows); err == nil {
+				affected, err = s.retentionRepo.DeleteByIDs(ctx, policy.Table, ids)
+			}
+		default:
+			affected, err = s.retentionRepo.DeleteByIDs(ctx, policy.Table, ids)
+		}
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to %s expired rows: %v", policy.Action, err)
+			break
+		}
+
+		result.Rows += affected
+		result.Batches++
+		if len(rows) < batchSize {
+			break
+		}
+	}
+	if result.Error == "" && ctx.Err() != nil {
+		result.Error = ctx.Err().Error()
+	}
+	if archive != nil {
+		if err := archive.Close(); err != nil && result.Error == "" {
+			result.Error = err.Error()
+		}
+		result.ArchiveFile = archive.Path()
+	}
+
+	if result.Error != "" {
+		s.logger.Error("Data retention policy failed", logger.StringField("table", policy.Table), logger.StringField("error", result.Error), logger.Field("rows", result.Rows))
+	} else {
+		s.logger.Info("Data retention policy applied", logger.StringField("table", policy.Table), logger.StringField("action", policy.Action), logger.Field("rows", result.Rows))
+	}
+	return result
+}
+
+func joinConditions(conditions ...string) string {
+	joined := ""
+	for _, condition := range conditions {
+		if condition == "" {
+			continue
+		}
+		if joined != "" {
+			joined += " AND "
+		}
+		joined += "(" + condition + ")"
+	}
+	return joined
+}
+
+// retentionArchive writes archived rows as gzip-compressed JSON lines. The file is created
+// on the first write, so runs without expired rows leave no empty archives behind.
+type retentionArchive struct {
+	path   string
+	file   *os.File
+	gzip   *gzip.Writer
+	buffer *bufio.Writer
+}
+
+func newRetentionArchive(dir, table string, now time.Time) *retentionArchive {
+	name := fmt.Sprintf("%s_%s.jsonl.gz", table, now.UTC().Format("20060102T150405Z"))
+	return &retentionArchive{path: filepath.Join(dir, table, name)}
+}
+
+// Path returns the archive file path, or an empty string when nothing was written.
+func (a *retentionArchive) Path() string {
+	if a.file == nil {
+		return ""
+	}
+	return a.path
+}
+
+// Write appends rows to the archive and flushes them to disk.
+func (a *retentionArchive) Write(rows []map[string]interface{}) error {
+	if a.file == nil {
+		if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+			return fmt.Errorf("failed to create archive directory: %w", err)
+		}
+		file, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to create archive file: %w", err)
+		}
+		a.file = file
+		a.gzip = gzip.NewWriter(file)
+		a.buffer = bufio.NewWriter(a.gzip)
+	}
+
+	encoder := json.NewEncoder(a.buffer)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return fmt.Errorf("failed to encode archived row: %w", err)
+		}
+	}
+	if err := a.buffer.Flush(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := a.gzip.Flush(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return a.file.Sync()
+}
+
+// Close finishes the compressed stream and closes the file.
+func (a *retentionArchive) Close() error {
+	if a.file == nil {
+		return nil
+	}
+	if err := a.gzip.Close(); err != nil {
+		a.file.Close()
+		return fmt.Errorf("failed to close archive: %w", err)
+	}
+	return a.file.Close()
+}
@@ -0,0 +1,159 @@
+package strategy
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/executor/config"
+	"golang-stock-scryper/internal/executor/dto"
+	"golang-stock-scryper/internal/executor/repository"
+	"golang-stock-scryper/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRetentionRepository serves expired rows from memory, oldest IDs first.
+type fakeRetentionRepository struct {
+	rows    map[string][]map[string]interface{}
+	targets []repository.RetentionTarget
+	cleared []interface{}
+}
+
+func (r *fakeRetentionRepository) FindExpired(ctx context.Context, target repository.RetentionTarget, cutoff time.Time, limit int, columns ...string) ([]map[string]interface{}, error) {
+	r.targets = append(r.targets, target)
+	rows := r.rows[target.Table]
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+func (r *fakeRetentionRepository) DeleteByIDs(ctx context.Context, table string, ids []interface{}) (int64, error) {
+	r.rows[table] = r.rows[table][len(ids):]
+	return int64(len(ids)), nil
+}
+
+func (r *fakeRetentionRepository) ClearColumn(ctx context.Context, table, column string, value interface{}, ids []interface{}) (int64, error) {
+	r.cleared = append(r.cleared, ids...)
+	r.rows[table] = r.rows[table][len(ids):]
+	return int64(len(ids)), nil
+}
+
+func newTestRetentionStrategy(t *testing.T, repo repository.RetentionRepository) *DataRetentionStrategy {
+	log, err := logger.New("error", "json")
+	require.NoError(t, err)
+	cfg := &config.Config{Retention: config.Retention{ArchiveDir: t.TempDir(), DefaultBatchSize: 2}}
+	return NewDataRetentionStrategy(cfg, log, repo)
+}
+
+func retentionJob(t *testing.T, payload entity.DataRetentionPayload) *entity.Job {
+	raw, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return &entity.Job{ID: 1, Type: entity.JobTypeDataRetention, Payload: raw}
+}
+
+func TestDataRetentionStrategy_Archive(t *testing.T) {
+	repo := &fakeRetentionRepository{rows: map[string][]map[string]interface{}{
+		"stock_news": {
+			{"id": 1, "raw_content": "a"},
+			{"id": 2, "raw_content": "b"},
+			{"id": 3, "raw_content": "c"},
+		},
+	}}
+	s := newTestRetentionStrategy(t, repo)
+
+	output, err := s.Execute(context.Background(), retentionJob(t, entity.DataRetentionPayload{
+		Policies: []entity.RetentionPolicy{{Table: "stock_news", RetentionDays: 30, Action: entity.RetentionActionArchive}},
+	}))
+	require.NoError(t, err)
+
+	var results []dto.ExecutorRetentionResult
+	require.NoError(t, json.Unmarshal([]byte(output), &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, int64(3), results[0].Rows)
+	assert.Equal(t, 2, results[0].Batches)
+	assert.Empty(t, repo.rows["stock_news"])
+
+	file, err := os.Open(results[0].ArchiveFile)
+	require.NoError(t, err)
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	var archived []map[string]interface{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var row map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+		archived = append(archived, row)
+	}
+	require.NoError(t, scanner.Err())
+	require.Len(t, archived, 3)
+	assert.Equal(t, "c", archived[2]["raw_content"])
+}
+
+func TestDataRetentionStrategy_Clear(t *testing.T) {
+	repo := &fakeRetentionRepository{rows: map[string][]map[string]interface{}{
+		"task_execution_history": {{"id": 7}},
+	}}
+	s := newTestRetentionStrategy(t, repo)
+
+	_, err := s.Execute(context.Background(), retentionJob(t, entity.DataRetentionPayload{
+		Policies: []entity.RetentionPolicy{{Table: "task_execution_history", RetentionDays: 7, Action: entity.RetentionActionClear}},
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{7}, repo.cleared)
+	assert.Equal(t, "(status NOT IN ('queued', 'running')) AND (output IS NOT NULL)", repo.targets[0].Condition)
+}
+
+func TestValidateRetentionPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload entity.DataRetentionPayload
+		wantErr bool
+	}{
+		{
+			name:    "valid",
+			payload: entity.DataRetentionPayload{Policies: []entity.RetentionPolicy{{Table: "stock_signals", RetentionDays: 90, Action: entity.RetentionActionDelete}}},
+		},
+		{
+			name:    "no policies",
+			payload: entity.DataRetentionPayload{},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported table",
+			payload: entity.DataRetentionPayload{Policies: []entity.RetentionPolicy{{Table: "users", RetentionDays: 90, Action: entity.RetentionActionDelete}}},
+			wantErr: true,
+		},
+		{
+			name:    "non-positive retention",
+			payload: entity.DataRetentionPayload{Policies: []entity.RetentionPolicy{{Table: "stock_news", Action: entity.RetentionActionDelete}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown action",
+			payload: entity.DataRetentionPayload{Policies: []entity.RetentionPolicy{{Table: "stock_news", RetentionDays: 30, Action: "truncate"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRetentionPayload(&tt.payload)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
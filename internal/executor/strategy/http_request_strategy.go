@@ -12,14 +12,6 @@ import (
 	"golang-stock-scryper/pkg/logger"
 )
 
-// HTTPJobDetails defines the structure for HTTP job payloads.
-type HTTPJobDetails struct {
-	URL     string            `json:"url"`
-	Method  string            `json:"method"`
-	Headers map[string]string `json:"headers"`
-	Body    json.RawMessage   `json:"body"`
-}
-
 // HTTPStrategy executes HTTP-based jobs.
 type HTTPStrategy struct {
 	logger *logger.Logger
@@ -37,7 +29,7 @@ func (s *HTTPStrategy) GetType() entity.JobType {
 
 // Execute performs the HTTP request defined in the job's payload.
 func (s *HTTPStrategy) Execute(ctx context.Context, job *entity.Job) (string, error) {
-	var details HTTPJobDetails
+	var details entity.HTTPJobDetails
 	if err := json.Unmarshal(job.Payload, &details); err != nil {
 		s.logger.Error("Failed to unmarshal job payload", logger.ErrorField(err), logger.Field("job_id", job.ID))
 		return "", fmt.Errorf("failed to unmarshal job payload: %w", err)
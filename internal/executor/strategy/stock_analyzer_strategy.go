@@ -11,8 +11,10 @@ import (
 	"golang-stock-scryper/pkg/common"
 	"golang-stock-scryper/pkg/logger"
 	"golang-stock-scryper/pkg/redis"
+	"golang-stock-scryper/pkg/tracing"
 
 	goRedis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // StockAnalyzerStrategy defines the strategy for analyzing stock news.
@@ -23,14 +25,6 @@ type StockAnalyzerStrategy struct {
 	tradingViewRepo repository.TradingViewRepository
 }
 
-type StockAnalyzerPayload struct {
-	SkipStocks       []string               `json:"skip_stocks"`
-	UseTradingView   bool                   `json:"use_trading_view"`
-	UseStockList     bool                   `json:"use_stock_list"`
-	AdditionalStocks []string               `json:"additional_stocks"`
-	TradingViewData  map[string]interface{} `json:"trading_view_data"`
-}
-
 type StockAnalyzerResult struct {
 	StockCode string `json:"stock_code"`
 	Success   bool   `json:"success"`
@@ -49,7 +43,7 @@ func (s *StockAnalyzerStrategy) GetType() entity.JobType {
 
 // Execute performs the stock news analysis defined in the job's payload.
 func (s *StockAnalyzerStrategy) Execute(ctx context.Context, job *entity.Job) (string, error) {
-	var payload StockAnalyzerPayload
+	var payload entity.StockAnalyzerPayload
 	if err := json.Unmarshal(job.Payload, &payload); err != nil {
 		s.logger.Error("Failed to unmarshal job payload", logger.ErrorField(err), logger.Field("job_id", job.ID))
 		return "", fmt.Errorf("failed to unmarshal job payload: %w", err)
@@ -101,26 +95,7 @@ func (s *StockAnalyzerStrategy) Execute(ctx context.Context, job *entity.Job) (s
 			continue
 		}
 
-		streamData := &dto.StreamDataStockAnalyzer{
-			StockCode: code,
-		}
-
-		streamDataJSON, err := json.Marshal(streamData)
-		if err != nil {
-			s.logger.Error("Failed to marshal stock analyzer payload", logger.ErrorField(err))
-			results = append(results, StockAnalyzerResult{
-				StockCode: code,
-				Success:   false,
-				Error:     err.Error(),
-			})
-			continue
-		}
-
-		if err := s.redisClient.XAdd(ctx, &goRedis.XAddArgs{
-			Stream: common.RedisStreamStockAnalyzer,
-			Values: map[string]interface{}{"payload": streamDataJSON},
-		}).Err(); err != nil {
-			s.logger.Error("Failed to enqueue stock analyzer task", logger.ErrorField(err), logger.Field("stock_code", code))
+		if err := s.enqueue(ctx, code); err != nil {
 			results = append(results, StockAnalyzerResult{
 				StockCode: code,
 				Success:   false,
@@ -147,3 +122,30 @@ func (s *StockAnalyzerStrategy) Execute(ctx context.Context, job *entity.Job) (s
 
 	return "", fmt.Errorf("failed to enqueue stock analyzer task")
 }
+
+// enqueue adds the analysis of one stock to the stock analyzer stream, carrying the trace of the
+// job execution.
+func (s *StockAnalyzerStrategy) enqueue(ctx context.Context, code string) error {
+	ctx, span := tracing.StartPublish(ctx, common.RedisStreamStockAnalyzer, attribute.String("stock.code", code))
+	defer span.End()
+
+	streamDataJSON, err := json.Marshal(&dto.StreamDataStockAnalyzer{
+		StockCode:    code,
+		TraceContext: tracing.Inject(ctx),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to marshal stock analyzer payload", logger.ErrorField(err))
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	if err := s.redisClient.XAdd(ctx, &goRedis.XAddArgs{
+		Stream: common.RedisStreamStockAnalyzer,
+		Values: map[string]interface{}{"payload": streamDataJSON},
+	}).Err(); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to enqueue stock analyzer task", logger.ErrorField(err), logger.Field("stock_code", code))
+		tracing.RecordError(span, err)
+		return err
+	}
+	return nil
+}
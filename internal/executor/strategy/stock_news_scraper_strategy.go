@@ -19,6 +19,8 @@ import (
 	"golang-stock-scryper/internal/executor/repository"
 	"golang-stock-scryper/pkg/decoder"
 	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/metrics"
+	"golang-stock-scryper/pkg/tracing"
 	"golang-stock-scryper/pkg/utils"
 	"net/http"
 	"net/url"
@@ -37,7 +39,8 @@ type StockNewsScraperStrategy struct {
 	aiRepo            repository.AIRepository
 	stockMentionRepo  repository.StockMentionRepository
 	stockNewsRepo     repository.StockNewsRepository
-	client            *http.Client
+	client            *http.Client // fetches news articles
+	rssClient         *http.Client // fetches Google News RSS feeds
 	inmemoryCache     *cache.Cache
 	stockRepo         repository.StocksRepository
 	stockPositionRepo repository.StockPositionsRepository
@@ -61,7 +64,8 @@ func NewStockNewsScraperStrategy(
 		aiRepo:            aiRepo,
 		stockMentionRepo:  stockMentionRepo,
 		stockNewsRepo:     stockNewsRepo,
-		client:            &http.Client{},
+		client:            &http.Client{Transport: metrics.NewTransport(metrics.ProviderNewsSite, tracing.NewTransport(metrics.ProviderNewsSite, nil))},
+		rssClient:         &http.Client{Transport: metrics.NewTransport(metrics.ProviderGoogleNews, tracing.NewTransport(metrics.ProviderGoogleNews, nil))},
 		inmemoryCache:     cache.New(5*time.Minute, 10*time.Minute),
 		stockRepo:         stockRepo,
 		stockPositionRepo: stockPositionRepo,
@@ -81,22 +85,8 @@ type scrapeResult struct {
 	QueryRSS    string   `json:"query_rss"`
 }
 
-type StockNewsScraperPayload struct {
-	AdditionalStockCodes []string       `json:"additional_stock_codes"`
-	DelayInterval        int            `json:"delay_interval"`
-	MaxNews              int            `json:"max_news"`
-	MaxNewsAgeInDays     int            `json:"max_news_age_in_days"`
-	BlackListedDomains   []string       `json:"blacklisted_domains"`
-	MaxConcurrent        int            `json:"max_concurrent"`
-	AdditionalKeywords   []string       `json:"additional_keywords"`
-	UseStockList         bool           `json:"use_stock_list"`
-	DefaultQueryParam    string         `json:"default_query_param"`
-	SourcePriority       map[string]int `json:"source_priority"`
-	UseStockPosition     bool           `json:"use_stock_position"`
-}
-
 func (s *StockNewsScraperStrategy) Execute(ctx context.Context, job *entity.Job) (string, error) {
-	var payload StockNewsScraperPayload
+	var payload entity.StockNewsScraperPayload
 	if err := json.Unmarshal(job.Payload, &payload); err != nil {
 		return "", fmt.Errorf("failed to unmarshal job payload: %w", err)
 	}
@@ -322,7 +312,7 @@ func (s *StockNewsScraperStrategy) filterExistingNewsItems(ctx context.Context,
 	return filteredItems, nil
 }
 
-func (s *StockNewsScraperStrategy) processNewsItem(ctx context.Context, item *dto.RSSItem, queryRSS string, payload StockNewsScraperPayload) (string, entity.StockNews, error) {
+func (s *StockNewsScraperStrategy) processNewsItem(ctx context.Context, item *dto.RSSItem, queryRSS string, payload entity.StockNewsScraperPayload) (string, entity.StockNews, error) {
 	decodeResult := s.decoder.DecodeGoogleNewsURL(item.Link, 0)
 	if !decodeResult.Status {
 		s.logger.Error("Failed to decode google rss link", logger.StringField("message", decodeResult.Message))
@@ -470,7 +460,7 @@ func (s *StockNewsScraperStrategy) parseRSSFeed(ctx context.Context, url string)
 	req.Header.Set("Connection", "keep-alive")
 	req.Header.Set("Upgrade-Insecure-Requests", "1")
 
-	resp, err := s.client.Do(req)
+	resp, err := s.rssClient.Do(req)
 	if err != nil {
 		s.logger.Error("Failed to fetch parse RSS feed", logger.ErrorField(err), logger.StringField("url", url))
 		return nil, fmt.Errorf("failed to fetch parse RSS feed: %w", err)
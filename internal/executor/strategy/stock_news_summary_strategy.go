@@ -56,17 +56,9 @@ func (s *StockNewsSummaryStrategy) GetType() entity.JobType {
 	return entity.JobTypeStockNewsSummary
 }
 
-// StockNewsSummaryPayload defines the payload for the stock news summary job.
-type StockNewsSummaryPayload struct {
-	MinToSummarizeNews int     `json:"min_to_summarize_news"`
-	MinConfidenceScore float64 `json:"min_confidence_score"`
-	MaxNewsAgeInDays   int     `json:"max_news_age_in_days"`
-	MaxNewsEachStock   int     `json:"max_news_each_stock"`
-}
-
 // Execute runs the stock news summary job.
 func (s *StockNewsSummaryStrategy) Execute(ctx context.Context, job *entity.Job) (string, error) {
-	var payload StockNewsSummaryPayload
+	var payload entity.StockNewsSummaryPayload
 	if err := json.Unmarshal(job.Payload, &payload); err != nil {
 		return "", fmt.Errorf("failed to unmarshal job payload: %w", err)
 	}
@@ -10,9 +10,11 @@ import (
 	"golang-stock-scryper/pkg/common"
 	"golang-stock-scryper/pkg/logger"
 	"golang-stock-scryper/pkg/redis"
+	"golang-stock-scryper/pkg/tracing"
 	"golang-stock-scryper/pkg/utils"
 
 	goRedis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -54,36 +56,7 @@ func (s *StockPositionMonitorStrategy) Execute(ctx context.Context, job *entity.
 	var results []StockPositionMonitorResult
 
 	for _, stockPosition := range stockPositions {
-		fieldsLog := []zap.Field{
-			logger.Field("stock_code", stockPosition.StockCode),
-			logger.Field("id", stockPosition.ID),
-			logger.Field("user_id", stockPosition.UserID),
-		}
-
-		streamData := &dto.StreamDataStockPositionMonitor{
-			StockPositionID: stockPosition.ID,
-			UserID:          stockPosition.UserID,
-			StockCode:       stockPosition.StockCode,
-		}
-		streamDataJSON, err := json.Marshal(streamData)
-		if err != nil {
-			loggerFields := append(fieldsLog, logger.ErrorField(err))
-			s.logger.Error("Failed to marshal stock position monitor payload", loggerFields...)
-			results = append(results, StockPositionMonitorResult{
-				StockCode: stockPosition.StockCode,
-				ID:        stockPosition.ID,
-				Success:   false,
-				Error:     err.Error(),
-			})
-			continue
-		}
-
-		if err := s.redisClient.XAdd(ctx, &goRedis.XAddArgs{
-			Stream: common.RedisStreamStockPositionMonitor,
-			Values: map[string]interface{}{"payload": streamDataJSON},
-		}).Err(); err != nil {
-			loggerFields := append(fieldsLog, logger.ErrorField(err))
-			s.logger.Error("Failed to enqueue stock position monitor task", loggerFields...)
+		if err := s.enqueue(ctx, stockPosition); err != nil {
 			results = append(results, StockPositionMonitorResult{
 				StockCode: stockPosition.StockCode,
 				ID:        stockPosition.ID,
@@ -107,3 +80,41 @@ func (s *StockPositionMonitorStrategy) Execute(ctx context.Context, job *entity.
 
 	return string(resultJSON), nil
 }
+
+// enqueue adds the monitoring of one stock position to the stock position monitor stream,
+// carrying the trace of the job execution.
+func (s *StockPositionMonitorStrategy) enqueue(ctx context.Context, stockPosition entity.StockPosition) error {
+	ctx, span := tracing.StartPublish(ctx, common.RedisStreamStockPositionMonitor,
+		attribute.String("stock.code", stockPosition.StockCode),
+		attribute.Int("stock_position.id", int(stockPosition.ID)),
+	)
+	defer span.End()
+
+	fieldsLog := []zap.Field{
+		logger.Field("stock_code", stockPosition.StockCode),
+		logger.Field("id", stockPosition.ID),
+		logger.Field("user_id", stockPosition.UserID),
+	}
+
+	streamDataJSON, err := json.Marshal(&dto.StreamDataStockPositionMonitor{
+		StockPositionID: stockPosition.ID,
+		UserID:          stockPosition.UserID,
+		StockCode:       stockPosition.StockCode,
+		TraceContext:    tracing.Inject(ctx),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to marshal stock position monitor payload", append(fieldsLog, logger.ErrorField(err))...)
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	if err := s.redisClient.XAdd(ctx, &goRedis.XAddArgs{
+		Stream: common.RedisStreamStockPositionMonitor,
+		Values: map[string]interface{}{"payload": streamDataJSON},
+	}).Err(); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to enqueue stock position monitor task", append(fieldsLog, logger.ErrorField(err))...)
+		tracing.RecordError(span, err)
+		return err
+	}
+	return nil
+}
@@ -36,15 +36,6 @@ type StockPriceAlertStrategy struct {
 	redisClient              *redisPkg.Client
 }
 
-// StockPriceAlertPayload defines the payload for stock price alert.
-type StockPriceAlertPayload struct {
-	DataInterval                string  `json:"data_interval"`
-	DataRange                   string  `json:"data_range"`
-	AlertTriggerWindowDuration  string  `json:"alert_trigger_window_duration"`
-	AlertCacheDuration          string  `json:"alert_cache_duration"`
-	AlertResendThresholdPercent float64 `json:"alert_resend_threshold_percent"`
-}
-
 // StockPriceAlertResult defines the result for stock price alert.
 type StockPriceAlertResult struct {
 	StockCode string `json:"stock_code"`
@@ -74,7 +65,7 @@ func (s *StockPriceAlertStrategy) Execute(ctx context.Context, job *entity.Job)
 	s.logger.DebugContext(ctx, "Executing stock alert job", logger.IntField("job_id", int(job.ID)))
 
 	var (
-		payload StockPriceAlertPayload
+		payload entity.StockPriceAlertPayload
 		results []StockPriceAlertResult
 	)
 	if err := json.Unmarshal(job.Payload, &payload); err != nil {
@@ -246,7 +237,7 @@ func (s *StockPriceAlertStrategy) sendTelegramMessageAlert(ctx context.Context,
 	}
 
 	message := telegram.FormatStockAlertResultForTelegram(alertType, stockPosition.StockCode, triggerPrice, targetPrice, timestamp)
-	err = s.telegramNotifier.SendMessageUser(message, stockPosition.User.TelegramID)
+	err = s.telegramNotifier.SendMessageUser(ctx, message, stockPosition.User.TelegramID)
 	if err != nil {
 		s.logger.Error("Failed to send alert", logger.ErrorField(err), logger.StringField("stock_code", stockPosition.StockCode))
 	}
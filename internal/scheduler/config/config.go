@@ -9,6 +9,23 @@ type Scheduler struct {
 	PollingInterval   string `mapstructure:"polling_interval"`
 	MaxConcurrentJobs int    `mapstructure:"max_concurrent_jobs"`
 	DefaultTimeout    string `mapstructure:"default_timeout"`
+	ClaimBatchSize    int    `mapstructure:"claim_batch_size"`
+}
+
+// Reaper holds configuration for reaping executions that stopped making progress.
+// Durations are Go duration strings; empty values use the defaults.
+type Reaper struct {
+	Interval         string `mapstructure:"interval"`          // how often executions are checked
+	HeartbeatTimeout string `mapstructure:"heartbeat_timeout"` // executions without a heartbeat for this long are lost
+	QueuedTimeout    string `mapstructure:"queued_timeout"`    // queued executions no executor picked up for this long are lost
+	TimeoutGrace     string `mapstructure:"timeout_grace"`     // added to the job timeout before a running execution times out
+}
+
+// Auth holds configuration for authenticating callers of the scheduling API.
+type Auth struct {
+	Enabled   bool   `mapstructure:"enabled"`    // when false, every caller is an admin
+	JWTSecret string `mapstructure:"jwt_secret"` // HS256 secret of bearer tokens; empty disables JWTs
+	JWTIssuer string `mapstructure:"jwt_issuer"` // when set, tokens must carry this iss claim
 }
 
 // Config holds the full configuration for the scheduler service.
@@ -19,6 +36,9 @@ type Config struct {
 	Redis     config.Redis    `mapstructure:"redis"`
 	API       config.API      `mapstructure:"api"`
 	Scheduler Scheduler       `mapstructure:"scheduler"`
+	Reaper    Reaper          `mapstructure:"reaper"`
+	Auth      Auth            `mapstructure:"auth"`
+	Tracing   config.Tracing  `mapstructure:"tracing"`
 }
 
 // Load loads the scheduler configuration from the given path.
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"strings"
+
+	"golang-stock-scryper/internal/scheduler/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// ActorHeader is the request header that names who makes a change, recorded in job versions.
+	ActorHeader = "X-Actor"
+	// maxActorLength matches the size of the actor column of job versions.
+	maxActorLength = 255
+)
+
+// ActorMiddleware records the caller named by the X-Actor header as the author of the changes
+// made by a request. Requests without the header are recorded as service.DefaultActor.
+func ActorMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		actor := strings.TrimSpace(c.Request().Header.Get(ActorHeader))
+		if actor != "" {
+			if len(actor) > maxActorLength {
+				actor = actor[:maxActorLength]
+			}
+			req := c.Request()
+			c.SetRequest(req.WithContext(service.WithActor(req.Context(), actor)))
+		}
+		return next(c)
+	}
+}
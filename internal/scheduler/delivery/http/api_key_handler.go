@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/internal/scheduler/service"
+	"golang-stock-scryper/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// APIKeyHandler handles HTTP requests for API keys.
+type APIKeyHandler struct {
+	authService service.AuthService
+	logger      *logger.Logger
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler.
+func NewAPIKeyHandler(authService service.AuthService, logger *logger.Logger) *APIKeyHandler {
+	return &APIKeyHandler{authService: authService, logger: logger}
+}
+
+// RegisterRoutes registers the API key routes to the Echo group. They are for admins only.
+func (h *APIKeyHandler) RegisterRoutes(g *echo.Group) {
+	admin := RequireRole(entity.RoleAdmin)
+	g.POST("", h.CreateAPIKey, admin)
+	g.GET("", h.ListAPIKeys, admin)
+	g.DELETE("/:id", h.RevokeAPIKey, admin)
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an API key with the given role. The key is only returned in this response
+// @Tags api-keys
+// @Accept  json
+// @Produce  json
+// @Param   apiKey  body    dto.CreateAPIKeyRequest   true    "API key to create"
+// @Success 201 {object} dto.CreateAPIKeyResponse
+// @Failure 400 {object} dto.ValidationErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c echo.Context) error {
+	var req dto.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
+	}
+
+	keyResponse, err := h.authService.CreateAPIKey(c.Request().Context(), &req)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{Error: "Invalid API key", Fields: validationErr.Fields})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, keyResponse)
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List every API key, including revoked ones. Keys themselves are never returned, only their prefix
+// @Tags api-keys
+// @Produce  json
+// @Success 200 {array} dto.APIKeyResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c echo.Context) error {
+	keys, err := h.authService.ListAPIKeys(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Failed to list API keys", logger.ErrorField(err))
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to list API keys"})
+	}
+	return c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key, so it no longer authenticates requests. The key stays listed
+// @Tags api-keys
+// @Produce  json
+// @Param   id  path    int true    "API key ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid API key ID"})
+	}
+
+	if err := h.authService.RevokeAPIKey(c.Request().Context(), uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "API key not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to revoke API key"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
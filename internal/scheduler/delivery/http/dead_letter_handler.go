@@ -0,0 +1,234 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/internal/scheduler/service"
+	"golang-stock-scryper/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DeadLetterHandler handles HTTP requests for the dead letters of the executor's streams.
+type DeadLetterHandler struct {
+	deadLetterService service.DeadLetterService
+	logger            *logger.Logger
+}
+
+// NewDeadLetterHandler creates a new DeadLetterHandler.
+func NewDeadLetterHandler(deadLetterService service.DeadLetterService, logger *logger.Logger) *DeadLetterHandler {
+	return &DeadLetterHandler{deadLetterService: deadLetterService, logger: logger}
+}
+
+// RegisterRoutes registers the dead-letter routes to the Echo group.
+func (h *DeadLetterHandler) RegisterRoutes(g *echo.Group) {
+	viewer := RequireRole(entity.RoleViewer)
+	operator := RequireRole(entity.RoleOperator)
+	admin := RequireRole(entity.RoleAdmin)
+	g.GET("", h.ListDeadLetterStreams, viewer)
+	g.GET("/:stream", h.ListDeadLetters, viewer)
+	g.DELETE("/:stream", h.PurgeDeadLetters, admin)
+	g.POST("/:stream/replay", h.ReplayDeadLetters, operator)
+	g.GET("/:stream/:id", h.GetDeadLetter, viewer)
+	g.DELETE("/:stream/:id", h.DeleteDeadLetter, admin)
+	g.POST("/:stream/:id/replay", h.ReplayDeadLetter, operator)
+}
+
+// ListDeadLetterStreams godoc
+// @Summary List dead-letter streams
+// @Description List the streams whose failed messages are kept in a dead-letter stream, with the number of dead letters of each
+// @Tags dead-letters
+// @Produce  json
+// @Success 200 {array} dto.DeadLetterStreamResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /dead-letters [get]
+func (h *DeadLetterHandler) ListDeadLetterStreams(c echo.Context) error {
+	streams, err := h.deadLetterService.ListDeadLetterStreams(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Failed to list dead-letter streams", logger.ErrorField(err))
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to get dead-letter streams"})
+	}
+	return c.JSON(http.StatusOK, streams)
+}
+
+// ListDeadLetters godoc
+// @Summary List the dead letters of a stream
+// @Description List the messages of a stream that failed every retry or could not be decoded, newest first, with their payload, last error, attempts and failure times
+// @Tags dead-letters
+// @Produce  json
+// @Param   stream  path    string  true    "Stream, e.g. stock.analyzer"
+// @Param   limit   query   int     false   "Page size (default 50, max 500)"
+// @Param   cursor  query   string  false   "next_cursor from the previous page"
+// @Success 200 {object} dto.DeadLetterListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /dead-letters/{stream} [get]
+func (h *DeadLetterHandler) ListDeadLetters(c echo.Context) error {
+	var req dto.ListDeadLettersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid query parameters"})
+	}
+
+	letters, err := h.deadLetterService.ListDeadLetters(c.Request().Context(), c.Param("stream"), &req)
+	if err != nil {
+		return h.errorResponse(c, err, "Failed to get dead letters")
+	}
+	return c.JSON(http.StatusOK, letters)
+}
+
+// GetDeadLetter godoc
+// @Summary Get a dead letter
+// @Description Get a single dead letter of a stream by its ID in the dead-letter stream
+// @Tags dead-letters
+// @Produce  json
+// @Param   stream  path    string  true    "Stream, e.g. stock.analyzer"
+// @Param   id      path    string  true    "Dead-letter ID"
+// @Success 200 {object} dto.DeadLetterResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /dead-letters/{stream}/{id} [get]
+func (h *DeadLetterHandler) GetDeadLetter(c echo.Context) error {
+	letter, err := h.deadLetterService.GetDeadLetter(c.Request().Context(), c.Param("stream"), c.Param("id"))
+	if err != nil {
+		return h.errorResponse(c, err, "Failed to get dead letter")
+	}
+	return c.JSON(http.StatusOK, letter)
+}
+
+// ReplayDeadLetters godoc
+// @Summary Replay dead letters
+// @Description Add the payload of each given dead letter back to its stream as a new message and remove the dead letter. A replayed message gets a fresh retry budget. IDs that are not dead letters of the stream, e.g. because they were already replayed, are listed as not found
+// @Tags dead-letters
+// @Accept  json
+// @Produce  json
+// @Param   stream  path    string                          true    "Stream, e.g. stock.analyzer"
+// @Param   replay  body    dto.ReplayDeadLettersRequest    true    "Dead letters to replay"
+// @Success 200 {object} dto.ReplayDeadLettersResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /dead-letters/{stream}/replay [post]
+func (h *DeadLetterHandler) ReplayDeadLetters(c echo.Context) error {
+	var req dto.ReplayDeadLettersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
+	}
+
+	replayed, err := h.deadLetterService.ReplayDeadLetters(c.Request().Context(), c.Param("stream"), req.IDs)
+	if err != nil {
+		return h.errorResponse(c, err, "Failed to replay dead letters")
+	}
+	return c.JSON(http.StatusOK, replayed)
+}
+
+// ReplayDeadLetter godoc
+// @Summary Replay a dead letter
+// @Description Add the payload of a dead letter back to its stream as a new message and remove the dead letter. The replayed message gets a fresh retry budget
+// @Tags dead-letters
+// @Produce  json
+// @Param   stream  path    string  true    "Stream, e.g. stock.analyzer"
+// @Param   id      path    string  true    "Dead-letter ID"
+// @Success 200 {object} dto.ReplayedDeadLetter
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /dead-letters/{stream}/{id}/replay [post]
+func (h *DeadLetterHandler) ReplayDeadLetter(c echo.Context) error {
+	id := c.Param("id")
+	replayed, err := h.deadLetterService.ReplayDeadLetters(c.Request().Context(), c.Param("stream"), []string{id})
+	if errors.Is(err, service.ErrInvalidInput) || (err == nil && len(replayed.Replayed) == 0) {
+		err = service.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return h.errorResponse(c, err, "Failed to replay dead letter")
+	}
+	return c.JSON(http.StatusOK, replayed.Replayed[0])
+}
+
+// DeleteDeadLetter godoc
+// @Summary Delete a dead letter
+// @Description Remove a dead letter without replaying it
+// @Tags dead-letters
+// @Produce  json
+// @Param   stream  path    string  true    "Stream, e.g. stock.analyzer"
+// @Param   id      path    string  true    "Dead-letter ID"
+// @Success 204 {object} nil
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /dead-letters/{stream}/{id} [delete]
+func (h *DeadLetterHandler) DeleteDeadLetter(c echo.Context) error {
+	if err := h.deadLetterService.DeleteDeadLetter(c.Request().Context(), c.Param("stream"), c.Param("id")); err != nil {
+		return h.errorResponse(c, err, "Failed to delete dead letter")
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PurgeDeadLetters godoc
+// @Summary Purge the dead letters of a stream
+// @Description Remove every dead letter of a stream, or only those added to the dead-letter stream before the given time, without replaying them
+// @Tags dead-letters
+// @Produce  json
+// @Param   stream  path    string  true    "Stream, e.g. stock.analyzer"
+// @Param   before  query   string  false   "Only dead letters added before this RFC 3339 time"
+// @Success 200 {object} dto.PurgeDeadLettersResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /dead-letters/{stream} [delete]
+func (h *DeadLetterHandler) PurgeDeadLetters(c echo.Context) error {
+	var req dto.PurgeDeadLettersRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid query parameters"})
+	}
+
+	purged, err := h.deadLetterService.PurgeDeadLetters(c.Request().Context(), c.Param("stream"), &req)
+	if err != nil {
+		return h.errorResponse(c, err, "Failed to purge dead letters")
+	}
+	return c.JSON(http.StatusOK, purged)
+}
+
+// errorResponse maps a dead-letter service error to its response.
+func (h *DeadLetterHandler) errorResponse(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	case errors.Is(err, service.ErrUnknownDeadLetterStream):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Stream has no dead letters"})
+	case errors.Is(err, service.ErrDeadLetterNotFound):
+		return c.JSON(http.StatusNotFound, echo.Map{"error": "Dead letter not found"})
+	}
+	h.logger.Error(message, logger.ErrorField(err), logger.StringField("stream", c.Param("stream")))
+	return c.JSON(http.StatusInternalServerError, echo.Map{"error": message})
+}
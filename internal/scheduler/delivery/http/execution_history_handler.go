@@ -1,13 +1,17 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
 	"golang-stock-scryper/internal/scheduler/service"
 	"golang-stock-scryper/pkg/logger"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 // ExecutionHistoryHandler handles HTTP requests for execution history.
@@ -23,28 +27,54 @@ func NewExecutionHistoryHandler(historyService service.ExecutionHistoryService,
 
 // RegisterRoutes registers the execution history routes to the Echo group.
 func (h *ExecutionHistoryHandler) RegisterRoutes(g *echo.Group) {
-	g.GET("", h.GetAllExecutionHistories)
-	g.GET("/:id", h.GetExecutionHistoryByID)
+	viewer := RequireRole(entity.RoleViewer)
+	g.GET("", h.ListExecutionHistories, viewer)
+	g.GET("/:id", h.GetExecutionHistoryByID, viewer)
+	g.GET("/:id/chain", h.GetExecutionChain, viewer)
+	g.POST("/:id/cancel", h.CancelExecution, RequireRole(entity.RoleOperator))
 }
 
 // RegisterJobRoutes registers the job-specific execution history routes.
 func (h *ExecutionHistoryHandler) RegisterJobRoutes(g *echo.Group) {
-	g.GET("/:id/executions", h.GetExecutionHistoriesByJobID)
+	g.GET("/:id/executions", h.GetExecutionHistoriesByJobID, RequireRole(entity.RoleViewer))
 }
 
-// GetAllExecutionHistories godoc
-// @Summary Get all execution histories
-// @Description Get all execution history records
+// ListExecutionHistories godoc
+// @Summary List execution histories
+// @Description List execution history records with filters and pagination. Use either offset or cursor paging.
 // @Tags executions
 // @Produce  json
-// @Success 200 {array} dto.ExecutionHistoryResponse
+// @Param   job_id          query   int     false   "Filter by job ID"
+// @Param   schedule_id     query   int     false   "Filter by schedule ID"
+// @Param   status          query   string  false   "Comma-separated statuses, e.g. failed,timeout"
+// @Param   started_after   query   string  false   "Only executions started at or after this RFC 3339 time"
+// @Param   started_before  query   string  false   "Only executions started before this RFC 3339 time"
+// @Param   sort            query   string  false   "Sort column: started_at (default) or id"
+// @Param   order           query   string  false   "Sort order: desc (default) or asc"
+// @Param   limit           query   int     false   "Page size (default 50, max 500)"
+// @Param   offset          query   int     false   "Number of records to skip"
+// @Param   cursor          query   string  false   "next_cursor from the previous page"
+// @Success 200 {object} dto.ExecutionHistoryListResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /executions [get]
-func (h *ExecutionHistoryHandler) GetAllExecutionHistories(c echo.Context) error {
-	histories, err := h.historyService.GetAllExecutionHistories(c.Request().Context())
+func (h *ExecutionHistoryHandler) ListExecutionHistories(c echo.Context) error {
+	var req dto.ListExecutionHistoriesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid query parameters"})
+	}
+
+	histories, err := h.historyService.ListExecutionHistories(c.Request().Context(), &req)
 	if err != nil {
-		h.logger.Error("Failed to get all execution histories", logger.ErrorField(err))
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get execution histories"})
+		if errors.Is(err, service.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		h.logger.Error("Failed to list execution histories", logger.ErrorField(err))
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to get execution histories"})
 	}
 	return c.JSON(http.StatusOK, histories)
 }
@@ -57,7 +87,11 @@ func (h *ExecutionHistoryHandler) GetAllExecutionHistories(c echo.Context) error
 // @Param   id  path    int true    "Execution History ID"
 // @Success 200 {object} dto.ExecutionHistoryResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /executions/{id} [get]
 func (h *ExecutionHistoryHandler) GetExecutionHistoryByID(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -75,13 +109,26 @@ func (h *ExecutionHistoryHandler) GetExecutionHistoryByID(c echo.Context) error
 
 // GetExecutionHistoriesByJobID godoc
 // @Summary Get execution histories for a job
-// @Description Get all execution history records for a specific job ID
+// @Description List execution history records of a specific job with the same filters and pagination as /executions
 // @Tags jobs
 // @Produce  json
-// @Param   id  path    int true    "Job ID"
-// @Success 200 {array} dto.ExecutionHistoryResponse
+// @Param   id              path    int     true    "Job ID"
+// @Param   schedule_id     query   int     false   "Filter by schedule ID"
+// @Param   status          query   string  false   "Comma-separated statuses, e.g. failed,timeout"
+// @Param   started_after   query   string  false   "Only executions started at or after this RFC 3339 time"
+// @Param   started_before  query   string  false   "Only executions started before this RFC 3339 time"
+// @Param   sort            query   string  false   "Sort column: started_at (default) or id"
+// @Param   order           query   string  false   "Sort order: desc (default) or asc"
+// @Param   limit           query   int     false   "Page size (default 50, max 500)"
+// @Param   offset          query   int     false   "Number of records to skip"
+// @Param   cursor          query   string  false   "next_cursor from the previous page"
+// @Success 200 {object} dto.ExecutionHistoryListResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /jobs/{id}/executions [get]
 func (h *ExecutionHistoryHandler) GetExecutionHistoriesByJobID(c echo.Context) error {
 	jobID, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -89,10 +136,83 @@ func (h *ExecutionHistoryHandler) GetExecutionHistoriesByJobID(c echo.Context) e
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid job ID"})
 	}
 
-	histories, err := h.historyService.GetExecutionHistoriesByJobID(c.Request().Context(), uint(jobID))
+	var req dto.ListExecutionHistoriesRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid query parameters"})
+	}
+
+	histories, err := h.historyService.GetExecutionHistoriesByJobID(c.Request().Context(), uint(jobID), &req)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		if errors.Is(err, service.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		h.logger.Error("Failed to get execution histories by job ID", logger.ErrorField(err), logger.Field("job_id", jobID))
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to get execution histories"})
 	}
 
 	return c.JSON(http.StatusOK, histories)
 }
+
+// GetExecutionChain godoc
+// @Summary Get the dependency chain of an execution
+// @Description Get an execution with the upstream executions that triggered it and the downstream executions it triggered
+// @Tags executions
+// @Produce  json
+// @Param   id  path    int true    "Execution History ID"
+// @Success 200 {object} dto.ExecutionChainResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /executions/{id}/chain [get]
+func (h *ExecutionHistoryHandler) GetExecutionChain(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid history ID"})
+	}
+
+	chain, err := h.historyService.GetExecutionChain(c.Request().Context(), uint(id))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, chain)
+}
+
+// CancelExecution godoc
+// @Summary Cancel a queued or running execution
+// @Description Cancel a queued or running execution. A queued execution no executor has picked up yet is cancelled right away; otherwise the executor holding it stops the job, and the execution ends with status cancelled and keeps the output produced so far.
+// @Tags executions
+// @Produce  json
+// @Param   id  path    int true    "Execution History ID"
+// @Success 202 {object} dto.ExecutionHistoryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 409 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /executions/{id}/cancel [post]
+func (h *ExecutionHistoryHandler) CancelExecution(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid history ID"})
+	}
+
+	history, err := h.historyService.CancelExecution(c.Request().Context(), uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Execution not found"})
+		case errors.Is(err, service.ErrExecutionNotRunning):
+			return c.JSON(http.StatusConflict, echo.Map{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, history)
+}
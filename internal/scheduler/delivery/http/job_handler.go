@@ -1,14 +1,17 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"golang-stock-scryper/internal/entity"
 	"golang-stock-scryper/internal/scheduler/dto"
 	"golang-stock-scryper/internal/scheduler/service"
 	"golang-stock-scryper/pkg/logger"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 // JobHandler handles HTTP requests for jobs.
@@ -24,23 +27,41 @@ func NewJobHandler(jobService service.JobService, logger *logger.Logger) *JobHan
 
 // RegisterRoutes registers the job routes to the Echo group.
 func (h *JobHandler) RegisterRoutes(g *echo.Group) {
-	g.POST("", h.CreateJob)
-	g.GET("", h.GetAllJobs)
-	g.GET("/:id", h.GetJobByID)
-	g.PUT("/:id", h.UpdateJob)
-	g.DELETE("/:id", h.DeleteJob)
+	viewer, operator, admin := RequireRole(entity.RoleViewer), RequireRole(entity.RoleOperator), RequireRole(entity.RoleAdmin)
+	g.POST("", h.CreateJob, admin)
+	g.GET("", h.GetAllJobs, viewer)
+	g.GET("/:id", h.GetJobByID, viewer)
+	g.PUT("/:id", h.UpdateJob, admin)
+	g.DELETE("/:id", h.DeleteJob, admin)
+	g.POST("/:id/trigger", h.TriggerJob, operator)
+	g.POST("/:id/pause", h.PauseJob, operator)
+	g.POST("/:id/resume", h.ResumeJob, operator)
+	g.GET("/:id/versions", h.ListJobVersions, viewer)
+	g.GET("/:id/versions/diff", h.DiffJobVersions, viewer)
+	g.GET("/:id/versions/:version", h.GetJobVersion, viewer)
+	// Rolling back can restore any job definition, so it needs the role that may create jobs.
+	g.POST("/:id/versions/:version/rollback", h.RollbackJob, RequireRole(entity.RoleAdmin))
+}
+
+// RegisterJobTypeRoutes registers the job type routes to the Echo group.
+func (h *JobHandler) RegisterJobTypeRoutes(g *echo.Group) {
+	g.GET("", h.ListJobTypes, RequireRole(entity.RoleViewer))
 }
 
 // CreateJob godoc
 // @Summary Create a new job
-// @Description Create a new job with schedules
+// @Description Create a new job with schedules. The payload is validated against the schema of the job type, see GET /job-types
 // @Tags jobs
 // @Accept  json
 // @Produce  json
 // @Param   job  body    dto.CreateJobRequest   true    "Job to create"
 // @Success 201 {object} dto.JobResponse
-// @Failure 400 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ValidationErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /jobs [post]
 func (h *JobHandler) CreateJob(c echo.Context) error {
 	var req dto.CreateJobRequest
@@ -48,10 +69,15 @@ func (h *JobHandler) CreateJob(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
 	}
 
-	// TODO: Add validation for the request payload
-
 	jobResponse, err := h.jobService.CreateJob(c.Request().Context(), &req)
 	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{Error: "Invalid job", Fields: validationErr.Fields})
+		}
+		if errors.Is(err, service.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
 		// TODO: Differentiate between different error types (e.g., validation, db error)
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
@@ -67,7 +93,11 @@ func (h *JobHandler) CreateJob(c echo.Context) error {
 // @Param   id  path    int true    "Job ID"
 // @Success 200 {object} dto.JobResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /jobs/{id} [get]
 func (h *JobHandler) GetJobByID(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -90,7 +120,11 @@ func (h *JobHandler) GetJobByID(c echo.Context) error {
 // @Tags jobs
 // @Produce  json
 // @Success 200 {array} dto.JobResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /jobs [get]
 func (h *JobHandler) GetAllJobs(c echo.Context) error {
 	jobs, err := h.jobService.GetAllJobs(c.Request().Context())
@@ -109,7 +143,11 @@ func (h *JobHandler) GetAllJobs(c echo.Context) error {
 // @Param   id  path    int true    "Job ID"
 // @Success 204 {object} nil
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /jobs/{id} [delete]
 func (h *JobHandler) DeleteJob(c echo.Context) error {
 	idStr := c.Param("id")
@@ -135,8 +173,12 @@ func (h *JobHandler) DeleteJob(c echo.Context) error {
 // @Param   id  path    int true    "Job ID"
 // @Param   job  body    dto.UpdateJobRequest   true    "Job to update"
 // @Success 200 {object} dto.JobResponse
-// @Failure 400 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ValidationErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /jobs/{id} [put]
 func (h *JobHandler) UpdateJob(c echo.Context) error {
 	idStr := c.Param("id")
@@ -150,13 +192,313 @@ func (h *JobHandler) UpdateJob(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
 	}
 
-	// TODO: Add validation for the request payload
-
 	jobResponse, err := h.jobService.UpdateJob(c.Request().Context(), uint(id), &req)
 	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{Error: "Invalid job", Fields: validationErr.Fields})
+		}
+		if errors.Is(err, service.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
 		// TODO: Differentiate between different error types (e.g., not found, validation, db error)
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, jobResponse)
 }
+
+// TriggerJob godoc
+// @Summary Trigger a job now
+// @Description Enqueue an immediate execution of a job without touching its schedules, optionally overriding the payload for this run only. Triggering needs the operator role, overriding the payload the admin role. The override replaces the payload of the job and is validated against the schema of the job type, see GET /job-types
+// @Tags jobs
+// @Accept  json
+// @Produce  json
+// @Param   id  path    int true    "Job ID"
+// @Param   trigger  body    dto.TriggerJobRequest   false    "Optional payload override"
+// @Success 202 {object} dto.TriggerJobResponse
+// @Failure 400 {object} dto.ValidationErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /jobs/{id}/trigger [post]
+func (h *JobHandler) TriggerJob(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid job ID"})
+	}
+
+	var req dto.TriggerJobRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
+	}
+	// A payload override changes what the job does, e.g. the URL of an http_request job, so
+	// like changing the job it needs the admin role.
+	if service.HasPayloadOverride(&req) {
+		if ok, err := checkRole(c, entity.RoleAdmin); !ok {
+			return err
+		}
+	}
+
+	triggerResponse, err := h.jobService.TriggerJob(c.Request().Context(), uint(id), &req)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{Error: "Invalid payload", Fields: validationErr.Fields})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Job not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, triggerResponse)
+}
+
+// PauseJob godoc
+// @Summary Pause a job
+// @Description Pause every schedule of a job until it is resumed or, when until is given, until that time. Scheduled runs that fall in the pause are skipped; manual triggers still run
+// @Tags jobs
+// @Accept  json
+// @Produce  json
+// @Param   id  path    int true    "Job ID"
+// @Param   pause  body    dto.PauseRequest   false    "Optional time to resume at"
+// @Success 200 {object} dto.JobResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /jobs/{id}/pause [post]
+func (h *JobHandler) PauseJob(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid job ID"})
+	}
+
+	var req dto.PauseRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
+	}
+
+	jobResponse, err := h.jobService.PauseJob(c.Request().Context(), uint(id), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Job not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, jobResponse)
+}
+
+// ResumeJob godoc
+// @Summary Resume a job
+// @Description Resume a paused job. Its schedules continue from their next fire time, without catching up on runs skipped during the pause
+// @Tags jobs
+// @Produce  json
+// @Param   id  path    int true    "Job ID"
+// @Success 200 {object} dto.JobResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /jobs/{id}/resume [post]
+func (h *JobHandler) ResumeJob(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid job ID"})
+	}
+
+	jobResponse, err := h.jobService.ResumeJob(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Job not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, jobResponse)
+}
+
+// ListJobTypes godoc
+// @Summary List job types
+// @Description List every job type with the JSON schema of its payload
+// @Tags jobs
+// @Produce  json
+// @Success 200 {array} dto.JobTypeResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /job-types [get]
+func (h *JobHandler) ListJobTypes(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.jobService.ListJobTypes())
+}
+
+// ListJobVersions godoc
+// @Summary List the versions of a job
+// @Description List every recorded change to a job definition or its schedules, newest first. Use GET /jobs/{id}/versions/{version} for the full snapshots
+// @Tags jobs
+// @Produce  json
+// @Param   id  path    int true    "Job ID"
+// @Success 200 {array} dto.JobVersionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /jobs/{id}/versions [get]
+func (h *JobHandler) ListJobVersions(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid job ID"})
+	}
+
+	versions, err := h.jobService.ListJobVersions(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Job not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, versions)
+}
+
+// GetJobVersion godoc
+// @Summary Get a version of a job
+// @Description Get a recorded change to a job with the full job definition before and after it
+// @Tags jobs
+// @Produce  json
+// @Param   id  path    int true    "Job ID"
+// @Param   version  path    int true    "Version number"
+// @Success 200 {object} dto.JobVersionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /jobs/{id}/versions/{version} [get]
+func (h *JobHandler) GetJobVersion(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid job ID"})
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid version"})
+	}
+
+	versionResponse, err := h.jobService.GetJobVersion(c.Request().Context(), uint(id), version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Job version not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, versionResponse)
+}
+
+// DiffJobVersions godoc
+// @Summary Compare two versions of a job
+// @Description List the fields that differ between the job definitions as they were after two versions
+// @Tags jobs
+// @Produce  json
+// @Param   id  path    int true    "Job ID"
+// @Param   from  query   int true    "Version to compare from"
+// @Param   to    query   int true    "Version to compare to"
+// @Success 200 {object} dto.JobVersionDiffResponse
+// @Failure 400 {object} dto.ValidationErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /jobs/{id}/versions/diff [get]
+func (h *JobHandler) DiffJobVersions(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid job ID"})
+	}
+
+	var req dto.DiffJobVersionsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid query parameters"})
+	}
+
+	diff, err := h.jobService.DiffJobVersions(c.Request().Context(), uint(id), &req)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{Error: "Invalid versions", Fields: validationErr.Fields})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Job version not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, diff)
+}
+
+// RollbackJob godoc
+// @Summary Roll a job back to a version
+// @Description Restore the job definition as it was after the given version, recorded as a new version. Needs the admin role. A deleted job is restored under its old ID. Schedules that still exist keep their execution times; schedules deleted since are recreated
+// @Tags jobs
+// @Produce  json
+// @Param   id  path    int true    "Job ID"
+// @Param   version  path    int true    "Version number to restore"
+// @Success 200 {object} dto.JobResponse
+// @Failure 400 {object} dto.ValidationErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /jobs/{id}/versions/{version}/rollback [post]
+func (h *JobHandler) RollbackJob(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid job ID"})
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid version"})
+	}
+
+	jobResponse, err := h.jobService.RollbackJob(c.Request().Context(), uint(id), version)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{Error: "The version cannot be restored", Fields: validationErr.Fields})
+		}
+		if errors.Is(err, service.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Job version not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, jobResponse)
+}
@@ -1,14 +1,17 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"golang-stock-scryper/internal/entity"
 	"golang-stock-scryper/internal/scheduler/dto"
 	"golang-stock-scryper/internal/scheduler/service"
 	"golang-stock-scryper/pkg/logger"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 // ScheduleHandler handles HTTP requests for schedules.
@@ -24,11 +27,15 @@ func NewScheduleHandler(scheduleService service.ScheduleService, logger *logger.
 
 // RegisterRoutes registers the schedule routes to the Echo group.
 func (h *ScheduleHandler) RegisterRoutes(g *echo.Group) {
-	g.POST("", h.CreateSchedule)
-	g.GET("", h.GetAllSchedules)
-	g.GET("/:id", h.GetScheduleByID)
-	g.PUT("/:id", h.UpdateSchedule)
-	g.DELETE("/:id", h.DeleteSchedule)
+	viewer, operator, admin := RequireRole(entity.RoleViewer), RequireRole(entity.RoleOperator), RequireRole(entity.RoleAdmin)
+	g.POST("", h.CreateSchedule, admin)
+	g.POST("/preview", h.PreviewSchedule, viewer)
+	g.GET("", h.GetAllSchedules, viewer)
+	g.GET("/:id", h.GetScheduleByID, viewer)
+	g.PUT("/:id", h.UpdateSchedule, admin)
+	g.DELETE("/:id", h.DeleteSchedule, admin)
+	g.POST("/:id/pause", h.PauseSchedule, operator)
+	g.POST("/:id/resume", h.ResumeSchedule, operator)
 }
 
 // CreateSchedule godoc
@@ -40,7 +47,11 @@ func (h *ScheduleHandler) RegisterRoutes(g *echo.Group) {
 // @Param   schedule  body    dto.CreateScheduleRequest   true    "Schedule to create"
 // @Success 201 {object} dto.ScheduleResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /schedules [post]
 func (h *ScheduleHandler) CreateSchedule(c echo.Context) error {
 	var req dto.CreateScheduleRequest
@@ -50,12 +61,48 @@ func (h *ScheduleHandler) CreateSchedule(c echo.Context) error {
 
 	scheduleResponse, err := h.scheduleService.CreateSchedule(c.Request().Context(), &req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusCreated, scheduleResponse)
 }
 
+// PreviewSchedule godoc
+// @Summary Preview the fire times of a cron expression
+// @Description Validate a cron expression and time zone and list their next fire times, evaluated exactly as the scheduler does
+// @Tags schedules
+// @Accept  json
+// @Produce  json
+// @Param   preview  body    dto.PreviewScheduleRequest   true    "Cron expression to preview"
+// @Success 200 {object} dto.PreviewScheduleResponse
+// @Failure 400 {object} dto.ValidationErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /schedules/preview [post]
+func (h *ScheduleHandler) PreviewSchedule(c echo.Context) error {
+	var req dto.PreviewScheduleRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
+	}
+
+	previewResponse, err := h.scheduleService.PreviewSchedule(&req)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{Error: "Invalid schedule", Fields: validationErr.Fields})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, previewResponse)
+}
+
 // GetScheduleByID godoc
 // @Summary Get a schedule by its ID
 // @Description Get a schedule by its ID
@@ -64,7 +111,11 @@ func (h *ScheduleHandler) CreateSchedule(c echo.Context) error {
 // @Param   id  path    int true    "Schedule ID"
 // @Success 200 {object} dto.ScheduleResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /schedules/{id} [get]
 func (h *ScheduleHandler) GetScheduleByID(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -86,7 +137,11 @@ func (h *ScheduleHandler) GetScheduleByID(c echo.Context) error {
 // @Tags schedules
 // @Produce  json
 // @Success 200 {array} dto.ScheduleResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /schedules [get]
 func (h *ScheduleHandler) GetAllSchedules(c echo.Context) error {
 	schedules, err := h.scheduleService.GetAllSchedules(c.Request().Context())
@@ -107,7 +162,11 @@ func (h *ScheduleHandler) GetAllSchedules(c echo.Context) error {
 // @Param   schedule  body    dto.UpdateScheduleRequest   true    "Schedule to update"
 // @Success 200 {object} dto.ScheduleResponse
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /schedules/{id} [put]
 func (h *ScheduleHandler) UpdateSchedule(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -122,6 +181,9 @@ func (h *ScheduleHandler) UpdateSchedule(c echo.Context) error {
 
 	scheduleResponse, err := h.scheduleService.UpdateSchedule(c.Request().Context(), uint(id), &req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
 	}
 
@@ -136,7 +198,11 @@ func (h *ScheduleHandler) UpdateSchedule(c echo.Context) error {
 // @Param   id  path    int true    "Schedule ID"
 // @Success 204 {object} nil
 // @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
 // @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /schedules/{id} [delete]
 func (h *ScheduleHandler) DeleteSchedule(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -150,3 +216,77 @@ func (h *ScheduleHandler) DeleteSchedule(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// PauseSchedule godoc
+// @Summary Pause a schedule
+// @Description Pause a schedule until it is resumed or, when until is given, until that time. Runs that fall in the pause are skipped
+// @Tags schedules
+// @Accept  json
+// @Produce  json
+// @Param   id  path    int true    "Schedule ID"
+// @Param   pause  body    dto.PauseRequest   false    "Optional time to resume at"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /schedules/{id}/pause [post]
+func (h *ScheduleHandler) PauseSchedule(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid schedule ID"})
+	}
+
+	var req dto.PauseRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
+	}
+
+	scheduleResponse, err := h.scheduleService.PauseSchedule(c.Request().Context(), uint(id), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Schedule not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, scheduleResponse)
+}
+
+// ResumeSchedule godoc
+// @Summary Resume a schedule
+// @Description Resume a paused schedule. It continues from its next fire time, without catching up on runs skipped during the pause
+// @Tags schedules
+// @Produce  json
+// @Param   id  path    int true    "Schedule ID"
+// @Success 200 {object} dto.ScheduleResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /schedules/{id}/resume [post]
+func (h *ScheduleHandler) ResumeSchedule(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid schedule ID"})
+	}
+
+	scheduleResponse, err := h.scheduleService.ResumeSchedule(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Schedule not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, scheduleResponse)
+}
@@ -0,0 +1,243 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/internal/scheduler/service"
+	"golang-stock-scryper/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// WebhookHandler handles HTTP requests for webhook subscriptions.
+type WebhookHandler struct {
+	webhookService service.WebhookService
+	logger         *logger.Logger
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(webhookService service.WebhookService, logger *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService, logger: logger}
+}
+
+// RegisterRoutes registers the webhook routes to the Echo group.
+func (h *WebhookHandler) RegisterRoutes(g *echo.Group) {
+	viewer := RequireRole(entity.RoleViewer)
+	admin := RequireRole(entity.RoleAdmin)
+	g.POST("", h.CreateWebhook, admin)
+	g.GET("", h.GetAllWebhooks, viewer)
+	g.GET("/:id", h.GetWebhookByID, viewer)
+	g.PUT("/:id", h.UpdateWebhook, admin)
+	g.DELETE("/:id", h.DeleteWebhook, admin)
+	g.GET("/:id/deliveries", h.ListWebhookDeliveries, viewer)
+}
+
+// CreateWebhook godoc
+// @Summary Create a webhook subscription
+// @Description Subscribe a URL to execution lifecycle events, for every job or a single one. The signing secret is generated unless given and only returned in this response
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param   webhook  body    dto.CreateWebhookRequest   true    "Webhook to create"
+// @Success 201 {object} dto.CreateWebhookResponse
+// @Failure 400 {object} dto.ValidationErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	var req dto.CreateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
+	}
+
+	webhookResponse, err := h.webhookService.CreateWebhook(c.Request().Context(), &req)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{Error: "Invalid webhook", Fields: validationErr.Fields})
+		}
+		h.logger.Error("Failed to create webhook", logger.ErrorField(err))
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to create webhook"})
+	}
+
+	return c.JSON(http.StatusCreated, webhookResponse)
+}
+
+// GetWebhookByID godoc
+// @Summary Get a webhook subscription by ID
+// @Description Get a single webhook subscription by its ID. The signing secret is not returned
+// @Tags webhooks
+// @Produce  json
+// @Param   id  path    int true    "Webhook ID"
+// @Success 200 {object} dto.WebhookResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhookByID(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid webhook ID"})
+	}
+
+	webhookResponse, err := h.webhookService.GetWebhookByID(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Webhook not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to get webhook"})
+	}
+
+	return c.JSON(http.StatusOK, webhookResponse)
+}
+
+// GetAllWebhooks godoc
+// @Summary Get all webhook subscriptions
+// @Description Get all webhook subscriptions. Signing secrets are not returned
+// @Tags webhooks
+// @Produce  json
+// @Success 200 {array} dto.WebhookResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetAllWebhooks(c echo.Context) error {
+	webhooks, err := h.webhookService.GetAllWebhooks(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Failed to get all webhooks", logger.ErrorField(err))
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to get webhooks"})
+	}
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook subscription
+// @Description Replace a webhook subscription. The signing secret is kept unless a new one is given
+// @Tags webhooks
+// @Accept  json
+// @Produce  json
+// @Param   id  path    int true    "Webhook ID"
+// @Param   webhook  body    dto.UpdateWebhookRequest   true    "Webhook to update"
+// @Success 200 {object} dto.WebhookResponse
+// @Failure 400 {object} dto.ValidationErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid webhook ID"})
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid request payload"})
+	}
+
+	webhookResponse, err := h.webhookService.UpdateWebhook(c.Request().Context(), uint(id), &req)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.JSON(http.StatusBadRequest, dto.ValidationErrorResponse{Error: "Invalid webhook", Fields: validationErr.Fields})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Webhook not found"})
+		}
+		h.logger.Error("Failed to update webhook", logger.ErrorField(err), logger.Field("webhook_id", id))
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to update webhook"})
+	}
+
+	return c.JSON(http.StatusOK, webhookResponse)
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook subscription
+// @Description Delete a webhook subscription together with its delivery log. Pending deliveries are dropped
+// @Tags webhooks
+// @Produce  json
+// @Param   id  path    int true    "Webhook ID"
+// @Success 204 {object} nil
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid webhook ID"})
+	}
+
+	if err := h.webhookService.DeleteWebhook(c.Request().Context(), uint(id)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Webhook not found"})
+		}
+		h.logger.Error("Failed to delete webhook", logger.ErrorField(err), logger.Field("webhook_id", id))
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to delete webhook"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List the deliveries of a webhook subscription
+// @Description List the delivery log of a webhook subscription, newest first, with the attempts, last response status and last error of each delivery
+// @Tags webhooks
+// @Produce  json
+// @Param   id      path    int     true    "Webhook ID"
+// @Param   status  query   string  false   "Filter by delivery status: pending, succeeded or failed"
+// @Param   limit   query   int     false   "Page size (default 50, max 500)"
+// @Success 200 {array} dto.WebhookDeliveryResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Failure 500 {object} dto.ErrorResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid webhook ID"})
+	}
+
+	var req dto.ListWebhookDeliveriesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": "Invalid query parameters"})
+	}
+
+	deliveries, err := h.webhookService.ListWebhookDeliveries(c.Request().Context(), uint(id), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, echo.Map{"error": "Webhook not found"})
+		}
+		h.logger.Error("Failed to list webhook deliveries", logger.ErrorField(err), logger.Field("webhook_id", id))
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": "Failed to get webhook deliveries"})
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
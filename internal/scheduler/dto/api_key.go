@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"database/sql"
+	"time"
+)
+
+// CreateAPIKeyRequest is the DTO for creating an API key.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`                 // identifies the caller in logs and job versions
+	Role      string     `json:"role"`                 // viewer, operator or admin
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // omit for a key that does not expire
+}
+
+// APIKeyResponse is the DTO for API responses containing an API key. The key itself is
+// only returned once, by CreateAPIKeyResponse.
+type APIKeyResponse struct {
+	ID         uint         `json:"id"`
+	Name       string       `json:"name"`
+	Role       string       `json:"role"`
+	Prefix     string       `json:"prefix"` // first characters of the key
+	CreatedBy  string       `json:"created_by"`
+	CreatedAt  time.Time    `json:"created_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at" swaggertype:"string" format:"date-time"`
+	ExpiresAt  sql.NullTime `json:"expires_at" swaggertype:"string" format:"date-time"`
+	RevokedAt  sql.NullTime `json:"revoked_at" swaggertype:"string" format:"date-time"`
+}
+
+// CreateAPIKeyResponse is returned when an API key is created.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"` // the API key; it is not stored and cannot be retrieved again
+}
@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetterStreamResponse describes the dead-letter stream of a stream.
+type DeadLetterStreamResponse struct {
+	Stream           string `json:"stream"`             // stream whose failed messages are kept, e.g. stock.analyzer
+	DeadLetterStream string `json:"dead_letter_stream"` // e.g. stock.analyzer.dlq
+	Count            int64  `json:"count"`
+}
+
+// ListDeadLettersRequest holds the query parameters for listing the dead letters of a stream.
+type ListDeadLettersRequest struct {
+	Limit  int    `query:"limit"`  // defaults to 50, at most 500
+	Cursor string `query:"cursor"` // next_cursor of the previous page
+}
+
+// DeadLetterResponse is a stream message that could not be processed.
+type DeadLetterResponse struct {
+	ID            string          `json:"id"`                           // ID in the dead-letter stream
+	Stream        string          `json:"stream"`                       // stream the message was read from
+	SourceID      string          `json:"source_id"`                    // ID the message had in its stream
+	Payload       json.RawMessage `json:"payload" swaggertype:"object"` // a string when the payload is not valid JSON
+	Error         string          `json:"error"`                        // error of the last attempt
+	Attempts      int64           `json:"attempts"`
+	FirstFailedAt time.Time       `json:"first_failed_at"`
+	LastFailedAt  time.Time       `json:"last_failed_at"`
+	Consumer      string          `json:"consumer"` // executor consumer that gave up on the message
+}
+
+// DeadLetterListResponse is the DTO for a page of dead letters, newest first.
+type DeadLetterListResponse struct {
+	Data       []*DeadLetterResponse `json:"data"`
+	Limit      int                   `json:"limit"`
+	NextCursor string                `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+// ReplayDeadLettersRequest is the DTO for replaying dead letters.
+type ReplayDeadLettersRequest struct {
+	IDs []string `json:"ids"` // dead-letter IDs, at most 500
+}
+
+// ReplayedDeadLetter is a dead letter that was added back to its stream.
+type ReplayedDeadLetter struct {
+	ID        string `json:"id"`         // former ID in the dead-letter stream
+	MessageID string `json:"message_id"` // ID of the new message in the stream
+}
+
+// ReplayDeadLettersResponse lists the outcome of a replay.
+type ReplayDeadLettersResponse struct {
+	Replayed []ReplayedDeadLetter `json:"replayed"`
+	NotFound []string             `json:"not_found"` // IDs that are not in the dead-letter stream, e.g. already replayed
+}
+
+// PurgeDeadLettersRequest holds the query parameters for purging dead letters.
+type PurgeDeadLettersRequest struct {
+	Before string `query:"before"` // only dead letters added before this RFC 3339 time; empty for all
+}
+
+// PurgeDeadLettersResponse is returned when dead letters are purged.
+type PurgeDeadLettersResponse struct {
+	Purged int64 `json:"purged"`
+}
@@ -4,3 +4,15 @@ package dto
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+// FieldError describes why a single request field is invalid.
+type FieldError struct {
+	Field   string `json:"field"` // JSON path of the field, e.g. payload.max_news or schedules[0].cron_expression
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is returned when one or more request fields are invalid.
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
+}
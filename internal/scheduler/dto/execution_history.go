@@ -1,16 +1,54 @@
 package dto
 
 import (
+	"database/sql"
 	"time"
 )
 
 // ExecutionHistoryResponse is the DTO for API responses containing execution history details.
 type ExecutionHistoryResponse struct {
-	ID         uint      `json:"id"`
-	JobID      uint      `json:"job_id"`
-	ScheduleID uint      `json:"schedule_id"`
-	Status     string    `json:"status"`
-	ExecutedAt time.Time `json:"executed_at"`
-	Duration   int64     `json:"duration_ms"`
-	Output     string    `json:"output"`
+	ID           uint         `json:"id"`
+	JobID        uint         `json:"job_id"`
+	ScheduleID   *uint        `json:"schedule_id"`
+	Status       string       `json:"status"`
+	ScheduledAt  sql.NullTime `json:"scheduled_at" swaggertype:"string" format:"date-time"`
+	ExecutedAt   time.Time    `json:"executed_at"`
+	CompletedAt  sql.NullTime `json:"completed_at" swaggertype:"string" format:"date-time"`
+	Duration     int64        `json:"duration_ms"`
+	Output       string       `json:"output"`
+	ErrorMessage string       `json:"error_message,omitempty"`
+	Attempt      int          `json:"attempt"`
+	RetryOfID    *uint        `json:"retry_of_id,omitempty"`
+	Trigger      string       `json:"trigger_type"`
+	HeartbeatAt  sql.NullTime `json:"heartbeat_at" swaggertype:"string" format:"date-time"` // last sign of life from the executor
+}
+
+// ListExecutionHistoriesRequest holds the query parameters for listing execution histories.
+type ListExecutionHistoriesRequest struct {
+	JobID         *uint  `query:"job_id"`
+	ScheduleID    *uint  `query:"schedule_id"`
+	Status        string `query:"status"`         // comma-separated statuses, e.g. "failed,timeout"
+	StartedAfter  string `query:"started_after"`  // RFC 3339, inclusive
+	StartedBefore string `query:"started_before"` // RFC 3339, exclusive
+	Sort          string `query:"sort"`           // "started_at" (default) or "id"
+	Order         string `query:"order"`          // "desc" (default) or "asc"
+	Limit         int    `query:"limit"`
+	Offset        int    `query:"offset"`
+	Cursor        string `query:"cursor"` // next_cursor from a previous page
+}
+
+// ExecutionHistoryListResponse is the DTO for a page of execution histories.
+type ExecutionHistoryListResponse struct {
+	Data       []*ExecutionHistoryResponse `json:"data"`
+	Total      int64                       `json:"total"` // number of executions matching the filters
+	Limit      int                         `json:"limit"`
+	Offset     int                         `json:"offset"`
+	NextCursor string                      `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+// ExecutionChainResponse is the DTO describing the dependency chain around an execution.
+type ExecutionChainResponse struct {
+	Execution  *ExecutionHistoryResponse   `json:"execution"`
+	Upstream   []*ExecutionHistoryResponse `json:"upstream"`   // executions that led to this one, nearest first
+	Downstream []*ExecutionHistoryResponse `json:"downstream"` // executions triggered by this one, level by level
 }
@@ -15,51 +15,90 @@ type RetryPolicyDTO struct {
 
 // ScheduleDTO represents a task schedule in API requests.
 type ScheduleDTO struct {
-	CronExpression string `json:"cron_expression"`
-	IsActive       bool   `json:"is_active"`
+	ID                  uint   `json:"id,omitempty"` // existing schedule to keep when updating a job
+	CronExpression      string `json:"cron_expression"`
+	IsActive            bool   `json:"is_active"`
+	Timezone            string `json:"timezone,omitempty"`       // IANA time zone, defaults to Asia/Jakarta
+	MisfirePolicy       string `json:"misfire_policy,omitempty"` // "run_once" (default), "skip" or "run_all"
+	MisfireGraceSeconds int    `json:"misfire_grace_seconds,omitempty"`
+	MisfireMaxRuns      int    `json:"misfire_max_runs,omitempty"`
 }
 
 // CreateJobRequest is the DTO for creating a new job.
 type CreateJobRequest struct {
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	Type        string          `json:"type"`
-	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
-	RetryPolicy RetryPolicyDTO  `json:"retry_policy"`
-	Timeout     int             `json:"timeout"` // in seconds
-	Schedules   []ScheduleDTO   `json:"schedules"`
+	Name              string          `json:"name"`
+	Description       string          `json:"description"`
+	Type              string          `json:"type"`
+	Payload           json.RawMessage `json:"payload" swaggertype:"object"`
+	RetryPolicy       RetryPolicyDTO  `json:"retry_policy"`
+	Timeout           int             `json:"timeout"`                      // in seconds
+	ConcurrencyPolicy string          `json:"concurrency_policy,omitempty"` // "allow" (default), "forbid", "replace" or "queue"
+	Schedules         []ScheduleDTO   `json:"schedules"`
+	DependsOn         []uint          `json:"depends_on"` // upstream job IDs that trigger this job on success
 }
 
 // UpdateJobRequest is the DTO for updating an existing job.
 type UpdateJobRequest struct {
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	Type        string          `json:"type"`
-	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
-	RetryPolicy RetryPolicyDTO  `json:"retry_policy"`
-	Timeout     int             `json:"timeout"` // in seconds
-	Schedules   []ScheduleDTO   `json:"schedules"`
+	Name              string          `json:"name"`
+	Description       string          `json:"description"`
+	Type              string          `json:"type"`
+	Payload           json.RawMessage `json:"payload" swaggertype:"object"`
+	RetryPolicy       RetryPolicyDTO  `json:"retry_policy"`
+	Timeout           int             `json:"timeout"`                      // in seconds
+	ConcurrencyPolicy string          `json:"concurrency_policy,omitempty"` // "allow" (default), "forbid", "replace" or "queue"
+	Schedules         []ScheduleDTO   `json:"schedules"`
+	DependsOn         []uint          `json:"depends_on"` // upstream job IDs that trigger this job on success
 }
 
 // ScheduleResponseDTO represents a task schedule in API responses.
 type ScheduleResponseDTO struct {
-	ID             uint         `json:"id"`
-	CronExpression string       `json:"cron_expression"`
-	IsActive       bool         `json:"is_active"`
-	NextExecution  sql.NullTime `json:"next_execution" swaggertype:"string" format:"date-time"`
-	LastExecution  sql.NullTime `json:"last_execution" swaggertype:"string" format:"date-time"`
+	ID                  uint         `json:"id"`
+	CronExpression      string       `json:"cron_expression"`
+	IsActive            bool         `json:"is_active"`
+	MisfirePolicy       string       `json:"misfire_policy"`
+	MisfireGraceSeconds int          `json:"misfire_grace_seconds"`
+	MisfireMaxRuns      int          `json:"misfire_max_runs"`
+	Timezone            string       `json:"timezone"`
+	NextExecution       sql.NullTime `json:"next_execution" swaggertype:"string" format:"date-time"`
+	LastExecution       sql.NullTime `json:"last_execution" swaggertype:"string" format:"date-time"`
+	Paused              bool         `json:"paused"`
+	PausedUntil         sql.NullTime `json:"paused_until" swaggertype:"string" format:"date-time"`
+	UpcomingExecutions  []time.Time  `json:"upcoming_executions"` // next fire times, leaving out runs while the job or schedule is paused
 }
 
 // JobResponse is the DTO for API responses containing job details.
 type JobResponse struct {
-	ID          uint                  `json:"id"`
-	Name        string                `json:"name"`
-	Description string                `json:"description"`
-	Type        string                `json:"type"`
-	Payload     json.RawMessage       `json:"payload" swaggertype:"object"`
-	RetryPolicy RetryPolicyDTO        `json:"retry_policy"`
-	Timeout     int                   `json:"timeout"`
-	Schedules   []ScheduleResponseDTO `json:"schedules"`
-	CreatedAt   time.Time             `json:"created_at"`
-	UpdatedAt   time.Time             `json:"updated_at"`
+	ID                uint                  `json:"id"`
+	Name              string                `json:"name"`
+	Description       string                `json:"description"`
+	Type              string                `json:"type"`
+	Payload           json.RawMessage       `json:"payload" swaggertype:"object"`
+	RetryPolicy       RetryPolicyDTO        `json:"retry_policy"`
+	Timeout           int                   `json:"timeout"`
+	ConcurrencyPolicy string                `json:"concurrency_policy"`
+	Schedules         []ScheduleResponseDTO `json:"schedules"`
+	DependsOn         []uint                `json:"depends_on"`
+	Paused            bool                  `json:"paused"`
+	PausedUntil       sql.NullTime          `json:"paused_until" swaggertype:"string" format:"date-time"`
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+}
+
+// TriggerJobRequest is the DTO for manually triggering a job.
+type TriggerJobRequest struct {
+	Payload json.RawMessage `json:"payload,omitempty" swaggertype:"object"` // optional one-off payload override
+}
+
+// TriggerJobResponse is the DTO returned after a job has been manually triggered.
+type TriggerJobResponse struct {
+	ExecutionID uint      `json:"execution_id"`
+	JobID       uint      `json:"job_id"`
+	Status      string    `json:"status"`
+	TriggerType string    `json:"trigger_type"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// PauseRequest is the DTO for pausing a job or a schedule.
+type PauseRequest struct {
+	Until *time.Time `json:"until,omitempty"` // resume automatically at this time; omit to pause until resumed
 }
@@ -0,0 +1,10 @@
+package dto
+
+import "golang-stock-scryper/internal/entity"
+
+// JobTypeResponse describes a job type and the JSON schema of its payload.
+type JobTypeResponse struct {
+	Type          string                `json:"type"`
+	Description   string                `json:"description"`
+	PayloadSchema *entity.PayloadSchema `json:"payload_schema" swaggertype:"object"`
+}
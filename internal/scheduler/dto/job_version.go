@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobVersionResponse is the DTO for API responses containing a version of a job.
+type JobVersionResponse struct {
+	JobID     uint            `json:"job_id"`
+	Version   int             `json:"version"`
+	Action    string          `json:"action"` // created, updated, deleted, rolled_back, schedule_created, schedule_updated or schedule_deleted
+	Actor     string          `json:"actor"`
+	CreatedAt time.Time       `json:"created_at"`
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"` // job definition before the change, omitted in lists
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`  // job definition after the change, omitted in lists
+}
+
+// DiffJobVersionsRequest holds the query parameters for comparing two versions of a job.
+type DiffJobVersionsRequest struct {
+	From int `query:"from"`
+	To   int `query:"to"`
+}
+
+// JobVersionDiffResponse lists the differences between the job definitions of two versions.
+type JobVersionDiffResponse struct {
+	JobID   uint            `json:"job_id"`
+	From    int             `json:"from"`
+	To      int             `json:"to"`
+	Changes []VersionChange `json:"changes"`
+}
+
+// VersionChange is a single field that differs between two versions of a job.
+type VersionChange struct {
+	Path string      `json:"path"` // e.g. payload.max_news or schedules[id=3].cron_expression; empty for the whole job
+	From interface{} `json:"from"` // null when the field was added
+	To   interface{} `json:"to"`   // null when the field was removed
+}
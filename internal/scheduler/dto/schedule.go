@@ -7,25 +7,55 @@ import (
 
 // CreateScheduleRequest defines the DTO for creating a new schedule.
 type CreateScheduleRequest struct {
-	JobID          uint   `json:"job_id"`
-	CronExpression string `json:"cron_expression"`
-	IsActive       bool   `json:"is_active"`
+	JobID               uint   `json:"job_id"`
+	CronExpression      string `json:"cron_expression"`
+	IsActive            bool   `json:"is_active"`
+	Timezone            string `json:"timezone,omitempty"`       // IANA time zone, defaults to Asia/Jakarta
+	MisfirePolicy       string `json:"misfire_policy,omitempty"` // "run_once" (default), "skip" or "run_all"
+	MisfireGraceSeconds int    `json:"misfire_grace_seconds,omitempty"`
+	MisfireMaxRuns      int    `json:"misfire_max_runs,omitempty"`
 }
 
 // UpdateScheduleRequest defines the DTO for updating an existing schedule.
 type UpdateScheduleRequest struct {
-	CronExpression string `json:"cron_expression"`
-	IsActive       bool   `json:"is_active"`
+	CronExpression      string `json:"cron_expression"`
+	IsActive            bool   `json:"is_active"`
+	Timezone            string `json:"timezone,omitempty"`       // IANA time zone, defaults to Asia/Jakarta
+	MisfirePolicy       string `json:"misfire_policy,omitempty"` // "run_once" (default), "skip" or "run_all"
+	MisfireGraceSeconds int    `json:"misfire_grace_seconds,omitempty"`
+	MisfireMaxRuns      int    `json:"misfire_max_runs,omitempty"`
 }
 
 // ScheduleResponse is the DTO for API responses containing schedule details.
 type ScheduleResponse struct {
-	ID             uint         `json:"id"`
-	JobID          uint         `json:"job_id"`
-	CronExpression string       `json:"cron_expression"`
-	IsActive       bool         `json:"is_active"`
-	NextExecution  sql.NullTime `json:"next_execution" swaggertype:"string" format:"date-time"`
-	LastExecution  sql.NullTime `json:"last_execution" swaggertype:"string" format:"date-time"`
-	CreatedAt      time.Time    `json:"created_at"`
-	UpdatedAt      time.Time    `json:"updated_at"`
+	ID                  uint         `json:"id"`
+	JobID               uint         `json:"job_id"`
+	CronExpression      string       `json:"cron_expression"`
+	IsActive            bool         `json:"is_active"`
+	MisfirePolicy       string       `json:"misfire_policy"`
+	MisfireGraceSeconds int          `json:"misfire_grace_seconds"`
+	MisfireMaxRuns      int          `json:"misfire_max_runs"`
+	Timezone            string       `json:"timezone"`
+	NextExecution       sql.NullTime `json:"next_execution" swaggertype:"string" format:"date-time"`
+	LastExecution       sql.NullTime `json:"last_execution" swaggertype:"string" format:"date-time"`
+	Paused              bool         `json:"paused"`
+	PausedUntil         sql.NullTime `json:"paused_until" swaggertype:"string" format:"date-time"`
+	UpcomingExecutions  []time.Time  `json:"upcoming_executions"` // next fire times, leaving out runs while the schedule is paused
+	CreatedAt           time.Time    `json:"created_at"`
+	UpdatedAt           time.Time    `json:"updated_at"`
+}
+
+// PreviewScheduleRequest defines the DTO for previewing the fire times of a cron expression.
+type PreviewScheduleRequest struct {
+	CronExpression string     `json:"cron_expression"`
+	Timezone       string     `json:"timezone,omitempty"` // IANA time zone, defaults to Asia/Jakarta
+	Count          int        `json:"count,omitempty"`    // number of fire times, defaults to 10, at most 100
+	From           *time.Time `json:"from,omitempty"`     // list fire times after this time, defaults to now
+}
+
+// PreviewScheduleResponse lists the next fire times of a cron expression in its time zone.
+type PreviewScheduleResponse struct {
+	CronExpression string      `json:"cron_expression"`
+	Timezone       string      `json:"timezone"`
+	FireTimes      []time.Time `json:"fire_times"`
 }
@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// CreateWebhookRequest is the DTO for creating a webhook subscription.
+type CreateWebhookRequest struct {
+	Name        string          `json:"name"`
+	URL         string          `json:"url"`                    // http or https URL the events are POSTed to
+	Secret      string          `json:"secret,omitempty"`       // signs every event; generated when omitted
+	JobID       *uint           `json:"job_id,omitempty"`       // job whose executions are sent; omit for every job
+	Statuses    []string        `json:"statuses"`               // execution statuses to send events for; empty for every status
+	RetryPolicy *RetryPolicyDTO `json:"retry_policy,omitempty"` // retries of failed deliveries; 5 exponential retries from 30s when omitted
+	IsActive    *bool           `json:"is_active,omitempty"`    // defaults to true
+}
+
+// UpdateWebhookRequest is the DTO for updating a webhook subscription. It replaces the
+// subscription, except for the secret, which is kept when omitted.
+type UpdateWebhookRequest struct {
+	Name        string          `json:"name"`
+	URL         string          `json:"url"`
+	Secret      string          `json:"secret,omitempty"`
+	JobID       *uint           `json:"job_id,omitempty"`
+	Statuses    []string        `json:"statuses"`
+	RetryPolicy *RetryPolicyDTO `json:"retry_policy,omitempty"`
+	IsActive    *bool           `json:"is_active,omitempty"`
+}
+
+// WebhookResponse is the DTO for API responses containing a webhook subscription. The secret
+// is only returned by CreateWebhookResponse.
+type WebhookResponse struct {
+	ID          uint           `json:"id"`
+	Name        string         `json:"name"`
+	URL         string         `json:"url"`
+	JobID       *uint          `json:"job_id"`
+	Statuses    []string       `json:"statuses"`
+	RetryPolicy RetryPolicyDTO `json:"retry_policy"`
+	IsActive    bool           `json:"is_active"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// CreateWebhookResponse is returned when a webhook subscription is created.
+type CreateWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"` // key of the X-Webhook-Signature HMAC; it cannot be retrieved again
+}
+
+// ListWebhookDeliveriesRequest holds the query parameters for listing the deliveries of a webhook subscription.
+type ListWebhookDeliveriesRequest struct {
+	Status string `query:"status"` // pending, succeeded or failed; empty for every status
+	Limit  int    `query:"limit"`  // defaults to 50, at most 500
+}
+
+// WebhookDeliveryResponse is an entry of the delivery log of a webhook subscription.
+type WebhookDeliveryResponse struct {
+	ID             uint            `json:"id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	ExecutionID    uint            `json:"execution_id"`
+	JobID          uint            `json:"job_id"`
+	Status         string          `json:"status"` // pending, succeeded or failed
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"` // set while the delivery is pending
+	LastAttemptAt  sql.NullTime    `json:"last_attempt_at" swaggertype:"string" format:"date-time"`
+	ResponseStatus int             `json:"response_status,omitempty"` // HTTP status of the last attempt
+	LastError      string          `json:"last_error,omitempty"`
+	DeliveredAt    sql.NullTime    `json:"delivered_at" swaggertype:"string" format:"date-time"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+}
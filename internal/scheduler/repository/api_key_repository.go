@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository defines the interface for API key data operations. Keys are revoked rather
+// than deleted, so the callers named in logs and job versions can still be looked up.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entity.APIKey) error
+	FindAll(ctx context.Context) ([]entity.APIKey, error)
+	FindByID(ctx context.Context, id uint) (*entity.APIKey, error)
+	FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	Revoke(ctx context.Context, id uint, at time.Time) error
+	TouchLastUsed(ctx context.Context, id uint, at time.Time, every time.Duration) error
+}
+
+// NewAPIKeyRepository creates a new GORM-based API key repository.
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new API key record.
+func (r *apiKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// FindAll retrieves every API key, including revoked ones, oldest first.
+func (r *apiKeyRepository) FindAll(ctx context.Context) ([]entity.APIKey, error) {
+	var keys []entity.APIKey
+	if err := r.db.WithContext(ctx).Order("id asc").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// FindByID retrieves an API key by its ID.
+func (r *apiKeyRepository) FindByID(ctx context.Context, id uint) (*entity.APIKey, error) {
+	var key entity.APIKey
+	if err := r.db.WithContext(ctx).First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindByHash retrieves the API key with the given SHA-256 hash.
+func (r *apiKeyRepository) FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	var key entity.APIKey
+	if err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Revoke marks an API key as revoked. Revoking a key that is already revoked keeps the
+// original revocation time. It returns gorm.ErrRecordNotFound when the key does not exist.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uint, at time.Time) error {
+	if _, err := r.FindByID(ctx, id); err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Model(&entity.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+// TouchLastUsed records that an API key was used. The row is written at most once per every,
+// so busy keys do not cause a write on each request.
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time, every time.Duration) error {
+	return r.db.WithContext(ctx).Model(&entity.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-every)).
+		Update("last_used_at", at).Error
+}
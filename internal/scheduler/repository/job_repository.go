@@ -2,11 +2,11 @@ package repository
 
 import (
 	"context"
-	"time"
 
 	"golang-stock-scryper/internal/entity"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // JobRepository defines the interface for job data operations.
@@ -14,8 +14,10 @@ type JobRepository interface {
 	Create(ctx context.Context, job *entity.Job) error
 	FindByID(ctx context.Context, id uint) (*entity.Job, error)
 	FindAll(ctx context.Context) ([]entity.Job, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]entity.Job, error)
+	FindAllDependencies(ctx context.Context) ([]entity.JobDependency, error)
 	Update(ctx context.Context, job *entity.Job) error
-	FindJobsToSchedule(ctx context.Context) ([]entity.Job, error)
+	UpdatePause(ctx context.Context, id uint, pause entity.PauseState) error
 	Delete(ctx context.Context, id uint) error
 }
 
@@ -36,7 +38,7 @@ func (r *jobRepository) Create(ctx context.Context, job *entity.Job) error {
 // FindByID retrieves a job by its ID.
 func (r *jobRepository) FindByID(ctx context.Context, id uint) (*entity.Job, error) {
 	var job entity.Job
-	if err := r.db.WithContext(ctx).Preload("Schedules").First(&job, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Schedules").Preload("Dependencies").First(&job, id).Error; err != nil {
 		return nil, err
 	}
 	return &job, nil
@@ -45,41 +47,99 @@ func (r *jobRepository) FindByID(ctx context.Context, id uint) (*entity.Job, err
 // FindAll retrieves all jobs.
 func (r *jobRepository) FindAll(ctx context.Context) ([]entity.Job, error) {
 	var jobs []entity.Job
-	if err := r.db.WithContext(ctx).Preload("Schedules").Find(&jobs).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Schedules").Preload("Dependencies").Find(&jobs).Error; err != nil {
 		return nil, err
 	}
 	return jobs, nil
 }
 
-// Update updates an existing job and its associated schedules within a transaction.
+// FindByIDs retrieves the jobs with the given IDs. Unknown IDs are ignored.
+func (r *jobRepository) FindByIDs(ctx context.Context, ids []uint) ([]entity.Job, error) {
+	var jobs []entity.Job
+	if len(ids) == 0 {
+		return jobs, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// FindAllDependencies retrieves every job dependency edge.
+func (r *jobRepository) FindAllDependencies(ctx context.Context) ([]entity.JobDependency, error) {
+	var dependencies []entity.JobDependency
+	if err := r.db.WithContext(ctx).Find(&dependencies).Error; err != nil {
+		return nil, err
+	}
+	return dependencies, nil
+}
+
+// Update updates an existing job, its schedules and its dependencies within a transaction.
+// Schedules with an ID are updated in place, so they keep their execution times, pause state
+// and history; schedules without an ID are created and the job's other schedules are deleted.
+// Only the configuration columns of kept schedules are written, since the scheduler updates
+// their execution times concurrently.
 func (r *jobRepository) Update(ctx context.Context, job *entity.Job) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// First, remove all existing schedules for this job to ensure a clean update.
-		if err := tx.Where("job_id = ?", job.ID).Delete(&entity.TaskSchedule{}).Error; err != nil {
+		if err := tx.Omit(clause.Associations).Save(job).Error; err != nil {
 			return err
 		}
 
-		// Now, save the job. GORM's Save method will update the job record
-		// and create the new schedule records from the job.Schedules slice.
-		return tx.Save(job).Error
+		keptIDs := []uint{0}
+		for _, schedule := range job.Schedules {
+			if schedule.ID != 0 {
+				keptIDs = append(keptIDs, schedule.ID)
+			}
+		}
+		if err := tx.Where("job_id = ? AND id NOT IN ?", job.ID, keptIDs).Delete(&entity.TaskSchedule{}).Error; err != nil {
+			return err
+		}
+		for i := range job.Schedules {
+			schedule := &job.Schedules[i]
+			schedule.JobID = job.ID
+			if schedule.ID == 0 {
+				if err := tx.Create(schedule).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			// The next execution computed by the caller only replaces the stored one when the
+			// schedule's timing changes.
+			err := tx.Model(&entity.TaskSchedule{}).
+				Where("id = ? AND job_id = ? AND (cron_expression <> ? OR timezone <> ?)", schedule.ID, job.ID, schedule.CronExpression, schedule.Timezone).
+				Update("next_execution", schedule.NextExecution).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(schedule).
+				Select("cron_expression", "is_active", "timezone", "misfire_policy", "misfire_grace_seconds", "misfire_max_runs").
+				Updates(schedule).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("job_id = ?", job.ID).Delete(&entity.JobDependency{}).Error; err != nil {
+			return err
+		}
+		if len(job.Dependencies) == 0 {
+			return nil
+		}
+		return tx.Create(&job.Dependencies).Error
 	})
 }
 
-// FindJobsToSchedule finds all active jobs with schedules that need to be run.
-func (r *jobRepository) FindJobsToSchedule(ctx context.Context) ([]entity.Job, error) {
-	var jobs []entity.Job
-	now := time.Now()
-	// Find jobs with active schedules that are due
-	err := r.db.WithContext(ctx).
-		Preload("Schedules", "is_active = ?", true).
-		Joins("JOIN task_schedules ts ON ts.job_id = jobs.id").
-		Where("ts.is_active = ? AND (ts.next_execution IS NULL OR ts.next_execution <= ?)", true, now).
-		Group("jobs.id").
-		Find(&jobs).Error
-	if err != nil {
-		return nil, err
+// UpdatePause pauses or resumes a job without touching its other columns.
+func (r *jobRepository) UpdatePause(ctx context.Context, id uint, pause entity.PauseState) error {
+	result := r.db.WithContext(ctx).Model(&entity.Job{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"paused": pause.Paused, "paused_until": pause.PausedUntil})
+	if result.Error != nil {
+		return result.Error
 	}
-	return jobs, nil
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 // Delete removes a job and its associated schedules and history.
@@ -91,6 +151,9 @@ func (r *jobRepository) Delete(ctx context.Context, id uint) error {
 		if err := tx.Where("job_id = ?", id).Delete(&entity.TaskSchedule{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("job_id = ? OR depends_on_job_id = ?", id, id).Delete(&entity.JobDependency{}).Error; err != nil {
+			return err
+		}
 		if err := tx.Delete(&entity.Job{}, id).Error; err != nil {
 			return err
 		}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"golang-stock-scryper/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// JobVersionRepository defines the interface for job version data operations. Versions are
+// immutable, so there are no update or delete operations.
+type JobVersionRepository interface {
+	Create(ctx context.Context, version *entity.JobVersion) error
+	FindAllByJobID(ctx context.Context, jobID uint) ([]entity.JobVersion, error)
+	FindByJobIDAndVersion(ctx context.Context, jobID uint, version int) (*entity.JobVersion, error)
+}
+
+// jobVersionLockNamespace is the first key of the advisory locks that serialise version numbering.
+const jobVersionLockNamespace = 1002
+
+// NewJobVersionRepository creates a new GORM-based job version repository.
+func NewJobVersionRepository(db *gorm.DB) JobVersionRepository {
+	return &jobVersionRepository{db: db}
+}
+
+type jobVersionRepository struct {
+	db *gorm.DB
+}
+
+// Create records a new version of a job and sets its version number to the next one of the job.
+// It must run inside a transaction, which holds the lock on the job's numbering until it ends.
+func (r *jobVersionRepository) Create(ctx context.Context, version *entity.JobVersion) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Exec("SELECT pg_advisory_xact_lock(?, ?)", jobVersionLockNamespace, int32(version.JobID)).Error; err != nil {
+		return err
+	}
+
+	var latest int
+	err := db.Model(&entity.JobVersion{}).
+		Where("job_id = ?", version.JobID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+	version.Version = latest + 1
+	return db.Create(version).Error
+}
+
+// FindAllByJobID retrieves every version of a job, newest first.
+func (r *jobVersionRepository) FindAllByJobID(ctx context.Context, jobID uint) ([]entity.JobVersion, error) {
+	var versions []entity.JobVersion
+	if err := r.db.WithContext(ctx).Where("job_id = ?", jobID).Order("version desc").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// FindByJobIDAndVersion retrieves a single version of a job.
+func (r *jobVersionRepository) FindByJobIDAndVersion(ctx context.Context, jobID uint, version int) (*entity.JobVersion, error) {
+	var jobVersion entity.JobVersion
+	if err := r.db.WithContext(ctx).Where("job_id = ? AND version = ?", jobID, version).First(&jobVersion).Error; err != nil {
+		return nil, err
+	}
+	return &jobVersion, nil
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"time"
 
 	"golang-stock-scryper/internal/entity"
 
@@ -12,9 +15,39 @@ import (
 type TaskExecutionHistoryRepository interface {
 	Create(ctx context.Context, history *entity.TaskExecutionHistory) error
 	FindByID(ctx context.Context, id uint) (*entity.TaskExecutionHistory, error)
-	FindAll(ctx context.Context) ([]entity.TaskExecutionHistory, error)
-	FindAllByJobID(ctx context.Context, jobID uint) ([]entity.TaskExecutionHistory, error)
+	FindAllByFilter(ctx context.Context, filter ExecutionHistoryFilter) ([]entity.TaskExecutionHistory, int64, error)
+	FindAllByTriggeredByIDs(ctx context.Context, ids []uint) ([]entity.TaskExecutionHistory, error)
 	Update(ctx context.Context, history *entity.TaskExecutionHistory) error
+	CancelQueued(ctx context.Context, history *entity.TaskExecutionHistory) (bool, error)
+	ReapTimedOut(ctx context.Context, now time.Time, grace time.Duration) ([]entity.TaskExecutionHistory, error)
+	ReapLost(ctx context.Context, now time.Time, heartbeatTimeout, queuedTimeout time.Duration) ([]entity.TaskExecutionHistory, error)
+}
+
+// Sort columns supported by FindAllByFilter.
+const (
+	ExecutionSortStartedAt = "started_at"
+	ExecutionSortID        = "id"
+)
+
+// ExecutionHistoryFilter narrows and pages a task execution history query.
+// Zero values mean "no filter".
+type ExecutionHistoryFilter struct {
+	JobID         *uint
+	ScheduleID    *uint
+	Statuses      []entity.TaskExecutionStatus
+	StartedAfter  *time.Time
+	StartedBefore *time.Time
+	SortBy        string // ExecutionSortStartedAt (default) or ExecutionSortID
+	Descending    bool
+	Limit         int
+	Offset        int                     // ignored when After is set
+	After         *ExecutionHistoryCursor // keyset position; results start after this row
+}
+
+// ExecutionHistoryCursor identifies the last row of a previous page for keyset pagination.
+type ExecutionHistoryCursor struct {
+	StartedAt time.Time
+	ID        uint
 }
 
 // NewTaskExecutionHistoryRepository creates a new GORM-based task execution history repository.
@@ -40,19 +73,69 @@ func (r *taskExecutionHistoryRepository) FindByID(ctx context.Context, id uint)
 	return &history, nil
 }
 
-// FindAll retrieves all task execution history records.
-func (r *taskExecutionHistoryRepository) FindAll(ctx context.Context) ([]entity.TaskExecutionHistory, error) {
+// FindAllByFilter retrieves a page of task execution history records matching the filter,
+// together with the total number of matching records ignoring pagination.
+func (r *taskExecutionHistoryRepository) FindAllByFilter(ctx context.Context, filter ExecutionHistoryFilter) ([]entity.TaskExecutionHistory, int64, error) {
+	query := r.db.WithContext(ctx).Model(&entity.TaskExecutionHistory{})
+	if filter.JobID != nil {
+		query = query.Where("job_id = ?", *filter.JobID)
+	}
+	if filter.ScheduleID != nil {
+		query = query.Where("schedule_id = ?", *filter.ScheduleID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.StartedAfter != nil {
+		query = query.Where("started_at >= ?", *filter.StartedAfter)
+	}
+	if filter.StartedBefore != nil {
+		query = query.Where("started_at < ?", *filter.StartedBefore)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	direction, comparator := "ASC", ">"
+	if filter.Descending {
+		direction, comparator = "DESC", "<"
+	}
+
+	page := query
+	if filter.SortBy == ExecutionSortID {
+		if filter.After != nil {
+			page = page.Where("id "+comparator+" ?", filter.After.ID)
+		}
+		page = page.Order("id " + direction)
+	} else {
+		if filter.After != nil {
+			page = page.Where("(started_at, id) "+comparator+" (?, ?)", filter.After.StartedAt, filter.After.ID)
+		}
+		page = page.Order(fmt.Sprintf("started_at %s, id %s", direction, direction))
+	}
+
+	page = page.Limit(filter.Limit)
+	if filter.After == nil {
+		page = page.Offset(filter.Offset)
+	}
+
 	var histories []entity.TaskExecutionHistory
-	if err := r.db.WithContext(ctx).Order("executed_at desc").Find(&histories).Error; err != nil {
-		return nil, err
+	if err := page.Find(&histories).Error; err != nil {
+		return nil, 0, err
 	}
-	return histories, nil
+	return histories, total, nil
 }
 
-// FindAllByJobID retrieves all task execution history records for a specific job.
-func (r *taskExecutionHistoryRepository) FindAllByJobID(ctx context.Context, jobID uint) ([]entity.TaskExecutionHistory, error) {
+// FindAllByTriggeredByIDs retrieves the executions triggered by any of the given upstream executions.
+func (r *taskExecutionHistoryRepository) FindAllByTriggeredByIDs(ctx context.Context, ids []uint) ([]entity.TaskExecutionHistory, error) {
 	var histories []entity.TaskExecutionHistory
-	if err := r.db.WithContext(ctx).Where("job_id = ?", jobID).Order("executed_at desc").Find(&histories).Error; err != nil {
+	if len(ids) == 0 {
+		return histories, nil
+	}
+	if err := r.db.WithContext(ctx).Where("triggered_by_id IN ?", ids).Order("started_at asc").Find(&histories).Error; err != nil {
 		return nil, err
 	}
 	return histories, nil
@@ -62,3 +145,62 @@ func (r *taskExecutionHistoryRepository) FindAllByJobID(ctx context.Context, job
 func (r *taskExecutionHistoryRepository) Update(ctx context.Context, history *entity.TaskExecutionHistory) error {
 	return r.db.WithContext(ctx).Updates(history).Error
 }
+
+// CancelQueued marks an execution that has not started running yet as cancelled and reports
+// whether it did. Executions an executor has already started are left alone.
+func (r *taskExecutionHistoryRepository) CancelQueued(ctx context.Context, history *entity.TaskExecutionHistory) (bool, error) {
+	now := time.Now()
+	message := "execution cancelled"
+	result := r.db.WithContext(ctx).Model(&entity.TaskExecutionHistory{}).
+		Where("id = ? AND status = ?", history.ID, entity.StatusQueued).
+		Updates(map[string]interface{}{"status": entity.StatusCancelled, "completed_at": now, "error_message": message})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	history.Status = entity.StatusCancelled
+	history.CompletedAt = sql.NullTime{Time: now, Valid: true}
+	history.ErrorMessage = sql.NullString{String: message, Valid: true}
+	return true, nil
+}
+
+// ReapTimedOut marks running executions that started more than their job's timeout plus grace
+// before now as timed out, and returns the executions it marked. Jobs without a timeout are ignored.
+func (r *taskExecutionHistoryRepository) ReapTimedOut(ctx context.Context, now time.Time, grace time.Duration) ([]entity.TaskExecutionHistory, error) {
+	var histories []entity.TaskExecutionHistory
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE task_execution_history AS h
+		SET status = ?, completed_at = ?, error_message = ?
+		FROM jobs AS j
+		WHERE h.job_id = j.id
+			AND h.status = ?
+			AND j.timeout > 0
+			AND h.started_at + make_interval(secs => j.timeout) < ?
+		RETURNING h.*`,
+		entity.StatusTimeout, now, "reaped: execution exceeded the job timeout", entity.StatusRunning, now.Add(-grace)).
+		Scan(&histories).Error
+	return histories, err
+}
+
+// ReapLost marks executions whose executor stopped sending heartbeats for longer than
+// heartbeatTimeout, and queued executions that no executor picked up within queuedTimeout,
+// as lost. It returns the executions it marked. Running executions without a heartbeat were
+// started by an executor that does not send them and are only reaped by ReapTimedOut.
+func (r *taskExecutionHistoryRepository) ReapLost(ctx context.Context, now time.Time, heartbeatTimeout, queuedTimeout time.Duration) ([]entity.TaskExecutionHistory, error) {
+	var histories []entity.TaskExecutionHistory
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE task_execution_history
+		SET status = ?, completed_at = ?,
+			error_message = CASE WHEN heartbeat_at IS NULL THEN ? ELSE ? END
+		WHERE (status IN ? AND heartbeat_at < ?)
+			OR (status = ? AND heartbeat_at IS NULL AND started_at < ?)
+		RETURNING *`,
+		entity.StatusLost, now,
+		"reaped: no executor picked up the execution", "reaped: the executor stopped sending heartbeats",
+		[]entity.TaskExecutionStatus{entity.StatusQueued, entity.StatusRunning}, now.Add(-heartbeatTimeout),
+		entity.StatusQueued, now.Add(-queuedTimeout)).
+		Scan(&histories).Error
+	return histories, err
+}
@@ -2,11 +2,12 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"golang-stock-scryper/internal/entity"
-	"golang-stock-scryper/pkg/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TaskScheduleRepository defines the interface for task schedule data operations.
@@ -16,7 +17,8 @@ type TaskScheduleRepository interface {
 	FindAll(ctx context.Context) ([]entity.TaskSchedule, error)
 	Update(ctx context.Context, schedule *entity.TaskSchedule) error
 	Delete(ctx context.Context, id uint) error
-	FindJobsToSchedule(ctx context.Context) ([]entity.TaskSchedule, error)
+	UpdatePause(ctx context.Context, id uint, pause entity.PauseState) error
+	ClaimJobsToSchedule(ctx context.Context, now time.Time, limit int, process func(schedule *entity.TaskSchedule, jobPaused bool)) ([]entity.TaskSchedule, error)
 }
 
 // NewTaskScheduleRepository creates a new GORM-based task schedule repository.
@@ -56,20 +58,75 @@ func (r *taskScheduleRepository) Update(ctx context.Context, schedule *entity.Ta
 	return r.db.WithContext(ctx).Save(schedule).Error
 }
 
+// UpdatePause pauses or resumes a task schedule without touching its other columns, which
+// the scheduler may be updating at the same time.
+func (r *taskScheduleRepository) UpdatePause(ctx context.Context, id uint, pause entity.PauseState) error {
+	result := r.db.WithContext(ctx).Model(&entity.TaskSchedule{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"paused": pause.Paused, "paused_until": pause.PausedUntil})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // Delete removes a task schedule by its ID.
 func (r *taskScheduleRepository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&entity.TaskSchedule{}, id).Error
 }
 
-// FindJobsToSchedule find all active jobs with schedules that need to be run
-func (r *taskScheduleRepository) FindJobsToSchedule(ctx context.Context) ([]entity.TaskSchedule, error) {
-	var schedules []entity.TaskSchedule
-	// Find jobs with active schedules that are due
-	err := r.db.WithContext(ctx).
-		Where("is_active = ? AND (next_execution IS NULL OR next_execution <= ?)", true, utils.TimeNowWIB()).
-		Find(&schedules).Error
+// ClaimJobsToSchedule atomically claims active schedules that are due to run at now.
+// Due rows are locked with FOR NO KEY UPDATE SKIP LOCKED, so concurrent scheduler instances
+// never claim the same schedule, while execution history rows referencing a claimed schedule
+// can still be inserted from other connections. For each claimed schedule, process is called
+// to publish its runs and move its execution times forward, and the result is saved before
+// the transaction commits. jobPaused tells whether the schedule's job is paused at now.
+func (r *taskScheduleRepository) ClaimJobsToSchedule(ctx context.Context, now time.Time, limit int, process func(schedule *entity.TaskSchedule, jobPaused bool)) ([]entity.TaskSchedule, error) {
+	var claimed []entity.TaskSchedule
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var schedules []entity.TaskSchedule
+		// Find jobs with active schedules that are due
+		err := tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE", Options: "SKIP LOCKED"}).
+			Where("is_active = ? AND (next_execution IS NULL OR next_execution <= ?)", true, now).
+			Order("next_execution ASC NULLS FIRST").
+			Limit(limit).
+			Find(&schedules).Error
+		if err != nil {
+			return err
+		}
+		if len(schedules) == 0 {
+			return nil
+		}
+
+		jobIDs := make([]uint, 0, len(schedules))
+		for _, schedule := range schedules {
+			jobIDs = append(jobIDs, schedule.JobID)
+		}
+		var pausedJobIDs []uint
+		err = tx.Model(&entity.Job{}).
+			Where("id IN ? AND paused AND (paused_until IS NULL OR paused_until > ?)", jobIDs, now).
+			Pluck("id", &pausedJobIDs).Error
+		if err != nil {
+			return err
+		}
+		pausedJobs := make(map[uint]bool, len(pausedJobIDs))
+		for _, id := range pausedJobIDs {
+			pausedJobs[id] = true
+		}
+
+		for i := range schedules {
+			process(&schedules[i], pausedJobs[schedules[i].JobID])
+			if err := tx.Save(&schedules[i]).Error; err != nil {
+				return err
+			}
+			claimed = append(claimed, schedules[i])
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return schedules, nil
+	return claimed, nil
 }
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Repositories bundles the repositories that are bound to the same database transaction.
+type Repositories struct {
+	Jobs      JobRepository
+	Schedules TaskScheduleRepository
+	Versions  JobVersionRepository
+}
+
+// TransactionManager runs changes that span several repositories atomically.
+type TransactionManager interface {
+	WithTransaction(ctx context.Context, fn func(repos Repositories) error) error
+}
+
+// NewTransactionManager creates a new GORM-based transaction manager.
+func NewTransactionManager(db *gorm.DB) TransactionManager {
+	return &transactionManager{db: db}
+}
+
+type transactionManager struct {
+	db *gorm.DB
+}
+
+// WithTransaction runs fn in a transaction with repositories bound to it. The transaction is
+// rolled back when fn returns an error.
+func (m *transactionManager) WithTransaction(ctx context.Context, fn func(repos Repositories) error) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(Repositories{
+			Jobs:      NewJobRepository(tx),
+			Schedules: NewTaskScheduleRepository(tx),
+			Versions:  NewJobVersionRepository(tx),
+		})
+	})
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// WebhookDeliveryFilter narrows the delivery log of a webhook subscription.
+type WebhookDeliveryFilter struct {
+	Status entity.WebhookDeliveryStatus // empty for every status
+	Limit  int
+}
+
+// WebhookRepository defines the interface for webhook subscription and delivery data operations.
+type WebhookRepository interface {
+	Create(ctx context.Context, subscription *entity.WebhookSubscription) error
+	FindAll(ctx context.Context) ([]entity.WebhookSubscription, error)
+	FindByID(ctx context.Context, id uint) (*entity.WebhookSubscription, error)
+	Update(ctx context.Context, subscription *entity.WebhookSubscription) error
+	Delete(ctx context.Context, id uint) error
+	FindDeliveries(ctx context.Context, subscriptionID uint, filter WebhookDeliveryFilter) ([]entity.WebhookDelivery, error)
+	EnqueueExecutionEvent(ctx context.Context, event entity.ExecutionEvent) error
+}
+
+// NewWebhookRepository creates a new GORM-based webhook repository.
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// Create creates a new webhook subscription.
+func (r *webhookRepository) Create(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+// FindAll retrieves every webhook subscription, oldest first.
+func (r *webhookRepository) FindAll(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	var subscriptions []entity.WebhookSubscription
+	if err := r.db.WithContext(ctx).Order("id asc").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// FindByID retrieves a webhook subscription by its ID.
+func (r *webhookRepository) FindByID(ctx context.Context, id uint) (*entity.WebhookSubscription, error) {
+	var subscription entity.WebhookSubscription
+	if err := r.db.WithContext(ctx).First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Update updates an existing webhook subscription.
+func (r *webhookRepository) Update(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Save(subscription).Error
+}
+
+// Delete deletes a webhook subscription together with its delivery log.
+func (r *webhookRepository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&entity.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindDeliveries retrieves the deliveries of a webhook subscription, newest first.
+func (r *webhookRepository) FindDeliveries(ctx context.Context, subscriptionID uint, filter WebhookDeliveryFilter) ([]entity.WebhookDelivery, error) {
+	query := r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	var deliveries []entity.WebhookDelivery
+	if err := query.Order("id desc").Limit(filter.Limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// EnqueueExecutionEvent records a pending delivery of the event for every active subscription
+// of the execution's job, or of every job, that asks for the execution's status. An event that
+// was already enqueued for a subscription is not enqueued again.
+func (r *webhookRepository) EnqueueExecutionEvent(ctx context.Context, event entity.ExecutionEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO webhook_deliveries
+			(subscription_id, event_id, event_type, execution_id, job_id, payload, status, attempts, next_attempt_at, created_at)
+		SELECT id, ?, ?, ?, ?, ?, ?, 0, ?, ?
+		FROM webhook_subscriptions
+		WHERE is_active
+			AND (job_id IS NULL OR job_id = ?)
+			AND (jsonb_array_length(statuses) = 0 OR statuses @> jsonb_build_array(?::text))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+		event.ID, event.Type, event.Execution.ID, event.Execution.JobID, string(payload), entity.WebhookDeliveryPending, now, now,
+		event.Execution.JobID, string(event.Execution.Status),
+	).Error
+}
@@ -0,0 +1,21 @@
+package service
+
+import "context"
+
+// DefaultActor is recorded as the author of changes made by callers that did not identify themselves.
+const DefaultActor = "anonymous"
+
+type actorContextKey struct{}
+
+// WithActor returns a context that records actor as the author of the changes made with it.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the author recorded by WithActor, or DefaultActor.
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorContextKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return DefaultActor
+}
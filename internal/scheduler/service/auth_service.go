@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/config"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/internal/scheduler/repository"
+	"golang-stock-scryper/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// AuthMethodAPIKey and AuthMethodJWT name how a principal was authenticated.
+	AuthMethodAPIKey = "api_key"
+	AuthMethodJWT    = "jwt"
+	// AuthMethodNone is used for every caller when authentication is disabled.
+	AuthMethodNone = "none"
+
+	// apiKeyPrefix starts every API key, so leaked keys are easy to recognise.
+	apiKeyPrefix = "jsk_"
+	// apiKeyDisplayLength is the number of leading characters of a key that are stored and shown.
+	apiKeyDisplayLength = 12
+	// maxAPIKeyNameLength matches the size of the name column of API keys.
+	maxAPIKeyNameLength = 100
+	// apiKeyTouchInterval limits how often the last use of a key is written.
+	apiKeyTouchInterval = time.Minute
+)
+
+// ErrUnauthenticated is returned when a credential is missing, unknown, revoked or expired.
+var ErrUnauthenticated = errors.New("invalid or expired credentials")
+
+// Principal is an authenticated caller of the scheduling API.
+type Principal struct {
+	Name   string // recorded as the actor of the caller's changes
+	Role   entity.Role
+	Method string // api_key, jwt or none
+	KeyID  uint   // ID of the API key, 0 for other methods
+}
+
+// AuthService defines the interface for authenticating API callers and managing API keys.
+type AuthService interface {
+	Authenticate(ctx context.Context, credential string) (*Principal, error)
+	CreateAPIKey(ctx context.Context, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context) ([]*dto.APIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, id uint) error
+}
+
+// NewAuthService creates a new auth service.
+func NewAuthService(apiKeyRepo repository.APIKeyRepository, cfg config.Auth, logger *logger.Logger) AuthService {
+	return &authService{
+		apiKeyRepo: apiKeyRepo,
+		jwtSecret:  []byte(cfg.JWTSecret),
+		jwtIssuer:  cfg.JWTIssuer,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+type authService struct {
+	apiKeyRepo repository.APIKeyRepository
+	jwtSecret  []byte
+	jwtIssuer  string
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+// Authenticate resolves a credential to the caller it belongs to. Credentials that look like a
+// JWT are verified as one when a JWT secret is configured; anything else is looked up as an API key.
+func (s *authService) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if credential == "" {
+		return nil, ErrUnauthenticated
+	}
+	now := s.now()
+
+	if len(s.jwtSecret) > 0 && strings.Count(credential, ".") == 2 {
+		claims, err := parseJWT(credential, s.jwtSecret, s.jwtIssuer, now)
+		if err != nil {
+			s.logger.Debug("Rejected bearer token", logger.ErrorField(err))
+			return nil, ErrUnauthenticated
+		}
+		return &Principal{Name: claims.Subject, Role: claims.Role, Method: AuthMethodJWT}, nil
+	}
+
+	key, err := s.apiKeyRepo.FindByHash(ctx, hashAPIKey(credential))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUnauthenticated
+		}
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if !key.IsUsableAt(now) {
+		return nil, ErrUnauthenticated
+	}
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID, now, apiKeyTouchInterval); err != nil {
+		s.logger.Warn("Failed to record API key use", logger.ErrorField(err), logger.Field("api_key_id", key.ID))
+	}
+	return &Principal{Name: key.Name, Role: key.Role, Method: AuthMethodAPIKey, KeyID: key.ID}, nil
+}
+
+// CreateAPIKey creates an API key. The key is only returned by this call.
+func (s *authService) CreateAPIKey(ctx context.Context, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	var fields []dto.FieldError
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		fields = append(fields, dto.FieldError{Field: "name", Message: "is required"})
+	} else if len(name) > maxAPIKeyNameLength {
+		fields = append(fields, dto.FieldError{Field: "name", Message: fmt.Sprintf("must be at most %d characters", maxAPIKeyNameLength)})
+	}
+	if !entity.Role(req.Role).IsValid() {
+		fields = append(fields, dto.FieldError{Field: "role", Message: "must be one of viewer, operator, admin"})
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		fields = append(fields, dto.FieldError{Field: "expires_at", Message: "must be in the future"})
+	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+
+	secret, err := newAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := &entity.APIKey{
+		Name:      name,
+		Role:      entity.Role(req.Role),
+		Prefix:    secret[:apiKeyDisplayLength],
+		KeyHash:   hashAPIKey(secret),
+		CreatedBy: ActorFromContext(ctx),
+	}
+	if req.ExpiresAt != nil {
+		key.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "API key created",
+		logger.Field("api_key_id", key.ID),
+		logger.StringField("name", key.Name),
+		logger.StringField("role", string(key.Role)))
+	return &dto.CreateAPIKeyResponse{APIKeyResponse: *mapToAPIKeyResponse(key), Key: secret}, nil
+}
+
+// ListAPIKeys retrieves every API key, including revoked ones.
+func (s *authService) ListAPIKeys(ctx context.Context) ([]*dto.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*dto.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		responses = append(responses, mapToAPIKeyResponse(&keys[i]))
+	}
+	return responses, nil
+}
+
+// RevokeAPIKey revokes an API key, so it no longer authenticates requests.
+func (s *authService) RevokeAPIKey(ctx context.Context, id uint) error {
+	if err := s.apiKeyRepo.Revoke(ctx, id, s.now()); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "API key revoked", logger.Field("api_key_id", id))
+	return nil
+}
+
+// newAPIKey generates a random API key.
+func newAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashAPIKey returns the hash an API key is stored and looked up by. Keys are long and random,
+// so a fast unsalted hash is enough.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func mapToAPIKeyResponse(key *entity.APIKey) *dto.APIKeyResponse {
+	return &dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Role:       string(key.Role),
+		Prefix:     key.Prefix,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		ExpiresAt:  key.ExpiresAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/config"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeAPIKeyRepository keeps API keys in memory.
+type fakeAPIKeyRepository struct {
+	keys []entity.APIKey
+}
+
+func (r *fakeAPIKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	key.ID = uint(len(r.keys) + 1)
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) FindAll(ctx context.Context) ([]entity.APIKey, error) {
+	return r.keys, nil
+}
+
+func (r *fakeAPIKeyRepository) FindByID(ctx context.Context, id uint) (*entity.APIKey, error) {
+	for i := range r.keys {
+		if r.keys[i].ID == id {
+			return &r.keys[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAPIKeyRepository) FindByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	for i := range r.keys {
+		if r.keys[i].KeyHash == keyHash {
+			return &r.keys[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAPIKeyRepository) Revoke(ctx context.Context, id uint, at time.Time) error {
+	key, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	key.RevokedAt = sql.NullTime{Time: at, Valid: true}
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) TouchLastUsed(ctx context.Context, id uint, at time.Time, every time.Duration) error {
+	key, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	key.LastUsedAt = sql.NullTime{Time: at, Valid: true}
+	return nil
+}
+
+func newTestAuthService(t *testing.T, repo *fakeAPIKeyRepository, cfg config.Auth) AuthService {
+	log, err := logger.New("error", "json")
+	require.NoError(t, err)
+	return NewAuthService(repo, cfg, log)
+}
+
+func TestAuthServiceAPIKeys(t *testing.T) {
+	repo := &fakeAPIKeyRepository{}
+	svc := newTestAuthService(t, repo, config.Auth{JWTSecret: "test-secret"})
+	ctx := WithActor(context.Background(), "alice")
+
+	_, err := svc.CreateAPIKey(ctx, &dto.CreateAPIKeyRequest{Name: " ", Role: "root"})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []dto.FieldError{
+		{Field: "name", Message: "is required"},
+		{Field: "role", Message: "must be one of viewer, operator, admin"},
+	}, validationErr.Fields)
+
+	created, err := svc.CreateAPIKey(ctx, &dto.CreateAPIKeyRequest{Name: "deploy-bot", Role: "operator"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	assert.Equal(t, "alice", created.CreatedBy)
+	assert.Equal(t, hashAPIKey(created.Key), repo.keys[0].KeyHash)
+
+	principal, err := svc.Authenticate(context.Background(), created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Name: "deploy-bot", Role: entity.RoleOperator, Method: AuthMethodAPIKey, KeyID: created.ID}, principal)
+	assert.True(t, repo.keys[0].LastUsedAt.Valid)
+
+	_, err = svc.Authenticate(context.Background(), created.Key+"x")
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	require.NoError(t, svc.RevokeAPIKey(ctx, created.ID))
+	_, err = svc.Authenticate(context.Background(), created.Key)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestAuthServiceJWT(t *testing.T) {
+	svc := newTestAuthService(t, &fakeAPIKeyRepository{}, config.Auth{JWTSecret: "test-secret"})
+	token := signTestJWT(t, "HS256", map[string]interface{}{"sub": "bob", "role": "viewer", "exp": time.Now().Add(time.Hour).Unix()}, "test-secret")
+
+	principal, err := svc.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Name: "bob", Role: entity.RoleViewer, Method: AuthMethodJWT}, principal)
+
+	// Without a secret, tokens are looked up as API keys and therefore rejected.
+	svc = newTestAuthService(t, &fakeAPIKeyRepository{}, config.Auth{})
+	_, err = svc.Authenticate(context.Background(), token)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
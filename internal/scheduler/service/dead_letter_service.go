@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/pkg/common"
+	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/stream"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultDeadLetterPageSize = 50
+	maxDeadLetterPageSize     = 500
+	// maxDeadLetterReplaySize is the number of dead letters replayed by one request.
+	maxDeadLetterReplaySize = 500
+)
+
+var (
+	// ErrUnknownDeadLetterStream is returned for a stream that has no dead-letter stream.
+	ErrUnknownDeadLetterStream = errors.New("stream has no dead-letter stream")
+	// ErrDeadLetterNotFound is returned when a dead letter does not exist, e.g. because it was replayed.
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	// ErrInvalidDeadLetterRequest is returned when dead-letter query parameters or IDs are invalid.
+	ErrInvalidDeadLetterRequest = fmt.Errorf("%w: invalid dead-letter request", ErrInvalidInput)
+)
+
+// streamIDPattern matches a Redis stream entry ID.
+var streamIDPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+// replayDeadLetterScript adds the payload field (ARGV[2]) of the dead letter ARGV[1] back to its
+// stream (KEYS[2]) and removes the dead letter from the dead-letter stream (KEYS[1]) in one step,
+// so a dead letter replayed by two requests at once is only added back once. It returns the new
+// message ID, or nil when the dead letter does not exist.
+var replayDeadLetterScript = redis.NewScript(`
+local entries = redis.call('XRANGE', KEYS[1], ARGV[1], ARGV[1])
+if #entries == 0 then
+	return false
+end
+local fields = entries[1][2]
+local payload = ''
+for i = 1, #fields, 2 do
+	if fields[i] == ARGV[2] then
+		payload = fields[i + 1]
+	end
+end
+local id = redis.call('XADD', KEYS[2], '*', ARGV[2], payload)
+redis.call('XDEL', KEYS[1], ARGV[1])
+return id
+`)
+
+// DeadLetterService defines the interface for inspecting, replaying and purging the stream
+// messages the executor gave up on.
+type DeadLetterService interface {
+	ListDeadLetterStreams(ctx context.Context) ([]*dto.DeadLetterStreamResponse, error)
+	ListDeadLetters(ctx context.Context, source string, req *dto.ListDeadLettersRequest) (*dto.DeadLetterListResponse, error)
+	GetDeadLetter(ctx context.Context, source, id string) (*dto.DeadLetterResponse, error)
+	ReplayDeadLetters(ctx context.Context, source string, ids []string) (*dto.ReplayDeadLettersResponse, error)
+	DeleteDeadLetter(ctx context.Context, source, id string) error
+	PurgeDeadLetters(ctx context.Context, source string, req *dto.PurgeDeadLettersRequest) (*dto.PurgeDeadLettersResponse, error)
+}
+
+// NewDeadLetterService creates a new dead-letter service.
+func NewDeadLetterService(redisClient *redis.Client, logger *logger.Logger) DeadLetterService {
+	return &deadLetterService{
+		redisClient: redisClient,
+		logger:      logger,
+	}
+}
+
+type deadLetterService struct {
+	redisClient *redis.Client
+	logger      *logger.Logger
+}
+
+// ListDeadLetterStreams lists every stream with a dead-letter stream and its number of dead letters.
+func (s *deadLetterService) ListDeadLetterStreams(ctx context.Context) ([]*dto.DeadLetterStreamResponse, error) {
+	responses := make([]*dto.DeadLetterStreamResponse, 0, len(common.RedisDeadLetterStreams))
+	for _, source := range common.RedisDeadLetterStreams {
+		count, err := s.redisClient.XLen(ctx, stream.DeadLetterStream(source)).Result()
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, &dto.DeadLetterStreamResponse{
+			Stream:           source,
+			DeadLetterStream: stream.DeadLetterStream(source),
+			Count:            count,
+		})
+	}
+	return responses, nil
+}
+
+// ListDeadLetters lists the dead letters of a stream, newest first.
+func (s *deadLetterService) ListDeadLetters(ctx context.Context, source string, req *dto.ListDeadLettersRequest) (*dto.DeadLetterListResponse, error) {
+	if err := checkDeadLetterStream(source); err != nil {
+		return nil, err
+	}
+	if req.Limit < 0 || req.Limit > maxDeadLetterPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidDeadLetterRequest, maxDeadLetterPageSize)
+	}
+	if req.Cursor != "" && !streamIDPattern.MatchString(req.Cursor) {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrInvalidDeadLetterRequest)
+	}
+	limit := defaultDeadLetterPageSize
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+
+	start := "+"
+	if req.Cursor != "" {
+		start = "(" + req.Cursor // exclusive, so the page starts after the cursor
+	}
+	// One more entry than the page size tells whether there is a next page.
+	messages, err := s.redisClient.XRevRangeN(ctx, stream.DeadLetterStream(source), start, "-", int64(limit)+1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.DeadLetterListResponse{Data: make([]*dto.DeadLetterResponse, 0, min(len(messages), limit)), Limit: limit}
+	if len(messages) > limit {
+		messages = messages[:limit]
+		response.NextCursor = messages[limit-1].ID
+	}
+	for _, message := range messages {
+		letter, err := stream.ParseDeadLetter(message)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Skipping invalid dead letter", logger.ErrorField(err), logger.StringField("stream", source))
+			continue
+		}
+		response.Data = append(response.Data, mapToDeadLetterResponse(letter))
+	}
+	return response, nil
+}
+
+// GetDeadLetter retrieves a dead letter of a stream.
+func (s *deadLetterService) GetDeadLetter(ctx context.Context, source, id string) (*dto.DeadLetterResponse, error) {
+	if err := checkDeadLetterStream(source); err != nil {
+		return nil, err
+	}
+	if !streamIDPattern.MatchString(id) {
+		return nil, ErrDeadLetterNotFound
+	}
+
+	messages, err := s.redisClient.XRange(ctx, stream.DeadLetterStream(source), id, id).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, ErrDeadLetterNotFound
+	}
+	letter, err := stream.ParseDeadLetter(messages[0])
+	if err != nil {
+		return nil, err
+	}
+	return mapToDeadLetterResponse(letter), nil
+}
+
+// ReplayDeadLetters adds the payload of each dead letter back to its stream as a new message,
+// which is processed like a new one with a fresh retry budget, and removes the dead letter.
+// IDs that are not in the dead-letter stream are reported as not found.
+func (s *deadLetterService) ReplayDeadLetters(ctx context.Context, source string, ids []string) (*dto.ReplayDeadLettersResponse, error) {
+	if err := checkDeadLetterStream(source); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 || len(ids) > maxDeadLetterReplaySize {
+		return nil, fmt.Errorf("%w: ids must contain between 1 and %d IDs", ErrInvalidDeadLetterRequest, maxDeadLetterReplaySize)
+	}
+	for _, id := range ids {
+		if !streamIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("%w: %q is not a dead-letter ID", ErrInvalidDeadLetterRequest, id)
+		}
+	}
+
+	response := &dto.ReplayDeadLettersResponse{Replayed: []dto.ReplayedDeadLetter{}, NotFound: []string{}}
+	keys := []string{stream.DeadLetterStream(source), source}
+	for _, id := range ids {
+		messageID, err := replayDeadLetterScript.Run(ctx, s.redisClient, keys, id, stream.FieldPayload).Text()
+		if errors.Is(err, redis.Nil) {
+			response.NotFound = append(response.NotFound, id)
+			continue
+		}
+		if err != nil {
+			// Dead letters replayed so far stay replayed; the caller retries the rest.
+			s.logger.ErrorContext(ctx, "Failed to replay dead letter", logger.ErrorField(err), logger.StringField("stream", source), logger.StringField("dead_letter_id", id))
+			return nil, err
+		}
+		response.Replayed = append(response.Replayed, dto.ReplayedDeadLetter{ID: id, MessageID: messageID})
+	}
+
+	s.logger.InfoContext(ctx, "Dead letters replayed",
+		logger.StringField("stream", source),
+		logger.IntField("replayed", len(response.Replayed)),
+		logger.IntField("not_found", len(response.NotFound)),
+	)
+	return response, nil
+}
+
+// DeleteDeadLetter removes a dead letter without replaying it.
+func (s *deadLetterService) DeleteDeadLetter(ctx context.Context, source, id string) error {
+	if err := checkDeadLetterStream(source); err != nil {
+		return err
+	}
+	if !streamIDPattern.MatchString(id) {
+		return ErrDeadLetterNotFound
+	}
+
+	deleted, err := s.redisClient.XDel(ctx, stream.DeadLetterStream(source), id).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrDeadLetterNotFound
+	}
+	s.logger.InfoContext(ctx, "Dead letter deleted", logger.StringField("stream", source), logger.StringField("dead_letter_id", id))
+	return nil
+}
+
+// PurgeDeadLetters removes the dead letters of a stream, or only those added before a time.
+func (s *deadLetterService) PurgeDeadLetters(ctx context.Context, source string, req *dto.PurgeDeadLettersRequest) (*dto.PurgeDeadLettersResponse, error) {
+	if err := checkDeadLetterStream(source); err != nil {
+		return nil, err
+	}
+	key := stream.DeadLetterStream(source)
+
+	var purged int64
+	if req.Before == "" {
+		var length *redis.IntCmd
+		if _, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			length = pipe.XLen(ctx, key)
+			pipe.Del(ctx, key)
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		purged = length.Val()
+	} else {
+		before, err := time.Parse(time.RFC3339, req.Before)
+		if err != nil {
+			return nil, fmt.Errorf("%w: before must be an RFC 3339 time", ErrInvalidDeadLetterRequest)
+		}
+		// Entry IDs start with the time the entry was added in milliseconds.
+		if purged, err = s.redisClient.XTrimMinID(ctx, key, fmt.Sprintf("%d-0", before.UnixMilli())).Result(); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Dead letters purged", logger.StringField("stream", source), logger.StringField("before", req.Before), logger.Field("purged", purged))
+	return &dto.PurgeDeadLettersResponse{Purged: purged}, nil
+}
+
+// checkDeadLetterStream returns ErrUnknownDeadLetterStream unless source has a dead-letter stream.
+func checkDeadLetterStream(source string) error {
+	if !slices.Contains(common.RedisDeadLetterStreams, source) {
+		return ErrUnknownDeadLetterStream
+	}
+	return nil
+}
+
+func mapToDeadLetterResponse(letter stream.DeadLetter) *dto.DeadLetterResponse {
+	payload := json.RawMessage(letter.Payload)
+	if !json.Valid(payload) {
+		// Payloads that could not be decoded are returned as a JSON string.
+		payload, _ = json.Marshal(letter.Payload)
+	}
+	return &dto.DeadLetterResponse{
+		ID:            letter.ID,
+		Stream:        letter.SourceStream,
+		SourceID:      letter.SourceID,
+		Payload:       payload,
+		Error:         letter.Error,
+		Attempts:      letter.Attempts,
+		FirstFailedAt: letter.FirstFailedAt,
+		LastFailedAt:  letter.LastFailedAt,
+		Consumer:      letter.Consumer,
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/pkg/stream"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDeadLetterService returns a service whose Redis is unreachable, so only requests
+// rejected before Redis is used succeed in the expected way.
+func newTestDeadLetterService(t *testing.T) DeadLetterService {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewDeadLetterService(client, nil)
+}
+
+func TestDeadLetterServiceRejectsInvalidRequests(t *testing.T) {
+	svc := newTestDeadLetterService(t)
+	ctx := context.Background()
+
+	_, err := svc.ListDeadLetters(ctx, "scheduler.task.execution", &dto.ListDeadLettersRequest{})
+	assert.ErrorIs(t, err, ErrUnknownDeadLetterStream)
+	_, err = svc.ListDeadLetters(ctx, "stock.analyzer", &dto.ListDeadLettersRequest{Limit: 501})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = svc.ListDeadLetters(ctx, "stock.analyzer", &dto.ListDeadLettersRequest{Cursor: "latest"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = svc.GetDeadLetter(ctx, "stock.analyzer", "latest")
+	assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+	assert.ErrorIs(t, svc.DeleteDeadLetter(ctx, "stock.analyzer", "+"), ErrDeadLetterNotFound)
+
+	_, err = svc.ReplayDeadLetters(ctx, "stock.position.monitor", nil)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = svc.ReplayDeadLetters(ctx, "stock.position.monitor", []string{"1718000000000-0", "-"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = svc.ReplayDeadLetters(ctx, "stock.news", []string{"1718000000000-0"})
+	assert.ErrorIs(t, err, ErrUnknownDeadLetterStream)
+
+	_, err = svc.PurgeDeadLetters(ctx, "stock.analyzer", &dto.PurgeDeadLettersRequest{Before: "yesterday"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestMapToDeadLetterResponse(t *testing.T) {
+	letter := stream.DeadLetter{
+		ID:            "1718000900000-0",
+		SourceStream:  "stock.analyzer",
+		SourceID:      "1718000000000-0",
+		Payload:       `{"stock_code":"BBCA"}`,
+		Error:         "quota exceeded",
+		Attempts:      4,
+		FirstFailedAt: time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC),
+		LastFailedAt:  time.Date(2024, 6, 10, 9, 15, 0, 0, time.UTC),
+		Consumer:      "executor-consumer-pod-a-1",
+	}
+
+	response := mapToDeadLetterResponse(letter)
+	assert.JSONEq(t, letter.Payload, string(response.Payload))
+	assert.Equal(t, "stock.analyzer", response.Stream)
+	assert.Equal(t, int64(4), response.Attempts)
+
+	// A payload that could not be decoded is kept as a JSON string.
+	letter.Payload = `{"stock_code":`
+	response = mapToDeadLetterResponse(letter)
+	var payload string
+	require.NoError(t, json.Unmarshal(response.Payload, &payload))
+	assert.Equal(t, letter.Payload, payload)
+}
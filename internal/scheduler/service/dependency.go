@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/repository"
+)
+
+// buildDependencies validates the upstream jobs of a job and returns its dependency entities.
+// jobID is zero for a job that does not exist yet.
+func buildDependencies(ctx context.Context, jobRepo repository.JobRepository, jobID uint, dependsOn []uint) ([]entity.JobDependency, error) {
+	seen := make(map[uint]bool, len(dependsOn))
+	var upstreamIDs []uint
+	for _, id := range dependsOn {
+		if id == jobID {
+			return nil, fmt.Errorf("%w: job cannot depend on itself", ErrInvalidDependency)
+		}
+		if !seen[id] {
+			seen[id] = true
+			upstreamIDs = append(upstreamIDs, id)
+		}
+	}
+
+	upstreamJobs, err := jobRepo.FindByIDs(ctx, upstreamIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(upstreamJobs) != len(upstreamIDs) {
+		return nil, fmt.Errorf("%w: one or more upstream jobs do not exist", ErrInvalidDependency)
+	}
+
+	// A job that does not exist yet has no downstream jobs, so it cannot close a cycle.
+	if jobID != 0 {
+		edges, err := jobRepo.FindAllDependencies(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if hasDependencyCycle(jobID, upstreamIDs, edges) {
+			return nil, ErrDependencyCycle
+		}
+	}
+
+	dependencies := make([]entity.JobDependency, 0, len(upstreamIDs))
+	for _, id := range upstreamIDs {
+		dependencies = append(dependencies, entity.JobDependency{JobID: jobID, DependsOnJobID: id})
+	}
+	return dependencies, nil
+}
+
+// hasDependencyCycle reports whether giving jobID the upstream jobs dependsOn, on top of the
+// existing edges of every other job, makes jobID reachable from itself.
+func hasDependencyCycle(jobID uint, dependsOn []uint, edges []entity.JobDependency) bool {
+	upstream := make(map[uint][]uint)
+	for _, edge := range edges {
+		if edge.JobID == jobID {
+			continue // replaced by dependsOn
+		}
+		upstream[edge.JobID] = append(upstream[edge.JobID], edge.DependsOnJobID)
+	}
+	upstream[jobID] = dependsOn
+
+	visited := make(map[uint]bool)
+	stack := append([]uint{}, dependsOn...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == jobID {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		stack = append(stack, upstream[id]...)
+	}
+	return false
+}
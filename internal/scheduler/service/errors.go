@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang-stock-scryper/internal/scheduler/dto"
+)
+
+var (
+	// ErrInvalidInput is the base error for requests rejected because of invalid client input.
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrInvalidMisfirePolicy is returned when a schedule uses an unknown misfire policy.
+	ErrInvalidMisfirePolicy = fmt.Errorf("%w: misfire_policy must be one of run_once, skip, run_all", ErrInvalidInput)
+	// ErrInvalidCronExpression is returned when a schedule has a cron expression that cannot be parsed.
+	ErrInvalidCronExpression = fmt.Errorf("%w: invalid cron_expression", ErrInvalidInput)
+	// ErrInvalidTimezone is returned when a schedule uses an unknown IANA time zone.
+	ErrInvalidTimezone = fmt.Errorf("%w: timezone must be an IANA time zone name such as Asia/Jakarta", ErrInvalidInput)
+	// ErrInvalidConcurrencyPolicy is returned when a job uses an unknown concurrency policy.
+	ErrInvalidConcurrencyPolicy = fmt.Errorf("%w: concurrency_policy must be one of allow, forbid, replace, queue", ErrInvalidInput)
+	// ErrInvalidExecutionFilter is returned when execution history query parameters are invalid.
+	ErrInvalidExecutionFilter = fmt.Errorf("%w: invalid execution filter", ErrInvalidInput)
+	// ErrInvalidDependency is returned when a job depends on itself or on an unknown job.
+	ErrInvalidDependency = fmt.Errorf("%w: invalid depends_on", ErrInvalidInput)
+	// ErrInvalidPauseUntil is returned when a job or schedule is paused until a time that has already passed.
+	ErrInvalidPauseUntil = fmt.Errorf("%w: until must be in the future", ErrInvalidInput)
+	// ErrExecutionNotRunning is returned when cancelling an execution that is neither queued nor running.
+	ErrExecutionNotRunning = errors.New("execution is not running")
+	// ErrRollbackToDeletedVersion is returned when rolling a job back to the version that deleted it.
+	ErrRollbackToDeletedVersion = fmt.Errorf("%w: the version deleted the job, roll back to an earlier version instead", ErrInvalidInput)
+	// ErrDependencyCycle is returned when job dependencies would form a cycle.
+	ErrDependencyCycle = fmt.Errorf("%w: depends_on would create a dependency cycle", ErrInvalidInput)
+)
+
+// ValidationError is returned when one or more request fields are invalid. It wraps
+// ErrInvalidInput and lists every invalid field, so clients can fix them in one go.
+type ValidationError struct {
+	Fields []dto.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+" "+field.Message)
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidInput, strings.Join(messages, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
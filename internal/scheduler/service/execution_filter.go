@@ -0,0 +1,171 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/internal/scheduler/repository"
+)
+
+const (
+	defaultExecutionPageSize = 50
+	maxExecutionPageSize     = 500
+)
+
+// buildExecutionHistoryFilter validates the list query parameters and converts them into a repository filter.
+func buildExecutionHistoryFilter(req *dto.ListExecutionHistoriesRequest) (repository.ExecutionHistoryFilter, error) {
+	filter := repository.ExecutionHistoryFilter{
+		JobID:      req.JobID,
+		ScheduleID: req.ScheduleID,
+		SortBy:     repository.ExecutionSortStartedAt,
+		Descending: true,
+		Limit:      defaultExecutionPageSize,
+		Offset:     req.Offset,
+	}
+
+	if req.Status != "" {
+		for _, status := range strings.Split(req.Status, ",") {
+			status = strings.TrimSpace(status)
+			if !isKnownExecutionStatus(entity.TaskExecutionStatus(status)) {
+				return filter, fmt.Errorf("%w: unknown status %q", ErrInvalidExecutionFilter, status)
+			}
+			filter.Statuses = append(filter.Statuses, entity.TaskExecutionStatus(status))
+		}
+	}
+
+	var err error
+	if filter.StartedAfter, err = parseTimeParam("started_after", req.StartedAfter); err != nil {
+		return filter, err
+	}
+	if filter.StartedBefore, err = parseTimeParam("started_before", req.StartedBefore); err != nil {
+		return filter, err
+	}
+
+	// A cursor carries the sort column and direction of the page it came from; they apply
+	// when the request omits them and must match when it repeats them.
+	var cursor *executionCursor
+	if req.Cursor != "" {
+		if req.Offset != 0 {
+			return filter, fmt.Errorf("%w: cursor and offset cannot be used together", ErrInvalidExecutionFilter)
+		}
+		if cursor, err = decodeExecutionCursor(req.Cursor); err != nil {
+			return filter, err
+		}
+		filter.SortBy = cursor.SortBy
+		filter.Descending = cursor.Descending
+		filter.After = &cursor.ExecutionHistoryCursor
+	}
+
+	switch req.Sort {
+	case "":
+	case repository.ExecutionSortStartedAt, repository.ExecutionSortID:
+		if cursor != nil && cursor.SortBy != req.Sort {
+			return filter, fmt.Errorf("%w: cursor was issued for sort=%s", ErrInvalidExecutionFilter, cursor.SortBy)
+		}
+		filter.SortBy = req.Sort
+	default:
+		return filter, fmt.Errorf("%w: sort must be started_at or id", ErrInvalidExecutionFilter)
+	}
+
+	switch order := strings.ToLower(req.Order); order {
+	case "":
+	case "asc", "desc":
+		if cursor != nil && cursor.Descending != (order == "desc") {
+			return filter, fmt.Errorf("%w: cursor was issued for order=%s", ErrInvalidExecutionFilter, sortOrder(cursor.Descending))
+		}
+		filter.Descending = order == "desc"
+	default:
+		return filter, fmt.Errorf("%w: order must be asc or desc", ErrInvalidExecutionFilter)
+	}
+
+	if req.Limit < 0 || req.Limit > maxExecutionPageSize {
+		return filter, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidExecutionFilter, maxExecutionPageSize)
+	}
+	if req.Limit > 0 {
+		filter.Limit = req.Limit
+	}
+	if req.Offset < 0 {
+		return filter, fmt.Errorf("%w: offset must not be negative", ErrInvalidExecutionFilter)
+	}
+
+	return filter, nil
+}
+
+func isKnownExecutionStatus(status entity.TaskExecutionStatus) bool {
+	switch status {
+	case entity.StatusQueued, entity.StatusRunning, entity.StatusCompleted, entity.StatusFailed,
+		entity.StatusTimeout, entity.StatusSkipped, entity.StatusCancelled, entity.StatusLost:
+		return true
+	}
+	return false
+}
+
+func parseTimeParam(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an RFC 3339 timestamp", ErrInvalidExecutionFilter, name)
+	}
+	return &t, nil
+}
+
+// executionCursor is the decoded form of an opaque execution history cursor.
+type executionCursor struct {
+	repository.ExecutionHistoryCursor
+	SortBy     string
+	Descending bool
+}
+
+func sortOrder(descending bool) string {
+	if descending {
+		return "desc"
+	}
+	return "asc"
+}
+
+// encodeExecutionCursor returns an opaque cursor pointing at the given row of a page
+// sorted as described by filter.
+func encodeExecutionCursor(filter repository.ExecutionHistoryFilter, history *entity.TaskExecutionHistory) string {
+	raw := fmt.Sprintf("%s:%s:%d:%d", filter.SortBy, sortOrder(filter.Descending), history.StartedAt.UnixNano(), history.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeExecutionCursor(cursor string) (*executionCursor, error) {
+	invalid := fmt.Errorf("%w: invalid cursor", ErrInvalidExecutionFilter)
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 4 {
+		return nil, invalid
+	}
+	sortBy, order := parts[0], parts[1]
+	if sortBy != repository.ExecutionSortStartedAt && sortBy != repository.ExecutionSortID {
+		return nil, invalid
+	}
+	if order != "asc" && order != "desc" {
+		return nil, invalid
+	}
+	nanos, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	historyID, err := strconv.ParseUint(parts[3], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	return &executionCursor{
+		ExecutionHistoryCursor: repository.ExecutionHistoryCursor{StartedAt: time.Unix(0, nanos), ID: uint(historyID)},
+		SortBy:                 sortBy,
+		Descending:             order == "desc",
+	}, nil
+}
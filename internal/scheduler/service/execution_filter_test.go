@@ -0,0 +1,122 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/internal/scheduler/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildExecutionHistoryFilter(t *testing.T) {
+	weekAgo := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		req     dto.ListExecutionHistoriesRequest
+		want    repository.ExecutionHistoryFilter
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			req:  dto.ListExecutionHistoriesRequest{},
+			want: repository.ExecutionHistoryFilter{SortBy: repository.ExecutionSortStartedAt, Descending: true, Limit: defaultExecutionPageSize},
+		},
+		{
+			name: "failed runs this week",
+			req:  dto.ListExecutionHistoriesRequest{Status: "failed, timeout", StartedAfter: "2025-06-10T00:00:00Z", Order: "asc", Limit: 10},
+			want: repository.ExecutionHistoryFilter{
+				Statuses:     []entity.TaskExecutionStatus{entity.StatusFailed, entity.StatusTimeout},
+				StartedAfter: &weekAgo,
+				SortBy:       repository.ExecutionSortStartedAt,
+				Limit:        10,
+			},
+		},
+		{
+			name: "stuck and lost runs",
+			req:  dto.ListExecutionHistoriesRequest{Status: "queued,lost"},
+			want: repository.ExecutionHistoryFilter{
+				Statuses:   []entity.TaskExecutionStatus{entity.StatusQueued, entity.StatusLost},
+				SortBy:     repository.ExecutionSortStartedAt,
+				Descending: true,
+				Limit:      defaultExecutionPageSize,
+			},
+		},
+		{
+			name:    "unknown status",
+			req:     dto.ListExecutionHistoriesRequest{Status: "broken"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid time",
+			req:     dto.ListExecutionHistoriesRequest{StartedBefore: "yesterday"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown sort column",
+			req:     dto.ListExecutionHistoriesRequest{Sort: "executed_at"},
+			wantErr: true,
+		},
+		{
+			name:    "limit too large",
+			req:     dto.ListExecutionHistoriesRequest{Limit: maxExecutionPageSize + 1},
+			wantErr: true,
+		},
+		{
+			name:    "invalid cursor",
+			req:     dto.ListExecutionHistoriesRequest{Cursor: "not-a-cursor"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildExecutionHistoryFilter(&tt.req)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExecutionCursorRoundTrip(t *testing.T) {
+	history := &entity.TaskExecutionHistory{ID: 42, StartedAt: time.Date(2025, 6, 17, 12, 30, 0, 123, time.UTC)}
+	filter := repository.ExecutionHistoryFilter{SortBy: repository.ExecutionSortID, Descending: false}
+
+	cursor, err := decodeExecutionCursor(encodeExecutionCursor(filter, history))
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(42), cursor.ID)
+	assert.True(t, history.StartedAt.Equal(cursor.StartedAt))
+	assert.Equal(t, repository.ExecutionSortID, cursor.SortBy)
+	assert.False(t, cursor.Descending)
+}
+
+func TestBuildExecutionHistoryFilterWithCursor(t *testing.T) {
+	history := &entity.TaskExecutionHistory{ID: 42, StartedAt: time.Date(2025, 6, 17, 12, 30, 0, 0, time.UTC)}
+	cursor := encodeExecutionCursor(repository.ExecutionHistoryFilter{SortBy: repository.ExecutionSortID, Descending: false}, history)
+
+	filter, err := buildExecutionHistoryFilter(&dto.ListExecutionHistoriesRequest{Cursor: cursor})
+	assert.NoError(t, err)
+	assert.Equal(t, repository.ExecutionSortID, filter.SortBy)
+	assert.False(t, filter.Descending)
+	assert.Equal(t, uint(42), filter.After.ID)
+
+	_, err = buildExecutionHistoryFilter(&dto.ListExecutionHistoriesRequest{Cursor: cursor, Sort: "id", Order: "asc"})
+	assert.NoError(t, err)
+
+	_, err = buildExecutionHistoryFilter(&dto.ListExecutionHistoriesRequest{Cursor: cursor, Sort: "started_at"})
+	assert.ErrorIs(t, err, ErrInvalidExecutionFilter)
+
+	_, err = buildExecutionHistoryFilter(&dto.ListExecutionHistoriesRequest{Cursor: cursor, Order: "desc"})
+	assert.ErrorIs(t, err, ErrInvalidExecutionFilter)
+
+	_, err = buildExecutionHistoryFilter(&dto.ListExecutionHistoriesRequest{Cursor: cursor, Offset: 50})
+	assert.ErrorIs(t, err, ErrInvalidExecutionFilter)
+}
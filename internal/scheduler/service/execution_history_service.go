@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"time"
+
 	"golang-stock-scryper/internal/entity"
 	"golang-stock-scryper/internal/scheduler/dto"
 	"golang-stock-scryper/internal/scheduler/repository"
@@ -11,21 +13,30 @@ import (
 // ExecutionHistoryService defines the interface for managing execution history.
 type ExecutionHistoryService interface {
 	GetExecutionHistoryByID(ctx context.Context, id uint) (*dto.ExecutionHistoryResponse, error)
-	GetAllExecutionHistories(ctx context.Context) ([]*dto.ExecutionHistoryResponse, error)
-	GetExecutionHistoriesByJobID(ctx context.Context, jobID uint) ([]*dto.ExecutionHistoryResponse, error)
+	ListExecutionHistories(ctx context.Context, req *dto.ListExecutionHistoriesRequest) (*dto.ExecutionHistoryListResponse, error)
+	GetExecutionHistoriesByJobID(ctx context.Context, jobID uint, req *dto.ListExecutionHistoriesRequest) (*dto.ExecutionHistoryListResponse, error)
+	GetExecutionChain(ctx context.Context, id uint) (*dto.ExecutionChainResponse, error)
+	CancelExecution(ctx context.Context, id uint) (*dto.ExecutionHistoryResponse, error)
 }
 
+// maxExecutionChainDepth bounds how far the dependency chain of an execution is followed.
+const maxExecutionChainDepth = 50
+
 // NewExecutionHistoryService creates a new execution history service.
-func NewExecutionHistoryService(historyRepo repository.TaskExecutionHistoryRepository, logger *logger.Logger) ExecutionHistoryService {
+func NewExecutionHistoryService(historyRepo repository.TaskExecutionHistoryRepository, webhookRepo repository.WebhookRepository, taskPublisher TaskPublisher, logger *logger.Logger) ExecutionHistoryService {
 	return &executionHistoryService{
-		historyRepo: historyRepo,
-		logger:      logger,
+		historyRepo:   historyRepo,
+		webhookRepo:   webhookRepo,
+		taskPublisher: taskPublisher,
+		logger:        logger,
 	}
 }
 
 type executionHistoryService struct {
-	historyRepo repository.TaskExecutionHistoryRepository
-	logger      *logger.Logger
+	historyRepo   repository.TaskExecutionHistoryRepository
+	webhookRepo   repository.WebhookRepository
+	taskPublisher TaskPublisher
+	logger        *logger.Logger
 }
 
 // GetExecutionHistoryByID retrieves an execution history record by its ID.
@@ -38,36 +49,117 @@ func (s *executionHistoryService) GetExecutionHistoryByID(ctx context.Context, i
 	return s.mapToExecutionHistoryResponse(history), nil
 }
 
-// GetAllExecutionHistories retrieves all execution history records.
-func (s *executionHistoryService) GetAllExecutionHistories(ctx context.Context) ([]*dto.ExecutionHistoryResponse, error) {
-	histories, err := s.historyRepo.FindAll(ctx)
+// ListExecutionHistories retrieves a filtered page of execution history records.
+func (s *executionHistoryService) ListExecutionHistories(ctx context.Context, req *dto.ListExecutionHistoriesRequest) (*dto.ExecutionHistoryListResponse, error) {
+	filter, err := buildExecutionHistoryFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	histories, total, err := s.historyRepo.FindAllByFilter(ctx, filter)
 	if err != nil {
-		s.logger.Error("Failed to get all execution histories", logger.ErrorField(err))
+		s.logger.Error("Failed to list execution histories", logger.ErrorField(err))
 		return nil, err
 	}
 
-	var historyResponses []*dto.ExecutionHistoryResponse
-	for _, history := range histories {
-		historyResponses = append(historyResponses, s.mapToExecutionHistoryResponse(&history))
+	response := &dto.ExecutionHistoryListResponse{
+		Data:   make([]*dto.ExecutionHistoryResponse, 0, len(histories)),
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+	for i := range histories {
+		response.Data = append(response.Data, s.mapToExecutionHistoryResponse(&histories[i]))
+	}
+	// Cursors continue keyset paging, so they are only handed out when offset paging is not in use.
+	if len(histories) == filter.Limit && filter.Offset == 0 {
+		response.NextCursor = encodeExecutionCursor(filter, &histories[len(histories)-1])
 	}
 
-	return historyResponses, nil
+	return response, nil
 }
 
-// GetExecutionHistoriesByJobID retrieves all execution history records for a specific job.
-func (s *executionHistoryService) GetExecutionHistoriesByJobID(ctx context.Context, jobID uint) ([]*dto.ExecutionHistoryResponse, error) {
-	histories, err := s.historyRepo.FindAllByJobID(ctx, jobID)
+// GetExecutionHistoriesByJobID retrieves a filtered page of execution history records for a specific job.
+func (s *executionHistoryService) GetExecutionHistoriesByJobID(ctx context.Context, jobID uint, req *dto.ListExecutionHistoriesRequest) (*dto.ExecutionHistoryListResponse, error) {
+	req.JobID = &jobID
+	return s.ListExecutionHistories(ctx, req)
+}
+
+// GetExecutionChain retrieves an execution together with the upstream executions that
+// triggered it and the downstream executions it triggered through job dependencies.
+func (s *executionHistoryService) GetExecutionChain(ctx context.Context, id uint) (*dto.ExecutionChainResponse, error) {
+	history, err := s.historyRepo.FindByID(ctx, id)
 	if err != nil {
-		s.logger.Error("Failed to get execution histories by job ID", logger.ErrorField(err), logger.Field("job_id", jobID))
+		s.logger.Error("Failed to find execution history", logger.ErrorField(err), logger.Field("history_id", id))
 		return nil, err
 	}
 
-	var historyResponses []*dto.ExecutionHistoryResponse
-	for _, history := range histories {
-		historyResponses = append(historyResponses, s.mapToExecutionHistoryResponse(&history))
+	chain := &dto.ExecutionChainResponse{
+		Execution:  s.mapToExecutionHistoryResponse(history),
+		Upstream:   []*dto.ExecutionHistoryResponse{},
+		Downstream: []*dto.ExecutionHistoryResponse{},
+	}
+
+	current := history
+	for depth := 0; current.TriggeredByID != nil && depth < maxExecutionChainDepth; depth++ {
+		upstream, err := s.historyRepo.FindByID(ctx, *current.TriggeredByID)
+		if err != nil {
+			s.logger.Error("Failed to find upstream execution", logger.ErrorField(err), logger.Field("history_id", *current.TriggeredByID))
+			return nil, err
+		}
+		chain.Upstream = append(chain.Upstream, s.mapToExecutionHistoryResponse(upstream))
+		current = upstream
+	}
+
+	level := []uint{history.ID}
+	for depth := 0; len(level) > 0 && depth < maxExecutionChainDepth; depth++ {
+		downstream, err := s.historyRepo.FindAllByTriggeredByIDs(ctx, level)
+		if err != nil {
+			s.logger.Error("Failed to find downstream executions", logger.ErrorField(err), logger.Field("history_id", id))
+			return nil, err
+		}
+		level = level[:0]
+		for i := range downstream {
+			chain.Downstream = append(chain.Downstream, s.mapToExecutionHistoryResponse(&downstream[i]))
+			level = append(level, downstream[i].ID)
+		}
 	}
 
-	return historyResponses, nil
+	return chain, nil
+}
+
+// CancelExecution cancels a queued or running execution. A queued execution that no executor
+// has picked up yet is cancelled right away. Otherwise cancellation is asynchronous: the executor
+// holding it stops the job and records the cancelled status together with any output produced so far.
+func (s *executionHistoryService) CancelExecution(ctx context.Context, id uint) (*dto.ExecutionHistoryResponse, error) {
+	history, err := s.historyRepo.FindByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to find execution history", logger.ErrorField(err), logger.Field("history_id", id))
+		return nil, err
+	}
+	if !history.Status.IsActive() {
+		return nil, ErrExecutionNotRunning
+	}
+	if history.Status == entity.StatusQueued {
+		cancelled, err := s.historyRepo.CancelQueued(ctx, history)
+		if err != nil {
+			s.logger.Error("Failed to cancel queued execution", logger.ErrorField(err), logger.Field("history_id", id))
+			return nil, err
+		}
+		if cancelled {
+			if err := s.webhookRepo.EnqueueExecutionEvent(ctx, entity.NewExecutionEvent(history, time.Now())); err != nil {
+				s.logger.Error("Failed to enqueue webhook event", logger.ErrorField(err), logger.Field("history_id", id), logger.StringField("status", string(history.Status)))
+			}
+		}
+	}
+
+	// The executor may hold a queued execution while it waits for a retry backoff or a free
+	// slot, so the cancellation is broadcast even when the row was cancelled above.
+
+	if err := s.taskPublisher.Cancel(ctx, history.ID); err != nil {
+		return nil, err
+	}
+	return s.mapToExecutionHistoryResponse(history), nil
 }
 
 // mapToExecutionHistoryResponse maps an entity.TaskExecutionHistory to a dto.ExecutionHistoryResponse.
@@ -78,12 +170,19 @@ func (s *executionHistoryService) mapToExecutionHistoryResponse(history *entity.
 	}
 
 	return &dto.ExecutionHistoryResponse{
-		ID:         history.ID,
-		JobID:      history.JobID,
-		ScheduleID: history.ScheduleID,
-		Status:     string(history.Status),
-		ExecutedAt: history.StartedAt,
-		Duration:   duration,
-		Output:     history.Output.String,
+		ID:           history.ID,
+		JobID:        history.JobID,
+		ScheduleID:   history.ScheduleID,
+		Status:       string(history.Status),
+		ScheduledAt:  history.ScheduledAt,
+		ExecutedAt:   history.StartedAt,
+		CompletedAt:  history.CompletedAt,
+		Duration:     duration,
+		Output:       history.Output.String,
+		ErrorMessage: history.ErrorMessage.String,
+		Attempt:      history.Attempt,
+		RetryOfID:    history.RetryOfID,
+		Trigger:      string(history.TriggerType),
+		HeartbeatAt:  history.HeartbeatAt,
 	}
 }
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/config"
+	"golang-stock-scryper/internal/scheduler/repository"
+	"golang-stock-scryper/pkg/logger"
+)
+
+const (
+	defaultReaperInterval         = 30 * time.Second
+	defaultReaperHeartbeatTimeout = 2 * time.Minute
+	defaultReaperQueuedTimeout    = 30 * time.Minute
+	defaultReaperTimeoutGrace     = time.Minute
+)
+
+// ExecutionReaper marks executions that stopped making progress as timed out or lost, so that
+// execution history does not keep showing them as queued or running.
+type ExecutionReaper interface {
+	Start(ctx context.Context)
+	Reap(ctx context.Context)
+}
+
+// ReaperSettings holds the parsed reaper configuration.
+type ReaperSettings struct {
+	Interval         time.Duration
+	HeartbeatTimeout time.Duration
+	QueuedTimeout    time.Duration
+	TimeoutGrace     time.Duration
+}
+
+// NewReaperSettings parses the reaper configuration, using defaults for empty values.
+func NewReaperSettings(cfg config.Reaper) (ReaperSettings, error) {
+	settings := ReaperSettings{
+		Interval:         defaultReaperInterval,
+		HeartbeatTimeout: defaultReaperHeartbeatTimeout,
+		QueuedTimeout:    defaultReaperQueuedTimeout,
+		TimeoutGrace:     defaultReaperTimeoutGrace,
+	}
+	fields := []struct {
+		name      string
+		value     string
+		dest      *time.Duration
+		allowZero bool
+	}{
+		{"interval", cfg.Interval, &settings.Interval, false},
+		{"heartbeat_timeout", cfg.HeartbeatTimeout, &settings.HeartbeatTimeout, false},
+		{"queued_timeout", cfg.QueuedTimeout, &settings.QueuedTimeout, false},
+		{"timeout_grace", cfg.TimeoutGrace, &settings.TimeoutGrace, true},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.value)
+		if err != nil {
+			return ReaperSettings{}, fmt.Errorf("invalid reaper.%s: %w", field.name, err)
+		}
+		if d < 0 {
+			return ReaperSettings{}, fmt.Errorf("invalid reaper.%s: must not be negative", field.name)
+		}
+		if d == 0 && !field.allowZero {
+			return ReaperSettings{}, fmt.Errorf("invalid reaper.%s: must be positive", field.name)
+		}
+		*field.dest = d
+	}
+	return settings, nil
+}
+
+// NewExecutionReaper creates a new execution reaper.
+func NewExecutionReaper(historyRepo repository.TaskExecutionHistoryRepository, webhookRepo repository.WebhookRepository, logger *logger.Logger, settings ReaperSettings) ExecutionReaper {
+	return &executionReaper{
+		historyRepo: historyRepo,
+		webhookRepo: webhookRepo,
+		logger:      logger,
+		settings:    settings,
+	}
+}
+
+type executionReaper struct {
+	historyRepo repository.TaskExecutionHistoryRepository
+	webhookRepo repository.WebhookRepository
+	logger      *logger.Logger
+	settings    ReaperSettings
+}
+
+// Start reaps executions periodically until ctx is done.
+func (r *executionReaper) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.settings.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Execution reaper stopping")
+			return
+		case <-ticker.C:
+			r.Reap(ctx)
+		}
+	}
+}
+
+// Reap marks running executions that exceeded their job timeout as timed out, and executions
+// whose executor went silent or that were never picked up as lost. Every scheduler instance
+// may reap; the updates are conditional, so an execution is only reaped once. Webhook
+// subscriptions are notified of every reaped execution.
+func (r *executionReaper) Reap(ctx context.Context) {
+	now := time.Now()
+	timedOut, err := r.historyRepo.ReapTimedOut(ctx, now, r.settings.TimeoutGrace)
+	if err != nil {
+		r.logger.Error("Failed to reap timed out executions", logger.ErrorField(err))
+	} else if len(timedOut) > 0 {
+		r.logger.Warn("Reaped timed out executions", logger.IntField("count", len(timedOut)))
+		r.notifyWebhooks(ctx, timedOut)
+	}
+
+	lost, err := r.historyRepo.ReapLost(ctx, now, r.settings.HeartbeatTimeout, r.settings.QueuedTimeout)
+	if err != nil {
+		r.logger.Error("Failed to reap lost executions", logger.ErrorField(err))
+	} else if len(lost) > 0 {
+		r.logger.Warn("Reaped lost executions", logger.IntField("count", len(lost)))
+		r.notifyWebhooks(ctx, lost)
+	}
+}
+
+// notifyWebhooks enqueues the new status of reaped executions as webhook events.
+func (r *executionReaper) notifyWebhooks(ctx context.Context, histories []entity.TaskExecutionHistory) {
+	for i := range histories {
+		history := &histories[i]
+		if err := r.webhookRepo.EnqueueExecutionEvent(ctx, entity.NewExecutionEvent(history, time.Now())); err != nil {
+			r.logger.Error("Failed to enqueue webhook event", logger.ErrorField(err), logger.Field("history_id", history.ID), logger.StringField("status", string(history.Status)))
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/scheduler/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReaperSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Reaper
+		want    ReaperSettings
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			cfg:  config.Reaper{},
+			want: ReaperSettings{
+				Interval:         defaultReaperInterval,
+				HeartbeatTimeout: defaultReaperHeartbeatTimeout,
+				QueuedTimeout:    defaultReaperQueuedTimeout,
+				TimeoutGrace:     defaultReaperTimeoutGrace,
+			},
+		},
+		{
+			name: "configured",
+			cfg:  config.Reaper{Interval: "10s", HeartbeatTimeout: "1m", QueuedTimeout: "1h", TimeoutGrace: "0s"},
+			want: ReaperSettings{
+				Interval:         10 * time.Second,
+				HeartbeatTimeout: time.Minute,
+				QueuedTimeout:    time.Hour,
+			},
+		},
+		{
+			name:    "invalid duration",
+			cfg:     config.Reaper{HeartbeatTimeout: "two minutes"},
+			wantErr: true,
+		},
+		{
+			name:    "zero interval",
+			cfg:     config.Reaper{Interval: "0s"},
+			wantErr: true,
+		},
+		{
+			name:    "negative grace",
+			cfg:     config.Reaper{TimeoutGrace: "-1m"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := NewReaperSettings(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, settings)
+		})
+	}
+}
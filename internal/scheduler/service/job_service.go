@@ -1,8 +1,13 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
 
 	"golang-stock-scryper/internal/entity"
 	"golang-stock-scryper/internal/scheduler/dto"
@@ -10,6 +15,7 @@ import (
 	"golang-stock-scryper/pkg/logger"
 
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
 // JobService defines the interface for managing jobs.
@@ -19,49 +25,101 @@ type JobService interface {
 	GetAllJobs(ctx context.Context) ([]*dto.JobResponse, error)
 	UpdateJob(ctx context.Context, id uint, req *dto.UpdateJobRequest) (*dto.JobResponse, error)
 	DeleteJob(ctx context.Context, id uint) error
+	TriggerJob(ctx context.Context, id uint, req *dto.TriggerJobRequest) (*dto.TriggerJobResponse, error)
+	ListJobTypes() []dto.JobTypeResponse
+	PauseJob(ctx context.Context, id uint, req *dto.PauseRequest) (*dto.JobResponse, error)
+	ResumeJob(ctx context.Context, id uint) (*dto.JobResponse, error)
+	ListJobVersions(ctx context.Context, id uint) ([]*dto.JobVersionResponse, error)
+	GetJobVersion(ctx context.Context, id uint, version int) (*dto.JobVersionResponse, error)
+	DiffJobVersions(ctx context.Context, id uint, req *dto.DiffJobVersionsRequest) (*dto.JobVersionDiffResponse, error)
+	RollbackJob(ctx context.Context, id uint, version int) (*dto.JobResponse, error)
 }
 
 // NewJobService creates a new job service.
-func NewJobService(jobRepo repository.JobRepository, logger *logger.Logger) JobService {
+func NewJobService(jobRepo repository.JobRepository, versionRepo repository.JobVersionRepository, txManager repository.TransactionManager, taskPublisher TaskPublisher, logger *logger.Logger) JobService {
 	return &jobService{
-		jobRepo: jobRepo,
-		logger:  logger,
+		jobRepo:       jobRepo,
+		versionRepo:   versionRepo,
+		txManager:     txManager,
+		taskPublisher: taskPublisher,
+		logger:        logger,
 	}
 }
 
 type jobService struct {
-	jobRepo repository.JobRepository
-	logger  *logger.Logger
+	jobRepo       repository.JobRepository
+	versionRepo   repository.JobVersionRepository
+	txManager     repository.TransactionManager
+	taskPublisher TaskPublisher
+	logger        *logger.Logger
 }
 
 // CreateJob handles the business logic for creating a new job.
 func (s *jobService) CreateJob(ctx context.Context, req *dto.CreateJobRequest) (*dto.JobResponse, error) {
-	retryPolicyBytes, err := json.Marshal(req.RetryPolicy)
+	job := &entity.Job{}
+	err := s.txManager.WithTransaction(ctx, func(repos repository.Repositories) error {
+		return createJob(ctx, repos, job, req, entity.JobVersionCreated)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	job := &entity.Job{
-		Name:        req.Name,
-		Description: req.Description,
-		Type:        entity.JobType(req.Type),
-		Payload:     datatypes.JSON(req.Payload),
-		RetryPolicy: datatypes.JSON(retryPolicyBytes),
-		Timeout:     req.Timeout,
+	return s.mapToJobResponse(job), nil
+}
+
+// createJob validates a job request, creates the job from it and records the new version.
+// job is empty, or holds only the ID when a deleted job is restored.
+func createJob(ctx context.Context, repos repository.Repositories, job *entity.Job, req *dto.CreateJobRequest, action entity.JobVersionAction) error {
+	if err := applyJobRequest(job, req); err != nil {
+		return err
 	}
 
 	for _, sDto := range req.Schedules {
-		job.Schedules = append(job.Schedules, entity.TaskSchedule{
-			CronExpression: sDto.CronExpression,
-			IsActive:       sDto.IsActive,
-		})
+		schedule, err := newTaskSchedule(sDto)
+		if err != nil {
+			return err
+		}
+		job.Schedules = append(job.Schedules, schedule)
 	}
 
-	if err := s.jobRepo.Create(ctx, job); err != nil {
-		return nil, err
+	var err error
+	job.Dependencies, err = buildDependencies(ctx, repos.Jobs, job.ID, req.DependsOn)
+	if err != nil {
+		return err
 	}
 
-	return s.mapToJobResponse(job), nil
+	if err := repos.Jobs.Create(ctx, job); err != nil {
+		return err
+	}
+	after := entity.NewJobSnapshot(job)
+	return recordJobVersion(ctx, repos, job.ID, action, nil, &after)
+}
+
+// applyJobRequest validates a job request and copies its job fields, other than the
+// schedules and dependencies, to job.
+func applyJobRequest(job *entity.Job, req *dto.CreateJobRequest) error {
+	if err := validateJobRequest(req); err != nil {
+		return err
+	}
+
+	retryPolicyBytes, err := json.Marshal(req.RetryPolicy)
+	if err != nil {
+		return err
+	}
+
+	concurrencyPolicy, err := parseConcurrencyPolicy(req.ConcurrencyPolicy)
+	if err != nil {
+		return err
+	}
+
+	job.Name = req.Name
+	job.Description = req.Description
+	job.Type = entity.JobType(req.Type)
+	job.Payload = datatypes.JSON(jobPayload(req.Payload))
+	job.RetryPolicy = datatypes.JSON(retryPolicyBytes)
+	job.Timeout = req.Timeout
+	job.ConcurrencyPolicy = concurrencyPolicy
+	return nil
 }
 
 // GetJobByID retrieves a job by its ID.
@@ -88,58 +146,244 @@ func (s *jobService) GetAllJobs(ctx context.Context) ([]*dto.JobResponse, error)
 	return jobResponses, nil
 }
 
-// DeleteJob deletes a job by its ID.
+// DeleteJob deletes a job by its ID. Deleting a job that does not exist succeeds.
 func (s *jobService) DeleteJob(ctx context.Context, id uint) error {
-	err := s.jobRepo.Delete(ctx, id)
+	err := s.txManager.WithTransaction(ctx, func(repos repository.Repositories) error {
+		job, err := repos.Jobs.FindByID(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := repos.Jobs.Delete(ctx, id); err != nil {
+			return err
+		}
+		before := entity.NewJobSnapshot(job)
+		return recordJobVersion(ctx, repos, id, entity.JobVersionDeleted, &before, nil)
+	})
 	if err != nil {
 		s.logger.Error("Failed to delete job", logger.ErrorField(err), logger.Field("job_id", id))
 		return err
 	}
-	s.logger.Info("Job deleted successfully", logger.Field("job_id", id))
+	s.logger.InfoContext(ctx, "Job deleted successfully", logger.Field("job_id", id))
 	return nil
 }
 
 // UpdateJob handles the business logic for updating an existing job.
 func (s *jobService) UpdateJob(ctx context.Context, id uint, req *dto.UpdateJobRequest) (*dto.JobResponse, error) {
-	// First, find the existing job.
-	job, err := s.jobRepo.FindByID(ctx, id)
+	err := s.txManager.WithTransaction(ctx, func(repos repository.Repositories) error {
+		job, err := repos.Jobs.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		// Update requests carry the same fields as create requests.
+		return updateJob(ctx, repos, job, (*dto.CreateJobRequest)(req), entity.JobVersionUpdated)
+	})
 	if err != nil {
-		s.logger.Error("Failed to find job for update", logger.ErrorField(err), logger.Field("job_id", id))
-		return nil, err // Or a specific not found error
+		s.logger.Error("Failed to update job", logger.ErrorField(err), logger.Field("job_id", id))
+		return nil, err
 	}
 
-	// Update the job fields from the request.
-	retryPolicyBytes, err := json.Marshal(req.RetryPolicy)
+	s.logger.InfoContext(ctx, "Job updated successfully", logger.Field("job_id", id))
+	return s.GetJobByID(ctx, id)
+}
+
+// updateJob validates a job request, applies it to an existing job and records the new version.
+func updateJob(ctx context.Context, repos repository.Repositories, job *entity.Job, req *dto.CreateJobRequest, action entity.JobVersionAction) error {
+	before := entity.NewJobSnapshot(job)
+	if err := applyJobRequest(job, req); err != nil {
+		return err
+	}
+
+	// Schedules of the request that match an existing schedule keep its identity; the others
+	// are created and unmatched existing schedules are deleted by the repository.
+	var err error
+	job.Schedules, err = mergeSchedules(job.Schedules, req.Schedules, time.Now())
+	if err != nil {
+		return err
+	}
+
+	job.Dependencies, err = buildDependencies(ctx, repos.Jobs, job.ID, req.DependsOn)
+	if err != nil {
+		return err
+	}
+
+	if err := repos.Jobs.Update(ctx, job); err != nil {
+		return err
+	}
+	after := entity.NewJobSnapshot(job)
+	return recordJobVersion(ctx, repos, job.ID, action, &before, &after)
+}
+
+// PauseJob pauses every schedule of a job, either until it is resumed or until req.Until.
+// Runs that fall in the pause are skipped.
+func (s *jobService) PauseJob(ctx context.Context, id uint, req *dto.PauseRequest) (*dto.JobResponse, error) {
+	pause, err := newPauseState(req, time.Now())
 	if err != nil {
-		s.logger.Error("Failed to marshal retry policy", logger.ErrorField(err))
 		return nil, err
 	}
+	if err := s.jobRepo.UpdatePause(ctx, id, pause); err != nil {
+		s.logger.Error("Failed to pause job", logger.ErrorField(err), logger.Field("job_id", id))
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "Job paused", logger.Field("job_id", id), logger.Field("paused_until", pause.PausedUntil.Time))
+	return s.GetJobByID(ctx, id)
+}
 
-	job.Name = req.Name
-	job.Description = req.Description
-	job.Type = entity.JobType(req.Type)
-	job.Payload = datatypes.JSON(req.Payload)
-	job.RetryPolicy = datatypes.JSON(retryPolicyBytes)
-	job.Timeout = req.Timeout
+// ResumeJob resumes a paused job. Its schedules continue from their next fire time.
+func (s *jobService) ResumeJob(ctx context.Context, id uint) (*dto.JobResponse, error) {
+	if err := s.jobRepo.UpdatePause(ctx, id, entity.PauseState{}); err != nil {
+		s.logger.Error("Failed to resume job", logger.ErrorField(err), logger.Field("job_id", id))
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "Job resumed", logger.Field("job_id", id))
+	return s.GetJobByID(ctx, id)
+}
 
-	// Replace existing schedules with new ones from the request.
-	job.Schedules = []entity.TaskSchedule{} // The repository update will handle deletion
-	for _, sDto := range req.Schedules {
-		job.Schedules = append(job.Schedules, entity.TaskSchedule{
-			CronExpression: sDto.CronExpression,
-			IsActive:       sDto.IsActive,
-			JobID:          job.ID,
-		})
+// TriggerJob enqueues an immediate execution of a job outside of its schedules.
+// The schedules' next execution times are left untouched. A payload override is validated
+// against the schema of the job type like the payload of the job, and a *ValidationError
+// lists its invalid fields.
+func (s *jobService) TriggerJob(ctx context.Context, id uint, req *dto.TriggerJobRequest) (*dto.TriggerJobResponse, error) {
+	job, err := s.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to find job for trigger", logger.ErrorField(err), logger.Field("job_id", id))
+		return nil, err
 	}
 
-	// Persist the updated job. The repository's Update method handles the transaction.
-	if err := s.jobRepo.Update(ctx, job); err != nil {
-		s.logger.Error("Failed to update job", logger.ErrorField(err), logger.Field("job_id", id))
+	history := &entity.TaskExecutionHistory{
+		JobID:       job.ID,
+		TriggerType: entity.TriggerTypeManual,
+	}
+	if req != nil && HasPayloadOverride(req) {
+		if spec, ok := entity.LookupJobType(job.Type); ok {
+			if fields := validateJobPayload(spec, req.Payload); len(fields) > 0 {
+				return nil, &ValidationError{Fields: fields}
+			}
+		}
+		history.PayloadOverride = datatypes.JSON(req.Payload)
+	}
+
+	if err := s.taskPublisher.Publish(ctx, history); err != nil {
+		s.logger.Error("Failed to trigger job", logger.ErrorField(err), logger.Field("job_id", id))
 		return nil, err
 	}
 
-	s.logger.Info("Job updated successfully", logger.Field("job_id", id))
-	return s.mapToJobResponse(job), nil
+	s.logger.InfoContext(ctx, "Job triggered manually", logger.Field("job_id", id), logger.Field("history_id", history.ID))
+	return &dto.TriggerJobResponse{
+		ExecutionID: history.ID,
+		JobID:       history.JobID,
+		Status:      string(history.Status),
+		TriggerType: string(history.TriggerType),
+		StartedAt:   history.StartedAt,
+	}, nil
+}
+
+// HasPayloadOverride reports whether a trigger request overrides the payload of the job. A JSON
+// null is treated like an absent override, so the job runs with its own payload.
+func HasPayloadOverride(req *dto.TriggerJobRequest) bool {
+	payload := bytes.TrimSpace(req.Payload)
+	return len(payload) > 0 && !bytes.Equal(payload, []byte("null"))
+}
+
+// ListJobTypes returns every job type with the JSON schema of its payload.
+func (s *jobService) ListJobTypes() []dto.JobTypeResponse {
+	jobTypes := make([]dto.JobTypeResponse, 0, len(entity.JobTypeSpecs))
+	for _, spec := range entity.JobTypeSpecs {
+		jobTypes = append(jobTypes, dto.JobTypeResponse{
+			Type:          string(spec.Type),
+			Description:   spec.Description,
+			PayloadSchema: entity.SchemaFor(spec.Payload),
+		})
+	}
+	return jobTypes
+}
+
+// newTaskSchedule builds a task schedule entity from a job request schedule.
+func newTaskSchedule(sDto dto.ScheduleDTO) (entity.TaskSchedule, error) {
+	cronExpression, err := parseCronExpression(sDto.CronExpression)
+	if err != nil {
+		return entity.TaskSchedule{}, err
+	}
+	misfirePolicy, err := parseMisfirePolicy(sDto.MisfirePolicy)
+	if err != nil {
+		return entity.TaskSchedule{}, err
+	}
+	timezone, err := parseTimezone(sDto.Timezone)
+	if err != nil {
+		return entity.TaskSchedule{}, err
+	}
+
+	return entity.TaskSchedule{
+		CronExpression:      cronExpression,
+		IsActive:            sDto.IsActive,
+		Timezone:            timezone,
+		MisfirePolicy:       misfirePolicy,
+		MisfireGraceSeconds: sDto.MisfireGraceSeconds,
+		MisfireMaxRuns:      sDto.MisfireMaxRuns,
+	}, nil
+}
+
+// mergeSchedules builds the schedules of a job update. A requested schedule keeps the
+// identity, execution times, pause state and history of the existing schedule with its ID
+// or, when it has no ID, of an existing schedule with the same cron expression and time zone.
+func mergeSchedules(existing []entity.TaskSchedule, requested []dto.ScheduleDTO, now time.Time) ([]entity.TaskSchedule, error) {
+	byID := make(map[uint]bool, len(existing))
+	for _, schedule := range existing {
+		byID[schedule.ID] = true
+	}
+
+	schedules := make([]entity.TaskSchedule, len(requested))
+	kept := make(map[uint]bool)
+	var fields []dto.FieldError
+	for i, sDto := range requested {
+		schedule, err := newTaskSchedule(sDto)
+		if err != nil {
+			return nil, err
+		}
+		if sDto.ID != 0 {
+			switch {
+			case !byID[sDto.ID]:
+				fields = append(fields, dto.FieldError{Field: fmt.Sprintf("schedules[%d].id", i), Message: "is not a schedule of this job"})
+			case kept[sDto.ID]:
+				fields = append(fields, dto.FieldError{Field: fmt.Sprintf("schedules[%d].id", i), Message: "is listed more than once"})
+			}
+			schedule.ID = sDto.ID
+			kept[sDto.ID] = true
+		}
+		schedules[i] = schedule
+	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+
+	// Requests without schedule IDs, such as a job resubmitted as a whole, match unchanged schedules.
+	for i := range schedules {
+		if schedules[i].ID != 0 {
+			continue
+		}
+		for _, current := range existing {
+			if !kept[current.ID] && current.CronExpression == schedules[i].CronExpression && current.Timezone == schedules[i].Timezone {
+				schedules[i].ID = current.ID
+				kept[current.ID] = true
+				break
+			}
+		}
+	}
+
+	// Kept schedules whose cron expression or time zone changes continue from the next fire
+	// time of the new timing; the repository ignores this for unchanged ones.
+	for i := range schedules {
+		if schedules[i].ID == 0 {
+			continue
+		}
+		if cronSchedule, err := scheduleCronParser.Parse(schedules[i].CronExpression); err == nil {
+			next := cronSchedule.Next(now.In(schedules[i].Location()))
+			schedules[i].NextExecution = sql.NullTime{Time: next, Valid: true}
+		}
+	}
+	return schedules, nil
 }
 
 // mapToJobResponse maps an entity.Job to a dto.JobResponse.
@@ -148,26 +392,41 @@ func (s *jobService) mapToJobResponse(job *entity.Job) *dto.JobResponse {
 	_ = json.Unmarshal(job.RetryPolicy, &retryPolicy)
 
 	var schedules []dto.ScheduleResponseDTO
-	for _, schedule := range job.Schedules {
+	now := time.Now()
+	for i := range job.Schedules {
+		schedule := &job.Schedules[i]
+		paused, pausedUntil := pauseResponse(schedule.PauseState, now)
 		schedules = append(schedules, dto.ScheduleResponseDTO{
-			ID:             schedule.ID,
-			CronExpression: schedule.CronExpression,
-			IsActive:       schedule.IsActive,
-			NextExecution:  schedule.NextExecution,
-			LastExecution:  schedule.LastExecution,
+			ID:                  schedule.ID,
+			CronExpression:      schedule.CronExpression,
+			IsActive:            schedule.IsActive,
+			MisfirePolicy:       string(schedule.MisfirePolicy),
+			MisfireGraceSeconds: schedule.MisfireGraceSeconds,
+			MisfireMaxRuns:      schedule.MisfireMaxRuns,
+			Timezone:            schedule.Timezone,
+			NextExecution:       schedule.InLocation(schedule.NextExecution),
+			LastExecution:       schedule.InLocation(schedule.LastExecution),
+			Paused:              paused,
+			PausedUntil:         schedule.InLocation(pausedUntil),
+			UpcomingExecutions:  upcomingExecutions(schedule, job.PauseState, now),
 		})
 	}
+	jobPaused, jobPausedUntil := pauseResponse(job.PauseState, now)
 
 	return &dto.JobResponse{
-		ID:          job.ID,
-		Name:        job.Name,
-		Description: job.Description,
-		Type:        string(job.Type),
-		Payload:     json.RawMessage(job.Payload),
-		RetryPolicy: retryPolicy,
-		Timeout:     job.Timeout,
-		Schedules:   schedules,
-		CreatedAt:   job.CreatedAt,
-		UpdatedAt:   job.UpdatedAt,
+		ID:                job.ID,
+		Name:              job.Name,
+		Description:       job.Description,
+		Type:              string(job.Type),
+		Payload:           json.RawMessage(job.Payload),
+		RetryPolicy:       retryPolicy,
+		Timeout:           job.Timeout,
+		ConcurrencyPolicy: string(job.ConcurrencyPolicy),
+		Schedules:         schedules,
+		DependsOn:         job.DependsOnJobIDs(),
+		Paused:            jobPaused,
+		PausedUntil:       jobPausedUntil,
+		CreatedAt:         job.CreatedAt,
+		UpdatedAt:         job.UpdatedAt,
 	}
 }
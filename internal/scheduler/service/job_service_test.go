@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/internal/scheduler/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeSchedules(t *testing.T) {
+	now := time.Date(2025, 6, 2, 10, 30, 0, 0, time.UTC)
+	existing := []entity.TaskSchedule{
+		{ID: 1, CronExpression: "0 * * * *", Timezone: "Asia/Jakarta"},
+		{ID: 2, CronExpression: "0 9 * * 1-5", Timezone: "Asia/Jakarta"},
+		{ID: 3, CronExpression: "*/5 * * * *", Timezone: "Asia/Jakarta"},
+	}
+
+	schedules, err := mergeSchedules(existing, []dto.ScheduleDTO{
+		{ID: 2, CronExpression: "30 9 * * 1-5"},
+		{CronExpression: "0 * * * *"},
+		{CronExpression: "0 0 * * *"},
+	}, now)
+	require.NoError(t, err)
+	require.Len(t, schedules, 3)
+
+	assert.Equal(t, uint(2), schedules[0].ID, "kept by ID")
+	assert.Equal(t, "30 9 * * 1-5", schedules[0].CronExpression)
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	assert.Equal(t, sql.NullTime{Time: time.Date(2025, 6, 3, 9, 30, 0, 0, jakarta), Valid: true}, schedules[0].NextExecution)
+
+	assert.Equal(t, uint(1), schedules[1].ID, "matched by cron expression and time zone")
+	assert.Equal(t, uint(0), schedules[2].ID, "new schedule")
+	assert.False(t, schedules[2].NextExecution.Valid)
+}
+
+func TestMergeSchedules_InvalidIDs(t *testing.T) {
+	existing := []entity.TaskSchedule{{ID: 1, CronExpression: "0 * * * *", Timezone: "Asia/Jakarta"}}
+
+	_, err := mergeSchedules(existing, []dto.ScheduleDTO{
+		{ID: 1, CronExpression: "0 * * * *"},
+		{ID: 1, CronExpression: "0 0 * * *"},
+		{ID: 9, CronExpression: "0 0 * * *"},
+	}, time.Now())
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []dto.FieldError{
+		{Field: "schedules[1].id", Message: "is listed more than once"},
+		{Field: "schedules[2].id", Message: "is not a schedule of this job"},
+	}, validationErr.Fields)
+}
+
+// fakeJobRepository returns its job from FindByID; every other method panics.
+type fakeJobRepository struct {
+	repository.JobRepository
+	job *entity.Job
+}
+
+func (r *fakeJobRepository) FindByID(ctx context.Context, id uint) (*entity.Job, error) {
+	return r.job, nil
+}
+
+func TestTriggerJob_RejectsInvalidPayloadOverride(t *testing.T) {
+	job := &entity.Job{ID: 7, Type: entity.JobTypeStockNewsScraper}
+	svc := NewJobService(&fakeJobRepository{job: job}, nil, nil, nil, nil)
+
+	_, err := svc.TriggerJob(context.Background(), job.ID, &dto.TriggerJobRequest{Payload: json.RawMessage(`{"max_new": 3, "max_news": "3"}`)})
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	var fields []string
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	assert.ElementsMatch(t, []string{"payload.max_new", "payload.max_news"}, fields)
+}
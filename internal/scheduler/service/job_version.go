@@ -0,0 +1,308 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/internal/scheduler/repository"
+	"golang-stock-scryper/pkg/logger"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ListJobVersions lists the versions of a job, newest first, without their snapshots.
+func (s *jobService) ListJobVersions(ctx context.Context, id uint) ([]*dto.JobVersionResponse, error) {
+	versions, err := s.versionRepo.FindAllByJobID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to list job versions", logger.ErrorField(err), logger.Field("job_id", id))
+		return nil, err
+	}
+	// Jobs created before versions were recorded have none yet.
+	if len(versions) == 0 {
+		if _, err := s.jobRepo.FindByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	responses := make([]*dto.JobVersionResponse, 0, len(versions))
+	for i := range versions {
+		response := mapToJobVersionResponse(&versions[i])
+		response.Before, response.After = nil, nil
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// GetJobVersion retrieves a version of a job with the job definition before and after the change.
+func (s *jobService) GetJobVersion(ctx context.Context, id uint, version int) (*dto.JobVersionResponse, error) {
+	jobVersion, err := s.versionRepo.FindByJobIDAndVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	return mapToJobVersionResponse(jobVersion), nil
+}
+
+// DiffJobVersions compares the job definitions as they were after two versions.
+func (s *jobService) DiffJobVersions(ctx context.Context, id uint, req *dto.DiffJobVersionsRequest) (*dto.JobVersionDiffResponse, error) {
+	var fields []dto.FieldError
+	if req.From < 1 {
+		fields = append(fields, dto.FieldError{Field: "from", Message: "must be a version number"})
+	}
+	if req.To < 1 {
+		fields = append(fields, dto.FieldError{Field: "to", Message: "must be a version number"})
+	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+
+	from, err := s.versionRepo.FindByJobIDAndVersion(ctx, id, req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.versionRepo.FindByJobIDAndVersion(ctx, id, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := diffJSON(from.After, to.After)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.JobVersionDiffResponse{JobID: id, From: req.From, To: req.To, Changes: changes}, nil
+}
+
+// RollbackJob restores the job definition as it was after the given version and records the
+// result as a new version. A deleted job is restored under its old ID. Schedules that still
+// exist keep their identity and execution times; schedules deleted since are recreated.
+func (s *jobService) RollbackJob(ctx context.Context, id uint, version int) (*dto.JobResponse, error) {
+	err := s.txManager.WithTransaction(ctx, func(repos repository.Repositories) error {
+		target, err := repos.Versions.FindByJobIDAndVersion(ctx, id, version)
+		if err != nil {
+			return err
+		}
+		if len(target.After) == 0 {
+			return ErrRollbackToDeletedVersion
+		}
+		var snapshot entity.JobSnapshot
+		if err := json.Unmarshal(target.After, &snapshot); err != nil {
+			return err
+		}
+
+		current, err := repos.Jobs.FindByID(ctx, id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return createJob(ctx, repos, &entity.Job{ID: id}, snapshotRequest(snapshot, nil), entity.JobVersionRolledBack)
+		}
+		if err != nil {
+			return err
+		}
+		return updateJob(ctx, repos, current, snapshotRequest(snapshot, current.Schedules), entity.JobVersionRolledBack)
+	})
+	if err != nil {
+		s.logger.Error("Failed to roll back job", logger.ErrorField(err), logger.Field("job_id", id), logger.IntField("version", version))
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Job rolled back", logger.Field("job_id", id), logger.IntField("version", version))
+	return s.GetJobByID(ctx, id)
+}
+
+// recordJobVersion records a change to a job. before is nil for a created job and after is
+// nil for a deleted one.
+func recordJobVersion(ctx context.Context, repos repository.Repositories, jobID uint, action entity.JobVersionAction, before, after *entity.JobSnapshot) error {
+	version := &entity.JobVersion{JobID: jobID, Action: action, Actor: ActorFromContext(ctx)}
+	var err error
+	if version.Before, err = marshalSnapshot(before); err != nil {
+		return err
+	}
+	if version.After, err = marshalSnapshot(after); err != nil {
+		return err
+	}
+	return repos.Versions.Create(ctx, version)
+}
+
+func marshalSnapshot(snapshot *entity.JobSnapshot) (datatypes.JSON, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(data), nil
+}
+
+// snapshotRequest builds the job request that restores a snapshot. Only schedules that are
+// among existing keep their ID.
+func snapshotRequest(snapshot entity.JobSnapshot, existing []entity.TaskSchedule) *dto.CreateJobRequest {
+	req := &dto.CreateJobRequest{
+		Name:              snapshot.Name,
+		Description:       snapshot.Description,
+		Type:              string(snapshot.Type),
+		Payload:           snapshot.Payload,
+		Timeout:           snapshot.Timeout,
+		ConcurrencyPolicy: string(snapshot.ConcurrencyPolicy),
+		DependsOn:         snapshot.DependsOn,
+	}
+	_ = json.Unmarshal(snapshot.RetryPolicy, &req.RetryPolicy)
+
+	existingIDs := make(map[uint]bool, len(existing))
+	for _, schedule := range existing {
+		existingIDs[schedule.ID] = true
+	}
+	for _, schedule := range snapshot.Schedules {
+		sDto := dto.ScheduleDTO{
+			CronExpression:      schedule.CronExpression,
+			IsActive:            schedule.IsActive,
+			Timezone:            schedule.Timezone,
+			MisfirePolicy:       string(schedule.MisfirePolicy),
+			MisfireGraceSeconds: schedule.MisfireGraceSeconds,
+			MisfireMaxRuns:      schedule.MisfireMaxRuns,
+		}
+		if existingIDs[schedule.ID] {
+			sDto.ID = schedule.ID
+		}
+		req.Schedules = append(req.Schedules, sDto)
+	}
+	return req
+}
+
+func mapToJobVersionResponse(version *entity.JobVersion) *dto.JobVersionResponse {
+	return &dto.JobVersionResponse{
+		JobID:     version.JobID,
+		Version:   version.Version,
+		Action:    string(version.Action),
+		Actor:     version.Actor,
+		CreatedAt: version.CreatedAt,
+		Before:    json.RawMessage(version.Before),
+		After:     json.RawMessage(version.After),
+	}
+}
+
+// diffJSON lists the fields that differ between two JSON documents. An empty document is
+// treated as null. Arrays whose elements all are objects with an id, such as schedules, are
+// compared by id; other arrays are compared by index.
+func diffJSON(from, to []byte) ([]dto.VersionChange, error) {
+	fromValue, err := decodeJSON(from)
+	if err != nil {
+		return nil, err
+	}
+	toValue, err := decodeJSON(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []dto.VersionChange{}
+	diffValues("", fromValue, toValue, &changes)
+	return changes, nil
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func diffValues(path string, from, to interface{}, changes *[]dto.VersionChange) {
+	fromObject, fromIsObject := from.(map[string]interface{})
+	toObject, toIsObject := to.(map[string]interface{})
+	if fromIsObject && toIsObject {
+		keys := make([]string, 0, len(fromObject)+len(toObject))
+		for key := range fromObject {
+			keys = append(keys, key)
+		}
+		for key := range toObject {
+			if _, ok := fromObject[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			diffValues(childPath, fromObject[key], toObject[key], changes)
+		}
+		return
+	}
+
+	fromArray, fromIsArray := from.([]interface{})
+	toArray, toIsArray := to.([]interface{})
+	if fromIsArray && toIsArray {
+		fromByID, fromHasIDs := elementsByID(fromArray)
+		toByID, toHasIDs := elementsByID(toArray)
+		if fromHasIDs && toHasIDs {
+			ids := make([]string, 0, len(fromByID)+len(toByID))
+			for id := range fromByID {
+				ids = append(ids, id)
+			}
+			for id := range toByID {
+				if _, ok := fromByID[id]; !ok {
+					ids = append(ids, id)
+				}
+			}
+			// IDs are non-negative integers, so shorter ones sort first.
+			sort.Slice(ids, func(i, j int) bool {
+				if len(ids[i]) != len(ids[j]) {
+					return len(ids[i]) < len(ids[j])
+				}
+				return ids[i] < ids[j]
+			})
+			for _, id := range ids {
+				diffValues(fmt.Sprintf("%s[id=%s]", path, id), fromByID[id], toByID[id], changes)
+			}
+			return
+		}
+		for i := 0; i < len(fromArray) || i < len(toArray); i++ {
+			var fromElement, toElement interface{}
+			if i < len(fromArray) {
+				fromElement = fromArray[i]
+			}
+			if i < len(toArray) {
+				toElement = toArray[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), fromElement, toElement, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, dto.VersionChange{Path: path, From: from, To: to})
+	}
+}
+
+// elementsByID indexes the elements of an array by their id field. It reports false unless
+// every element is an object with a distinct, non-zero id.
+func elementsByID(array []interface{}) (map[string]interface{}, bool) {
+	byID := make(map[string]interface{}, len(array))
+	for _, element := range array {
+		object, ok := element.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		id, ok := object["id"].(json.Number)
+		if !ok || id.String() == "0" {
+			return nil, false
+		}
+		if _, duplicate := byID[id.String()]; duplicate {
+			return nil, false
+		}
+		byID[id.String()] = element
+	}
+	return byID, true
+}
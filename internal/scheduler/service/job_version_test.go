@@ -0,0 +1,91 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestDiffJSON(t *testing.T) {
+	from := `{
+		"name": "news",
+		"payload": {"max_news": 5, "blacklisted_domains": ["a.com", "b.com"]},
+		"schedules": [
+			{"id": 2, "cron_expression": "0 * * * *"},
+			{"id": 10, "cron_expression": "0 9 * * 1-5"}
+		]
+	}`
+	to := `{
+		"name": "news",
+		"payload": {"max_news": 10, "blacklisted_domains": ["a.com"], "use_stock_list": true},
+		"schedules": [
+			{"id": 10, "cron_expression": "30 9 * * 1-5"},
+			{"id": 11, "cron_expression": "0 0 * * *"}
+		]
+	}`
+
+	changes, err := diffJSON([]byte(from), []byte(to))
+	require.NoError(t, err)
+
+	var paths []string
+	for _, change := range changes {
+		paths = append(paths, change.Path)
+	}
+	assert.Equal(t, []string{
+		"payload.blacklisted_domains[1]",
+		"payload.max_news",
+		"payload.use_stock_list",
+		"schedules[id=2]",
+		"schedules[id=10].cron_expression",
+		"schedules[id=11]",
+	}, paths)
+	assert.Equal(t, dto.VersionChange{Path: "payload.max_news", From: json.Number("5"), To: json.Number("10")}, changes[1])
+	assert.Nil(t, changes[3].To)
+	assert.Nil(t, changes[5].From)
+
+	changes, err = diffJSON([]byte(from), []byte(from))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// The version that deleted a job has no job definition after it.
+	changes, err = diffJSON([]byte(from), nil)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "", changes[0].Path)
+}
+
+func TestSnapshotRequest(t *testing.T) {
+	job := &entity.Job{
+		Name:              "news",
+		Type:              entity.JobTypeStockNewsScraper,
+		Payload:           datatypes.JSON(`{"max_news": 5}`),
+		RetryPolicy:       datatypes.JSON(`{"max_retries": 3, "backoff_strategy": "fixed", "initial_interval": "1m"}`),
+		Timeout:           600,
+		ConcurrencyPolicy: entity.ConcurrencyPolicyForbid,
+		Schedules: []entity.TaskSchedule{
+			{ID: 1, CronExpression: "0 * * * *", IsActive: true, Timezone: "Asia/Jakarta", MisfirePolicy: entity.MisfirePolicyRunOnce},
+			{ID: 2, CronExpression: "0 9 * * 1-5", Timezone: "UTC", MisfirePolicy: entity.MisfirePolicySkip},
+		},
+		Dependencies: []entity.JobDependency{{DependsOnJobID: 7}},
+	}
+
+	// Schedule 2 has been deleted since the snapshot was taken.
+	req := snapshotRequest(entity.NewJobSnapshot(job), job.Schedules[:1])
+	assert.Equal(t, "news", req.Name)
+	assert.Equal(t, string(entity.JobTypeStockNewsScraper), req.Type)
+	assert.JSONEq(t, `{"max_news": 5}`, string(req.Payload))
+	assert.Equal(t, dto.RetryPolicyDTO{MaxRetries: 3, BackoffStrategy: "fixed", InitialInterval: "1m"}, req.RetryPolicy)
+	assert.Equal(t, 600, req.Timeout)
+	assert.Equal(t, "forbid", req.ConcurrencyPolicy)
+	assert.Equal(t, []uint{7}, req.DependsOn)
+	assert.Equal(t, []dto.ScheduleDTO{
+		{ID: 1, CronExpression: "0 * * * *", IsActive: true, Timezone: "Asia/Jakarta", MisfirePolicy: "run_once"},
+		{CronExpression: "0 9 * * 1-5", Timezone: "UTC", MisfirePolicy: "skip"},
+	}, req.Schedules)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+)
+
+// jwtClaims are the claims read from a bearer token. Numeric dates are seconds since the epoch.
+type jwtClaims struct {
+	Subject   string      `json:"sub"`
+	Role      entity.Role `json:"role"`
+	Issuer    string      `json:"iss"`
+	ExpiresAt *float64    `json:"exp"`
+	NotBefore *float64    `json:"nbf"`
+}
+
+// parseJWT verifies an HS256-signed JWT and returns its claims. Tokens must expire, name their
+// subject and carry a known role; when issuer is not empty, they must also be issued by it.
+func parseJWT(token string, secret []byte, issuer string, now time.Time) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token must have three parts")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("malformed token header")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, errors.New("malformed token header")
+	}
+	// The algorithm is fixed rather than taken from the header, so a token cannot downgrade it.
+	if header.Alg != "HS256" {
+		return nil, errors.New("token must be signed with HS256")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("invalid token signature")
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, errors.New("malformed token claims")
+	}
+
+	switch {
+	case claims.ExpiresAt == nil:
+		return nil, errors.New("token must have an exp claim")
+	case !now.Before(time.Unix(int64(*claims.ExpiresAt), 0)):
+		return nil, errors.New("token has expired")
+	case claims.NotBefore != nil && now.Before(time.Unix(int64(*claims.NotBefore), 0)):
+		return nil, errors.New("token is not valid yet")
+	case issuer != "" && claims.Issuer != issuer:
+		return nil, errors.New("token has an unexpected issuer")
+	case strings.TrimSpace(claims.Subject) == "":
+		return nil, errors.New("token must have a sub claim")
+	case !claims.Role.IsValid():
+		return nil, errors.New("token role must be one of viewer, operator, admin")
+	}
+	return &claims, nil
+}
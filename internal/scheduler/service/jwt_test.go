@@ -0,0 +1,113 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signTestJWT builds a JWT with the given header algorithm and claims, signed with HS256.
+func signTestJWT(t *testing.T, alg string, claims map[string]interface{}, secret string) string {
+	t.Helper()
+	header, err := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	require.NoError(t, err)
+	body, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseJWT(t *testing.T) {
+	const secret = "test-secret"
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{"sub": "ci-pipeline", "role": "operator", "iss": "auth.example.com", "exp": now.Add(time.Hour).Unix()}
+	}
+
+	claims, err := parseJWT(signTestJWT(t, "HS256", validClaims(), secret), []byte(secret), "auth.example.com", now)
+	require.NoError(t, err)
+	assert.Equal(t, "ci-pipeline", claims.Subject)
+	assert.Equal(t, entity.RoleOperator, claims.Role)
+
+	tests := []struct {
+		name   string
+		token  func() string
+		issuer string
+	}{
+		{
+			name:  "wrong secret",
+			token: func() string { return signTestJWT(t, "HS256", validClaims(), "other-secret") },
+		},
+		{
+			name:  "other algorithm",
+			token: func() string { return signTestJWT(t, "none", validClaims(), secret) },
+		},
+		{
+			name: "expired",
+			token: func() string {
+				claims := validClaims()
+				claims["exp"] = now.Unix()
+				return signTestJWT(t, "HS256", claims, secret)
+			},
+		},
+		{
+			name: "without expiry",
+			token: func() string {
+				claims := validClaims()
+				delete(claims, "exp")
+				return signTestJWT(t, "HS256", claims, secret)
+			},
+		},
+		{
+			name: "not valid yet",
+			token: func() string {
+				claims := validClaims()
+				claims["nbf"] = now.Add(time.Minute).Unix()
+				return signTestJWT(t, "HS256", claims, secret)
+			},
+		},
+		{
+			name: "unknown role",
+			token: func() string {
+				claims := validClaims()
+				claims["role"] = "root"
+				return signTestJWT(t, "HS256", claims, secret)
+			},
+		},
+		{
+			name: "without subject",
+			token: func() string {
+				claims := validClaims()
+				delete(claims, "sub")
+				return signTestJWT(t, "HS256", claims, secret)
+			},
+		},
+		{
+			name:   "other issuer",
+			token:  func() string { return signTestJWT(t, "HS256", validClaims(), secret) },
+			issuer: "other.example.com",
+		},
+		{
+			name:  "malformed",
+			token: func() string { return "not.a.jwt" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseJWT(tt.token(), []byte(secret), tt.issuer, now)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Results of handing an execution over to the execution service.
+const (
+	publishResultSuccess = "success"
+	publishResultFailure = "failure"
+)
+
+var tasksPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "scheduler_tasks_published_total",
+	Help: "Executions handed over to the execution service, by trigger type and result.",
+}, []string{"trigger_type", "result"})
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	defaultMisfireMaxRuns = 10
+	// maxMisfireScan bounds the walk over missed fire times after a very long downtime.
+	maxMisfireScan = 100000
+)
+
+// dueRuns returns the fire times to publish for a claimed schedule according to its misfire policy.
+// A run is considered missed when it is later than the schedule's grace period, which is never
+// shorter than minGrace (the polling interval), since every run is picked up a little late.
+// now must be in the schedule's time zone; fire times are computed in that zone.
+func dueRuns(schedule *entity.TaskSchedule, cronSchedule cron.Schedule, now time.Time, minGrace time.Duration) []time.Time {
+	if !schedule.NextExecution.Valid {
+		return []time.Time{now}
+	}
+
+	first := schedule.NextExecution.Time.In(now.Location())
+	grace := time.Duration(schedule.MisfireGraceSeconds) * time.Second
+	if grace < minGrace {
+		grace = minGrace
+	}
+	if now.Sub(first) <= grace {
+		return []time.Time{first}
+	}
+
+	switch schedule.MisfirePolicy {
+	case entity.MisfirePolicySkip:
+		return nil
+	case entity.MisfirePolicyRunAll:
+		maxRuns := schedule.MisfireMaxRuns
+		if maxRuns <= 0 {
+			maxRuns = defaultMisfireMaxRuns
+		}
+		missed := missedFireTimes(cronSchedule, first, now)
+		if len(missed) > maxRuns {
+			missed = missed[len(missed)-maxRuns:]
+		}
+		return missed
+	default:
+		missed := missedFireTimes(cronSchedule, first, now)
+		return missed[len(missed)-1:]
+	}
+}
+
+// missedFireTimes lists the fire times from first up to now, always including first.
+func missedFireTimes(cronSchedule cron.Schedule, first, now time.Time) []time.Time {
+	missed := []time.Time{first}
+	for t := cronSchedule.Next(first); !t.IsZero() && !t.After(now) && len(missed) < maxMisfireScan; t = cronSchedule.Next(t) {
+		missed = append(missed, t)
+	}
+	return missed
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDueRuns(t *testing.T) {
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	hourly, _ := parser.Parse("0 * * * *")
+	now := time.Date(2025, 6, 17, 12, 30, 0, 0, time.UTC)
+	nextExecution := func(t time.Time) sql.NullTime { return sql.NullTime{Time: t, Valid: true} }
+
+	tests := []struct {
+		name     string
+		schedule entity.TaskSchedule
+		want     []time.Time
+	}{
+		{
+			name:     "new schedule runs now",
+			schedule: entity.TaskSchedule{},
+			want:     []time.Time{now},
+		},
+		{
+			name: "run within grace period is not a misfire",
+			schedule: entity.TaskSchedule{
+				MisfirePolicy: entity.MisfirePolicySkip,
+				NextExecution: nextExecution(now.Add(-3 * time.Second)),
+			},
+			want: []time.Time{now.Add(-3 * time.Second)},
+		},
+		{
+			name: "skip drops late runs",
+			schedule: entity.TaskSchedule{
+				MisfirePolicy:       entity.MisfirePolicySkip,
+				MisfireGraceSeconds: 60,
+				NextExecution:       nextExecution(time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)),
+			},
+			want: nil,
+		},
+		{
+			name: "run once collapses missed runs into the latest",
+			schedule: entity.TaskSchedule{
+				MisfirePolicy: entity.MisfirePolicyRunOnce,
+				NextExecution: nextExecution(time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)),
+			},
+			want: []time.Time{time.Date(2025, 6, 17, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "run all keeps the latest max runs",
+			schedule: entity.TaskSchedule{
+				MisfirePolicy:  entity.MisfirePolicyRunAll,
+				MisfireMaxRuns: 2,
+				NextExecution:  nextExecution(time.Date(2025, 6, 17, 9, 0, 0, 0, time.UTC)),
+			},
+			want: []time.Time{
+				time.Date(2025, 6, 17, 11, 0, 0, 0, time.UTC),
+				time.Date(2025, 6, 17, 12, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dueRuns(&tt.schedule, hourly, now, 5*time.Second))
+		})
+	}
+}
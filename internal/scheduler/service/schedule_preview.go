@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	defaultPreviewCount = 10
+	maxPreviewCount     = 100
+	// upcomingExecutionCount is the number of fire times listed in schedule responses.
+	upcomingExecutionCount = 5
+	// maxFireTimeScan bounds the walk over fire times that are skipped while paused.
+	maxFireTimeScan = 10000
+)
+
+// PreviewSchedule validates a cron expression and time zone and lists their next fire times,
+// evaluated exactly as the scheduler does.
+func (s *scheduleService) PreviewSchedule(req *dto.PreviewScheduleRequest) (*dto.PreviewScheduleResponse, error) {
+	var fields []dto.FieldError
+	cronSchedule, err := scheduleCronParser.Parse(req.CronExpression)
+	if err != nil {
+		fields = append(fields, dto.FieldError{Field: "cron_expression", Message: err.Error()})
+	}
+	timezone, err := parseTimezone(req.Timezone)
+	if err != nil {
+		fields = append(fields, dto.FieldError{Field: "timezone", Message: "must be an IANA time zone name such as Asia/Jakarta"})
+	}
+	count := req.Count
+	if count == 0 {
+		count = defaultPreviewCount
+	}
+	if count < 0 || count > maxPreviewCount {
+		fields = append(fields, dto.FieldError{Field: "count", Message: fmt.Sprintf("must be between 1 and %d", maxPreviewCount)})
+	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+
+	schedule := entity.TaskSchedule{CronExpression: req.CronExpression, Timezone: timezone}
+	from := time.Now()
+	if req.From != nil {
+		from = *req.From
+	}
+
+	return &dto.PreviewScheduleResponse{
+		CronExpression: req.CronExpression,
+		Timezone:       timezone,
+		FireTimes:      fireTimes(cronSchedule, from.In(schedule.Location()), count, nil),
+	}, nil
+}
+
+// upcomingExecutions lists the next fire times of a schedule after now, leaving out runs the
+// scheduler will skip because the schedule or its job is paused. Inactive schedules and
+// schedules with a broken cron expression have none.
+func upcomingExecutions(schedule *entity.TaskSchedule, jobPause entity.PauseState, now time.Time) []time.Time {
+	if !schedule.IsActive {
+		return []time.Time{}
+	}
+	cronSchedule, err := scheduleCronParser.Parse(schedule.CronExpression)
+	if err != nil {
+		return []time.Time{}
+	}
+	paused := func(t time.Time) bool {
+		return schedule.IsPausedAt(t) || jobPause.IsPausedAt(t)
+	}
+	return fireTimes(cronSchedule, now.In(schedule.Location()), upcomingExecutionCount, paused)
+}
+
+// fireTimes returns up to n fire times after from, leaving out those for which skip reports true.
+func fireTimes(cronSchedule cron.Schedule, from time.Time, n int, skip func(time.Time) bool) []time.Time {
+	times := make([]time.Time, 0, n)
+	t := from
+	for scanned := 0; len(times) < n && scanned < maxFireTimeScan; scanned++ {
+		t = cronSchedule.Next(t)
+		if t.IsZero() {
+			break
+		}
+		if skip != nil && skip(t) {
+			continue
+		}
+		times = append(times, t)
+	}
+	return times
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewSchedule(t *testing.T) {
+	s := &scheduleService{}
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+	// Friday 15:00 WIB
+	from := time.Date(2025, 6, 6, 8, 0, 0, 0, time.UTC)
+
+	preview, err := s.PreviewSchedule(&dto.PreviewScheduleRequest{
+		CronExpression: "0 9 * * 1-5",
+		Count:          3,
+		From:           &from,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Jakarta", preview.Timezone)
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 6, 9, 9, 0, 0, 0, jakarta),
+		time.Date(2025, 6, 10, 9, 0, 0, 0, jakarta),
+		time.Date(2025, 6, 11, 9, 0, 0, 0, jakarta),
+	}, preview.FireTimes)
+
+	_, err = s.PreviewSchedule(&dto.PreviewScheduleRequest{CronExpression: "0 0 9 * * 1-5", Timezone: "Local", Count: 1000})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	var fields []string
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	assert.Equal(t, []string{"cron_expression", "timezone", "count"}, fields)
+}
+
+func TestUpcomingExecutions(t *testing.T) {
+	now := time.Date(2025, 6, 2, 10, 30, 0, 0, time.UTC)
+	schedule := &entity.TaskSchedule{CronExpression: "0 * * * *", Timezone: "UTC", IsActive: true}
+
+	upcoming := upcomingExecutions(schedule, entity.PauseState{}, now)
+	require.Len(t, upcoming, upcomingExecutionCount)
+	assert.Equal(t, time.Date(2025, 6, 2, 11, 0, 0, 0, time.UTC), upcoming[0])
+
+	jobPause := entity.PauseState{Paused: true, PausedUntil: sql.NullTime{Time: now.Add(2 * time.Hour), Valid: true}}
+	upcoming = upcomingExecutions(schedule, jobPause, now)
+	assert.Equal(t, time.Date(2025, 6, 2, 13, 0, 0, 0, time.UTC), upcoming[0], "runs during the pause are left out")
+
+	schedule.PauseState = entity.PauseState{Paused: true}
+	assert.Empty(t, upcomingExecutions(schedule, entity.PauseState{}, now), "paused until resumed")
+
+	schedule.PauseState = entity.PauseState{}
+	schedule.IsActive = false
+	assert.Empty(t, upcomingExecutions(schedule, entity.PauseState{}, now))
+}
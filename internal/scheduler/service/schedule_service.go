@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"golang-stock-scryper/internal/entity"
 	"golang-stock-scryper/internal/scheduler/dto"
 	"golang-stock-scryper/internal/scheduler/repository"
 	"golang-stock-scryper/pkg/logger"
+
+	"gorm.io/gorm"
 )
 
 // ScheduleService defines the interface for managing schedules.
@@ -15,35 +21,60 @@ type ScheduleService interface {
 	GetAllSchedules(ctx context.Context) ([]*dto.ScheduleResponse, error)
 	UpdateSchedule(ctx context.Context, id uint, req *dto.UpdateScheduleRequest) (*dto.ScheduleResponse, error)
 	DeleteSchedule(ctx context.Context, id uint) error
+	PauseSchedule(ctx context.Context, id uint, req *dto.PauseRequest) (*dto.ScheduleResponse, error)
+	ResumeSchedule(ctx context.Context, id uint) (*dto.ScheduleResponse, error)
+	PreviewSchedule(req *dto.PreviewScheduleRequest) (*dto.PreviewScheduleResponse, error)
 }
 
 // NewScheduleService creates a new schedule service.
-func NewScheduleService(scheduleRepo repository.TaskScheduleRepository, logger *logger.Logger) ScheduleService {
+func NewScheduleService(scheduleRepo repository.TaskScheduleRepository, txManager repository.TransactionManager, logger *logger.Logger) ScheduleService {
 	return &scheduleService{
 		scheduleRepo: scheduleRepo,
+		txManager:    txManager,
 		logger:       logger,
 	}
 }
 
 type scheduleService struct {
 	scheduleRepo repository.TaskScheduleRepository
+	txManager    repository.TransactionManager
 	logger       *logger.Logger
 }
 
 // CreateSchedule handles the business logic for creating a new schedule.
 func (s *scheduleService) CreateSchedule(ctx context.Context, req *dto.CreateScheduleRequest) (*dto.ScheduleResponse, error) {
+	cronExpression, err := parseCronExpression(req.CronExpression)
+	if err != nil {
+		return nil, err
+	}
+	misfirePolicy, err := parseMisfirePolicy(req.MisfirePolicy)
+	if err != nil {
+		return nil, err
+	}
+	timezone, err := parseTimezone(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
 	schedule := &entity.TaskSchedule{
-		JobID:          req.JobID,
-		CronExpression: req.CronExpression,
-		IsActive:       req.IsActive,
+		JobID:               req.JobID,
+		CronExpression:      cronExpression,
+		IsActive:            req.IsActive,
+		Timezone:            timezone,
+		MisfirePolicy:       misfirePolicy,
+		MisfireGraceSeconds: req.MisfireGraceSeconds,
+		MisfireMaxRuns:      req.MisfireMaxRuns,
 	}
 
-	if err := s.scheduleRepo.Create(ctx, schedule); err != nil {
+	err = s.changeJobSchedules(ctx, schedule.JobID, entity.JobVersionScheduleCreated, func(repos repository.Repositories) error {
+		return repos.Schedules.Create(ctx, schedule)
+	})
+	if err != nil {
 		s.logger.Error("Failed to create schedule", logger.ErrorField(err))
 		return nil, err
 	}
 
-	s.logger.Info("Schedule created successfully", logger.Field("schedule_id", schedule.ID))
+	s.logger.InfoContext(ctx, "Schedule created successfully", logger.Field("schedule_id", schedule.ID))
 	return s.mapToScheduleResponse(schedule), nil
 }
 
@@ -81,39 +112,127 @@ func (s *scheduleService) UpdateSchedule(ctx context.Context, id uint, req *dto.
 		return nil, err
 	}
 
-	schedule.CronExpression = req.CronExpression
-	schedule.IsActive = req.IsActive
+	cronExpression, err := parseCronExpression(req.CronExpression)
+	if err != nil {
+		return nil, err
+	}
+	misfirePolicy, err := parseMisfirePolicy(req.MisfirePolicy)
+	if err != nil {
+		return nil, err
+	}
+	timezone, err := parseTimezone(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+	schedule.CronExpression = cronExpression
+	schedule.IsActive = req.IsActive
+	schedule.Timezone = timezone
+	schedule.MisfirePolicy = misfirePolicy
+	schedule.MisfireGraceSeconds = req.MisfireGraceSeconds
+	schedule.MisfireMaxRuns = req.MisfireMaxRuns
+
+	err = s.changeJobSchedules(ctx, schedule.JobID, entity.JobVersionScheduleUpdated, func(repos repository.Repositories) error {
+		return repos.Schedules.Update(ctx, schedule)
+	})
+	if err != nil {
 		s.logger.Error("Failed to update schedule", logger.ErrorField(err), logger.Field("schedule_id", id))
 		return nil, err
 	}
 
-	s.logger.Info("Schedule updated successfully", logger.Field("schedule_id", id))
+	s.logger.InfoContext(ctx, "Schedule updated successfully", logger.Field("schedule_id", id))
 	return s.mapToScheduleResponse(schedule), nil
 }
 
-// DeleteSchedule deletes a schedule by its ID.
+// DeleteSchedule deletes a schedule by its ID. Deleting a schedule that does not exist succeeds.
 func (s *scheduleService) DeleteSchedule(ctx context.Context, id uint) error {
-	err := s.scheduleRepo.Delete(ctx, id)
+	schedule, err := s.scheduleRepo.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err == nil {
+		err = s.changeJobSchedules(ctx, schedule.JobID, entity.JobVersionScheduleDeleted, func(repos repository.Repositories) error {
+			return repos.Schedules.Delete(ctx, id)
+		})
+	}
 	if err != nil {
 		s.logger.Error("Failed to delete schedule", logger.ErrorField(err), logger.Field("schedule_id", id))
 		return err
 	}
-	s.logger.Info("Schedule deleted successfully", logger.Field("schedule_id", id))
+	s.logger.InfoContext(ctx, "Schedule deleted successfully", logger.Field("schedule_id", id))
 	return nil
 }
 
+// PauseSchedule pauses a schedule, either until it is resumed or until req.Until.
+// Runs that fall in the pause are skipped.
+func (s *scheduleService) PauseSchedule(ctx context.Context, id uint, req *dto.PauseRequest) (*dto.ScheduleResponse, error) {
+	pause, err := newPauseState(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.scheduleRepo.UpdatePause(ctx, id, pause); err != nil {
+		s.logger.Error("Failed to pause schedule", logger.ErrorField(err), logger.Field("schedule_id", id))
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "Schedule paused", logger.Field("schedule_id", id), logger.Field("paused_until", pause.PausedUntil.Time))
+	return s.GetScheduleByID(ctx, id)
+}
+
+// ResumeSchedule resumes a paused schedule. It continues from its next fire time.
+func (s *scheduleService) ResumeSchedule(ctx context.Context, id uint) (*dto.ScheduleResponse, error) {
+	if err := s.scheduleRepo.UpdatePause(ctx, id, entity.PauseState{}); err != nil {
+		s.logger.Error("Failed to resume schedule", logger.ErrorField(err), logger.Field("schedule_id", id))
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "Schedule resumed", logger.Field("schedule_id", id))
+	return s.GetScheduleByID(ctx, id)
+}
+
+// changeJobSchedules applies a change to the schedules of a job and records it as a new
+// version of the job, in one transaction.
+func (s *scheduleService) changeJobSchedules(ctx context.Context, jobID uint, action entity.JobVersionAction, change func(repos repository.Repositories) error) error {
+	return s.txManager.WithTransaction(ctx, func(repos repository.Repositories) error {
+		job, err := repos.Jobs.FindByID(ctx, jobID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: job %d does not exist", ErrInvalidInput, jobID)
+		}
+		if err != nil {
+			return err
+		}
+		before := entity.NewJobSnapshot(job)
+
+		if err := change(repos); err != nil {
+			return err
+		}
+
+		job, err = repos.Jobs.FindByID(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		after := entity.NewJobSnapshot(job)
+		return recordJobVersion(ctx, repos, jobID, action, &before, &after)
+	})
+}
+
 // mapToScheduleResponse maps an entity.TaskSchedule to a dto.ScheduleResponse.
 func (s *scheduleService) mapToScheduleResponse(schedule *entity.TaskSchedule) *dto.ScheduleResponse {
+	now := time.Now()
+	paused, pausedUntil := pauseResponse(schedule.PauseState, now)
 	return &dto.ScheduleResponse{
-		ID:             schedule.ID,
-		JobID:          schedule.JobID,
-		CronExpression: schedule.CronExpression,
-		IsActive:       schedule.IsActive,
-		NextExecution:  schedule.NextExecution,
-		LastExecution:  schedule.LastExecution,
-		CreatedAt:      schedule.CreatedAt,
-		UpdatedAt:      schedule.UpdatedAt,
+		ID:                  schedule.ID,
+		JobID:               schedule.JobID,
+		CronExpression:      schedule.CronExpression,
+		IsActive:            schedule.IsActive,
+		MisfirePolicy:       string(schedule.MisfirePolicy),
+		MisfireGraceSeconds: schedule.MisfireGraceSeconds,
+		MisfireMaxRuns:      schedule.MisfireMaxRuns,
+		Timezone:            schedule.Timezone,
+		NextExecution:       schedule.InLocation(schedule.NextExecution),
+		LastExecution:       schedule.InLocation(schedule.LastExecution),
+		Paused:              paused,
+		PausedUntil:         schedule.InLocation(pausedUntil),
+		UpcomingExecutions:  upcomingExecutions(schedule, entity.PauseState{}, now),
+		CreatedAt:           schedule.CreatedAt,
+		UpdatedAt:           schedule.UpdatedAt,
 	}
 }
@@ -3,19 +3,18 @@ package service
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"time"
 
 	"golang-stock-scryper/internal/entity"
 	"golang-stock-scryper/internal/scheduler/config"
 	"golang-stock-scryper/internal/scheduler/repository"
-	"golang-stock-scryper/pkg/common"
 	"golang-stock-scryper/pkg/logger"
 
-	"github.com/redis/go-redis/v9"
 	"github.com/robfig/cron/v3"
 )
 
+const defaultClaimBatchSize = 100
+
 // SchedulerService defines the interface for the job scheduling service.
 type SchedulerService interface {
 	Start(ctx context.Context)
@@ -23,15 +22,15 @@ type SchedulerService interface {
 }
 
 // NewSchedulerService creates a new scheduler service.
-func NewSchedulerService(jobRepo repository.JobRepository, scheduleRepo repository.TaskScheduleRepository, historyRepo repository.TaskExecutionHistoryRepository, redisClient *redis.Client, logger *logger.Logger, pollingInterval time.Duration, cfg *config.Config) SchedulerService {
+func NewSchedulerService(jobRepo repository.JobRepository, scheduleRepo repository.TaskScheduleRepository, historyRepo repository.TaskExecutionHistoryRepository, taskPublisher TaskPublisher, logger *logger.Logger, pollingInterval time.Duration, cfg *config.Config) SchedulerService {
 	return &schedulerService{
 		jobRepo:         jobRepo,
 		scheduleRepo:    scheduleRepo,
 		historyRepo:     historyRepo,
-		redisClient:     redisClient,
+		taskPublisher:   taskPublisher,
 		logger:          logger,
 		pollingInterval: pollingInterval,
-		cronParser:      cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor),
+		cronParser:      scheduleCronParser,
 		cfg:             cfg,
 	}
 }
@@ -40,7 +39,7 @@ type schedulerService struct {
 	jobRepo         repository.JobRepository
 	scheduleRepo    repository.TaskScheduleRepository
 	historyRepo     repository.TaskExecutionHistoryRepository
-	redisClient     *redis.Client
+	taskPublisher   TaskPublisher
 	logger          *logger.Logger
 	pollingInterval time.Duration
 	cronParser      cron.Parser
@@ -63,72 +62,83 @@ func (s *schedulerService) Start(ctx context.Context) {
 	}
 }
 
-// ProcessJobs finds and enqueues jobs that are due.
+// ProcessJobs claims and enqueues jobs that are due.
+// Schedules are claimed, published and advanced to their next execution in a single
+// transaction, so several scheduler instances can run side by side without publishing
+// the same run twice, and a run whose publish fails stays due for the next tick.
 func (s *schedulerService) ProcessJobs(ctx context.Context) {
-	schedules, err := s.scheduleRepo.FindJobsToSchedule(ctx)
-	if err != nil {
-		s.logger.Error("Failed to find jobs to schedule", logger.ErrorField(err))
-		return
-	}
-
-	for _, schedule := range schedules {
-		s.publishTask(ctx, schedule)
-	}
-}
-
-func (s *schedulerService) publishTask(ctx context.Context, schedule entity.TaskSchedule) {
 	now := time.Now()
+	_, err := s.scheduleRepo.ClaimJobsToSchedule(ctx, now, s.claimBatchSize(), func(schedule *entity.TaskSchedule, jobPaused bool) {
+		cronSchedule, err := s.cronParser.Parse(schedule.CronExpression)
+		if err != nil {
+			// A broken schedule would otherwise stay due and be claimed first on every tick.
+			s.logger.Error("Deactivating schedule with invalid cron expression", logger.ErrorField(err), logger.Field("schedule_id", schedule.ID))
+			schedule.IsActive = false
+			return
+		}
 
-	history := &entity.TaskExecutionHistory{
-		JobID:      schedule.JobID,
-		ScheduleID: schedule.ID,
-		Status:     entity.StatusRunning, // Or a new "Queued" status
-		StartedAt:  now,
-	}
-
-	if err := s.historyRepo.Create(ctx, history); err != nil {
-		s.logger.Error("Failed to create task history", logger.ErrorField(err), logger.Field("schedule_id", schedule.ID))
-		return
-	}
+		// Cron expressions are evaluated in the schedule's own time zone.
+		localNow := now.In(schedule.Location())
+
+		// Runs that fall in a pause are skipped rather than caught up by the misfire policy
+		// once the pause ends.
+		if jobPaused || schedule.IsPausedAt(now) {
+			s.logger.Info("Skipping paused schedule run",
+				logger.Field("schedule_id", schedule.ID),
+				logger.Field("job_id", schedule.JobID),
+				logger.Field("job_paused", jobPaused))
+			schedule.NextExecution = sql.NullTime{Time: cronSchedule.Next(localNow), Valid: true}
+			return
+		}
+		runs := dueRuns(schedule, cronSchedule, localNow, s.pollingInterval)
+		if len(runs) == 0 {
+			s.logger.Warn("Skipping missed schedule run",
+				logger.Field("schedule_id", schedule.ID),
+				logger.Field("missed_execution", schedule.NextExecution.Time),
+				logger.Field("misfire_policy", schedule.MisfirePolicy))
+		}
 
-	taskPayload, err := json.Marshal(history) // Pass history object to executor
+		for i, scheduledAt := range runs {
+			if err := s.publishTask(ctx, *schedule, scheduledAt, now); err != nil {
+				// Keep the schedule due from the first unpublished run so it is retried on the next tick.
+				schedule.NextExecution = sql.NullTime{Time: scheduledAt, Valid: true}
+				if i > 0 {
+					schedule.LastExecution = sql.NullTime{Time: now, Valid: true}
+				}
+				return
+			}
+		}
+		if len(runs) > 0 {
+			schedule.LastExecution.Time = now
+			schedule.LastExecution.Valid = true
+		}
+		schedule.NextExecution.Time = cronSchedule.Next(localNow)
+		schedule.NextExecution.Valid = true
+	})
 	if err != nil {
-		s.logger.Error("Failed to marshal task payload", logger.ErrorField(err), logger.Field("history_id", history.ID))
-		return
+		s.logger.Error("Failed to claim jobs to schedule", logger.ErrorField(err))
 	}
+}
 
-	if err := s.redisClient.XAdd(ctx, &redis.XAddArgs{
-		Stream: common.RedisStreamSchedulerTaskExecution,
-		Values: map[string]interface{}{"payload": taskPayload},
-		MaxLen: s.cfg.Redis.StreamMaxLen, // Limit the stream size
-	}).Err(); err != nil {
-		s.logger.Error("Failed to enqueue task", logger.ErrorField(err), logger.Field("history_id", history.ID))
-		history.Status = entity.StatusFailed
-		history.CompletedAt.Time = time.Now()
-		history.CompletedAt.Valid = true
-		history.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
-		errInner := s.historyRepo.Update(ctx, history)
-		if errInner != nil {
-			s.logger.Error("Failed to update task history", logger.ErrorField(errInner), logger.Field("history_id", history.ID))
-		}
-		return
+func (s *schedulerService) publishTask(ctx context.Context, schedule entity.TaskSchedule, scheduledAt time.Time, now time.Time) error {
+	history := &entity.TaskExecutionHistory{
+		JobID:       schedule.JobID,
+		ScheduleID:  &schedule.ID,
+		ScheduledAt: sql.NullTime{Time: scheduledAt, Valid: true},
+		StartedAt:   now,
+		TriggerType: entity.TriggerTypeSchedule,
 	}
 
-	s.logger.Info("Task published successfully", logger.Field("history_id", history.ID))
-
-	// Update schedule for next run
-	cronSchedule, err := s.cronParser.Parse(schedule.CronExpression)
-	if err != nil {
-		s.logger.Error("Failed to parse cron expression", logger.ErrorField(err), logger.Field("schedule_id", schedule.ID))
-		return
+	if err := s.taskPublisher.Publish(ctx, history); err != nil {
+		s.logger.Error("Failed to publish scheduled task", logger.ErrorField(err), logger.Field("schedule_id", schedule.ID))
+		return err
 	}
+	return nil
+}
 
-	schedule.LastExecution.Time = now
-	schedule.LastExecution.Valid = true
-	schedule.NextExecution.Time = cronSchedule.Next(now)
-	schedule.NextExecution.Valid = true
-
-	if err := s.scheduleRepo.Update(ctx, &schedule); err != nil {
-		s.logger.Error("Failed to update next execution time", logger.ErrorField(err), logger.Field("schedule_id", schedule.ID))
+func (s *schedulerService) claimBatchSize() int {
+	if s.cfg.Scheduler.ClaimBatchSize > 0 {
+		return s.cfg.Scheduler.ClaimBatchSize
 	}
+	return defaultClaimBatchSize
 }
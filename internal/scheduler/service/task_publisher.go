@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/config"
+	"golang-stock-scryper/internal/scheduler/repository"
+	"golang-stock-scryper/pkg/common"
+	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/tracing"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TaskPublisher records an execution history and hands it over to the execution service.
+type TaskPublisher interface {
+	Publish(ctx context.Context, history *entity.TaskExecutionHistory) error
+	Cancel(ctx context.Context, historyID uint) error
+}
+
+// NewTaskPublisher creates a new Redis stream based task publisher.
+func NewTaskPublisher(historyRepo repository.TaskExecutionHistoryRepository, webhookRepo repository.WebhookRepository, redisClient *redis.Client, logger *logger.Logger, cfg *config.Config) TaskPublisher {
+	return &taskPublisher{
+		historyRepo: historyRepo,
+		webhookRepo: webhookRepo,
+		redisClient: redisClient,
+		logger:      logger,
+		cfg:         cfg,
+	}
+}
+
+type taskPublisher struct {
+	historyRepo repository.TaskExecutionHistoryRepository
+	webhookRepo repository.WebhookRepository
+	redisClient *redis.Client
+	logger      *logger.Logger
+	cfg         *config.Config
+}
+
+// Publish creates the history record and enqueues it to the task execution stream.
+// If enqueueing fails, the history is marked as failed and the error is returned.
+// Webhook subscriptions are notified of the queued execution, and of its failure.
+// The trace context of the publish span travels with the task to the executor.
+func (p *taskPublisher) Publish(ctx context.Context, history *entity.TaskExecutionHistory) error {
+	if history.Status == "" {
+		history.Status = entity.StatusQueued
+	}
+	if history.StartedAt.IsZero() {
+		history.StartedAt = time.Now()
+	}
+	if history.Attempt == 0 {
+		history.Attempt = 1
+	}
+	if history.TriggerType == "" {
+		history.TriggerType = entity.TriggerTypeSchedule
+	}
+
+	ctx, span := tracing.StartPublish(ctx, common.RedisStreamSchedulerTaskExecution,
+		attribute.Int("job.id", int(history.JobID)),
+		attribute.String("job.trigger_type", string(history.TriggerType)),
+	)
+	defer span.End()
+
+	if err := p.historyRepo.Create(ctx, history); err != nil {
+		p.logger.ErrorContext(ctx, "Failed to create task history", logger.ErrorField(err), logger.Field("job_id", history.JobID))
+		tasksPublished.WithLabelValues(string(history.TriggerType), publishResultFailure).Inc()
+		tracing.RecordError(span, err)
+		return err
+	}
+	span.SetAttributes(attribute.Int("execution.id", int(history.ID)))
+	// The queued event is enqueued before the task, so it cannot be sent after the executor's started event.
+	p.notifyWebhooks(ctx, history)
+
+	history.TraceContext = tracing.Inject(ctx)
+	taskPayload, err := json.Marshal(history) // Pass history object to executor
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to marshal task payload", logger.ErrorField(err), logger.Field("history_id", history.ID))
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	if err := p.redisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: common.RedisStreamSchedulerTaskExecution,
+		Values: map[string]interface{}{"payload": taskPayload},
+		MaxLen: p.cfg.Redis.StreamMaxLen, // Limit the stream size
+	}).Err(); err != nil {
+		p.logger.ErrorContext(ctx, "Failed to enqueue task", logger.ErrorField(err), logger.Field("history_id", history.ID))
+		tasksPublished.WithLabelValues(string(history.TriggerType), publishResultFailure).Inc()
+		tracing.RecordError(span, err)
+		history.Status = entity.StatusFailed
+		history.CompletedAt.Time = time.Now()
+		history.CompletedAt.Valid = true
+		history.ErrorMessage = sql.NullString{String: err.Error(), Valid: true}
+		errInner := p.historyRepo.Update(ctx, history)
+		if errInner != nil {
+			p.logger.ErrorContext(ctx, "Failed to update task history", logger.ErrorField(errInner), logger.Field("history_id", history.ID))
+		} else {
+			p.notifyWebhooks(ctx, history)
+		}
+		return err
+	}
+
+	tasksPublished.WithLabelValues(string(history.TriggerType), publishResultSuccess).Inc()
+	p.logger.InfoContext(ctx, "Task published successfully", logger.Field("history_id", history.ID), logger.Field("trigger_type", history.TriggerType))
+	return nil
+}
+
+// Cancel asks every executor instance to cancel the given execution. The executor running it
+// records the final status, so the history is not changed here.
+func (p *taskPublisher) Cancel(ctx context.Context, historyID uint) error {
+	if err := p.redisClient.Publish(ctx, common.RedisChannelTaskExecutionCancel, strconv.FormatUint(uint64(historyID), 10)).Err(); err != nil {
+		p.logger.Error("Failed to publish execution cancellation", logger.ErrorField(err), logger.Field("history_id", historyID))
+		return err
+	}
+	p.logger.Info("Execution cancellation requested", logger.Field("history_id", historyID))
+	return nil
+}
+
+// notifyWebhooks enqueues the current status of an execution as an event for the webhook
+// subscriptions that ask for it. A failure is logged and does not affect publishing.
+func (p *taskPublisher) notifyWebhooks(ctx context.Context, history *entity.TaskExecutionHistory) {
+	if err := p.webhookRepo.EnqueueExecutionEvent(ctx, entity.NewExecutionEvent(history, time.Now())); err != nil {
+		p.logger.ErrorContext(ctx, "Failed to enqueue webhook event", logger.ErrorField(err), logger.Field("history_id", history.ID), logger.StringField("status", string(history.Status)))
+	}
+}
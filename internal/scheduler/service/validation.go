@@ -0,0 +1,171 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+
+	"github.com/robfig/cron/v3"
+)
+
+// scheduleCronParser parses schedule cron expressions, both when they are saved and when
+// the scheduler evaluates them.
+var scheduleCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// parseCronExpression validates a schedule cron expression from an API request.
+func parseCronExpression(expression string) (string, error) {
+	if _, err := scheduleCronParser.Parse(expression); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCronExpression, err)
+	}
+	return expression, nil
+}
+
+// parseMisfirePolicy validates a misfire policy from an API request, defaulting to run_once.
+func parseMisfirePolicy(policy string) (entity.MisfirePolicy, error) {
+	if policy == "" {
+		return entity.MisfirePolicyRunOnce, nil
+	}
+	if !entity.MisfirePolicy(policy).IsValid() {
+		return "", ErrInvalidMisfirePolicy
+	}
+	return entity.MisfirePolicy(policy), nil
+}
+
+// parseTimezone validates an IANA time zone name from an API request, defaulting to
+// entity.DefaultScheduleTimezone.
+func parseTimezone(timezone string) (string, error) {
+	if timezone == "" {
+		return entity.DefaultScheduleTimezone, nil
+	}
+	// time.LoadLocation accepts "Local" (the host's zone) and, on case-insensitive file
+	// systems, spellings such as "utc"; neither is a stable IANA name.
+	if strings.EqualFold(timezone, "Local") || (strings.EqualFold(timezone, "UTC") && timezone != "UTC") {
+		return "", ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", ErrInvalidTimezone
+	}
+	return timezone, nil
+}
+
+// newPauseState builds the pause state of a pause request. Without an until time the
+// pause lasts until it is resumed.
+func newPauseState(req *dto.PauseRequest, now time.Time) (entity.PauseState, error) {
+	pause := entity.PauseState{Paused: true}
+	if req != nil && req.Until != nil {
+		if !req.Until.After(now) {
+			return entity.PauseState{}, ErrInvalidPauseUntil
+		}
+		pause.PausedUntil = sql.NullTime{Time: *req.Until, Valid: true}
+	}
+	return pause, nil
+}
+
+// pauseResponse returns the pause fields of a response: whether the pause is in effect at
+// now and, if so, when it ends.
+func pauseResponse(pause entity.PauseState, now time.Time) (bool, sql.NullTime) {
+	if !pause.IsPausedAt(now) {
+		return false, sql.NullTime{}
+	}
+	return true, pause.PausedUntil
+}
+
+// parseConcurrencyPolicy validates a job concurrency policy from an API request, defaulting to allow.
+func parseConcurrencyPolicy(policy string) (entity.ConcurrencyPolicy, error) {
+	if policy == "" {
+		return entity.ConcurrencyPolicyAllow, nil
+	}
+	if !entity.ConcurrencyPolicy(policy).IsValid() {
+		return "", ErrInvalidConcurrencyPolicy
+	}
+	return entity.ConcurrencyPolicy(policy), nil
+}
+
+// jobPayload returns the payload of a job request, treating an absent or null payload as
+// an empty object.
+func jobPayload(payload json.RawMessage) json.RawMessage {
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return json.RawMessage("{}")
+	}
+	return payload
+}
+
+// validateJobRequest checks a job create or update request and returns a *ValidationError
+// listing every invalid field: the name, the type, the payload against the schema of the
+// type, and each schedule.
+func validateJobRequest(req *dto.CreateJobRequest) error {
+	var fields []dto.FieldError
+	invalid := func(field, format string, args ...interface{}) {
+		fields = append(fields, dto.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		invalid("name", "is required")
+	}
+	if req.Timeout < 0 {
+		invalid("timeout", "must not be negative")
+	}
+	if req.RetryPolicy.MaxRetries < 0 {
+		invalid("retry_policy.max_retries", "must not be negative")
+	}
+	if _, err := parseConcurrencyPolicy(req.ConcurrencyPolicy); err != nil {
+		invalid("concurrency_policy", "must be one of allow, forbid, replace, queue")
+	}
+
+	if req.Type == "" {
+		invalid("type", "is required")
+	} else if spec, ok := entity.LookupJobType(entity.JobType(req.Type)); !ok {
+		types := make([]string, 0, len(entity.JobTypeSpecs))
+		for _, spec := range entity.JobTypeSpecs {
+			types = append(types, string(spec.Type))
+		}
+		invalid("type", "must be one of %s", strings.Join(types, ", "))
+	} else {
+		fields = append(fields, validateJobPayload(spec, req.Payload)...)
+	}
+
+	for i, schedule := range req.Schedules {
+		prefix := fmt.Sprintf("schedules[%d].", i)
+		if _, err := scheduleCronParser.Parse(schedule.CronExpression); err != nil {
+			invalid(prefix+"cron_expression", "%v", err)
+		}
+		if _, err := parseTimezone(schedule.Timezone); err != nil {
+			invalid(prefix+"timezone", "must be an IANA time zone name such as Asia/Jakarta")
+		}
+		if _, err := parseMisfirePolicy(schedule.MisfirePolicy); err != nil {
+			invalid(prefix+"misfire_policy", "must be one of run_once, skip, run_all")
+		}
+		if schedule.MisfireGraceSeconds < 0 {
+			invalid(prefix+"misfire_grace_seconds", "must not be negative")
+		}
+		if schedule.MisfireMaxRuns < 0 {
+			invalid(prefix+"misfire_max_runs", "must not be negative")
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// validateJobPayload checks a job payload against the schema of its job type and returns an
+// error for every invalid payload field.
+func validateJobPayload(spec entity.JobTypeSpec, payload json.RawMessage) []dto.FieldError {
+	var fields []dto.FieldError
+	for _, fieldErr := range entity.SchemaFor(spec.Payload).Validate(jobPayload(payload)) {
+		field := "payload"
+		if fieldErr.Field != "" {
+			field += "." + fieldErr.Field
+		}
+		fields = append(fields, dto.FieldError{Field: field, Message: fieldErr.Message})
+	}
+	return fields
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang-stock-scryper/internal/scheduler/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimezone(t *testing.T) {
+	tests := []struct {
+		timezone string
+		want     string
+		wantErr  bool
+	}{
+		{timezone: "", want: "Asia/Jakarta"},
+		{timezone: "Asia/Jakarta", want: "Asia/Jakarta"},
+		{timezone: "UTC", want: "UTC"},
+		{timezone: "Local", wantErr: true},
+		{timezone: "local", wantErr: true},
+		{timezone: "utc", wantErr: true},
+		{timezone: "Mars/Olympus", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			got, err := parseTimezone(tt.timezone)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTimezone)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseCronExpression(t *testing.T) {
+	_, err := parseCronExpression("*/5 9-15 * * 1-5")
+	assert.NoError(t, err)
+
+	_, err = parseCronExpression("@every 30s")
+	assert.NoError(t, err)
+
+	_, err = parseCronExpression("5 * * * * *")
+	assert.ErrorIs(t, err, ErrInvalidCronExpression)
+}
+
+func TestValidateJobRequest(t *testing.T) {
+	valid := dto.CreateJobRequest{
+		Name:      "news",
+		Type:      "stock_news_scraper",
+		Payload:   json.RawMessage(`{"max_news": 5}`),
+		Schedules: []dto.ScheduleDTO{{CronExpression: "0 * * * *"}},
+	}
+	assert.NoError(t, validateJobRequest(&valid))
+
+	withoutPayload := valid
+	withoutPayload.Type = "stock_position_monitor"
+	withoutPayload.Payload = nil
+	assert.NoError(t, validateJobRequest(&withoutPayload))
+
+	invalid := valid
+	invalid.Name = " "
+	invalid.Payload = json.RawMessage(`{"max_new": 5}`)
+	invalid.ConcurrencyPolicy = "parallel"
+	invalid.Schedules = []dto.ScheduleDTO{{CronExpression: "every hour", Timezone: "Local", MisfirePolicy: "later"}}
+	err := validateJobRequest(&invalid)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	var fields []string
+	for _, field := range validationErr.Fields {
+		fields = append(fields, field.Field)
+	}
+	assert.Equal(t, []string{
+		"name",
+		"concurrency_policy",
+		"payload.max_new",
+		"schedules[0].cron_expression",
+		"schedules[0].timezone",
+		"schedules[0].misfire_policy",
+	}, fields)
+
+	unknownType := valid
+	unknownType.Type = "stock_news_scrapper"
+	require.ErrorAs(t, validateJobRequest(&unknownType), &validationErr)
+	assert.Equal(t, "type", validationErr.Fields[0].Field)
+}
+
+func TestNewPauseState(t *testing.T) {
+	now := time.Date(2025, 12, 24, 8, 0, 0, 0, time.UTC)
+	until := now.Add(48 * time.Hour)
+
+	pause, err := newPauseState(&dto.PauseRequest{}, now)
+	require.NoError(t, err)
+	assert.True(t, pause.IsPausedAt(now.AddDate(1, 0, 0)), "pause without until lasts until resumed")
+
+	pause, err = newPauseState(&dto.PauseRequest{Until: &until}, now)
+	require.NoError(t, err)
+	assert.True(t, pause.IsPausedAt(now))
+	assert.False(t, pause.IsPausedAt(until), "pause ends at until")
+
+	paused, pausedUntil := pauseResponse(pause, until.Add(time.Minute))
+	assert.False(t, paused)
+	assert.False(t, pausedUntil.Valid)
+
+	past := now.Add(-time.Minute)
+	_, err = newPauseState(&dto.PauseRequest{Until: &past}, now)
+	assert.ErrorIs(t, err, ErrInvalidPauseUntil)
+}
+
+func TestValidateWebhookRequest(t *testing.T) {
+	valid := dto.UpdateWebhookRequest{
+		Name:     "alerts",
+		URL:      "https://hooks.example.com/executions",
+		Statuses: []string{"failed", "timeout"},
+	}
+	assert.Empty(t, validateWebhookRequest(&valid))
+
+	invalid := valid
+	invalid.Name = ""
+	invalid.URL = "hooks.example.com/executions"
+	invalid.Statuses = []string{"failed", "done"}
+	invalid.RetryPolicy = &dto.RetryPolicyDTO{MaxRetries: -1, InitialInterval: "soon"}
+	var fields []string
+	for _, field := range validateWebhookRequest(&invalid) {
+		fields = append(fields, field.Field)
+	}
+	assert.Equal(t, []string{
+		"name",
+		"url",
+		"statuses[1]",
+		"retry_policy.max_retries",
+		"retry_policy.initial_interval",
+	}, fields)
+}
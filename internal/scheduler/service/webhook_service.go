@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"golang-stock-scryper/internal/entity"
+	"golang-stock-scryper/internal/scheduler/dto"
+	"golang-stock-scryper/internal/scheduler/repository"
+	"golang-stock-scryper/pkg/logger"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWebhookDeliveryPageSize = 50
+	maxWebhookDeliveryPageSize     = 500
+	// maxWebhookNameLength matches the size of the name column of webhook subscriptions.
+	maxWebhookNameLength = 100
+	// maxWebhookSecretLength matches the size of the secret column of webhook subscriptions.
+	maxWebhookSecretLength = 255
+)
+
+// ErrInvalidWebhookDeliveryFilter is returned when the delivery log query parameters are invalid.
+var ErrInvalidWebhookDeliveryFilter = fmt.Errorf("%w: invalid webhook delivery filter", ErrInvalidInput)
+
+// WebhookService defines the interface for managing webhook subscriptions.
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.CreateWebhookResponse, error)
+	GetWebhookByID(ctx context.Context, id uint) (*dto.WebhookResponse, error)
+	GetAllWebhooks(ctx context.Context) ([]*dto.WebhookResponse, error)
+	UpdateWebhook(ctx context.Context, id uint, req *dto.UpdateWebhookRequest) (*dto.WebhookResponse, error)
+	DeleteWebhook(ctx context.Context, id uint) error
+	ListWebhookDeliveries(ctx context.Context, id uint, req *dto.ListWebhookDeliveriesRequest) ([]*dto.WebhookDeliveryResponse, error)
+}
+
+// NewWebhookService creates a new webhook service.
+func NewWebhookService(webhookRepo repository.WebhookRepository, jobRepo repository.JobRepository, logger *logger.Logger) WebhookService {
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		jobRepo:     jobRepo,
+		logger:      logger,
+	}
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	jobRepo     repository.JobRepository
+	logger      *logger.Logger
+}
+
+// CreateWebhook creates a webhook subscription, generating its secret unless one is given.
+func (s *webhookService) CreateWebhook(ctx context.Context, req *dto.CreateWebhookRequest) (*dto.CreateWebhookResponse, error) {
+	subscription := &entity.WebhookSubscription{IsActive: true}
+	if err := s.applyWebhookRequest(ctx, subscription, (*dto.UpdateWebhookRequest)(req)); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		subscription.Secret = secret
+	}
+
+	if err := s.webhookRepo.Create(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Webhook created", logger.Field("webhook_id", subscription.ID))
+	return &dto.CreateWebhookResponse{WebhookResponse: *mapToWebhookResponse(subscription), Secret: subscription.Secret}, nil
+}
+
+// GetWebhookByID retrieves a webhook subscription by its ID.
+func (s *webhookService) GetWebhookByID(ctx context.Context, id uint) (*dto.WebhookResponse, error) {
+	subscription, err := s.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return mapToWebhookResponse(subscription), nil
+}
+
+// GetAllWebhooks retrieves every webhook subscription.
+func (s *webhookService) GetAllWebhooks(ctx context.Context) ([]*dto.WebhookResponse, error) {
+	subscriptions, err := s.webhookRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*dto.WebhookResponse, 0, len(subscriptions))
+	for i := range subscriptions {
+		responses = append(responses, mapToWebhookResponse(&subscriptions[i]))
+	}
+	return responses, nil
+}
+
+// UpdateWebhook replaces a webhook subscription. Its secret is kept unless a new one is given.
+// Pending deliveries are sent with the new settings.
+func (s *webhookService) UpdateWebhook(ctx context.Context, id uint, req *dto.UpdateWebhookRequest) (*dto.WebhookResponse, error) {
+	subscription, err := s.webhookRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyWebhookRequest(ctx, subscription, req); err != nil {
+		return nil, err
+	}
+	if err := s.webhookRepo.Update(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Webhook updated", logger.Field("webhook_id", id))
+	return mapToWebhookResponse(subscription), nil
+}
+
+// DeleteWebhook deletes a webhook subscription together with its delivery log.
+func (s *webhookService) DeleteWebhook(ctx context.Context, id uint) error {
+	if err := s.webhookRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.logger.InfoContext(ctx, "Webhook deleted", logger.Field("webhook_id", id))
+	return nil
+}
+
+// ListWebhookDeliveries retrieves the delivery log of a webhook subscription, newest first.
+func (s *webhookService) ListWebhookDeliveries(ctx context.Context, id uint, req *dto.ListWebhookDeliveriesRequest) ([]*dto.WebhookDeliveryResponse, error) {
+	filter := repository.WebhookDeliveryFilter{Status: entity.WebhookDeliveryStatus(req.Status), Limit: defaultWebhookDeliveryPageSize}
+	switch filter.Status {
+	case "", entity.WebhookDeliveryPending, entity.WebhookDeliverySucceeded, entity.WebhookDeliveryFailed:
+	default:
+		return nil, fmt.Errorf("%w: status must be one of pending, succeeded, failed", ErrInvalidWebhookDeliveryFilter)
+	}
+	if req.Limit < 0 || req.Limit > maxWebhookDeliveryPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidWebhookDeliveryFilter, maxWebhookDeliveryPageSize)
+	}
+	if req.Limit > 0 {
+		filter.Limit = req.Limit
+	}
+
+	if _, err := s.webhookRepo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.webhookRepo.FindDeliveries(ctx, id, filter)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*dto.WebhookDeliveryResponse, 0, len(deliveries))
+	for i := range deliveries {
+		responses = append(responses, mapToWebhookDeliveryResponse(&deliveries[i]))
+	}
+	return responses, nil
+}
+
+// applyWebhookRequest validates a webhook request and applies it to subscription. It returns a
+// *ValidationError listing every invalid field.
+func (s *webhookService) applyWebhookRequest(ctx context.Context, subscription *entity.WebhookSubscription, req *dto.UpdateWebhookRequest) error {
+	fields := validateWebhookRequest(req)
+	if req.JobID != nil {
+		if _, err := s.jobRepo.FindByID(ctx, *req.JobID); err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			fields = append(fields, dto.FieldError{Field: "job_id", Message: "is not an existing job"})
+		}
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+
+	statuses := req.Statuses
+	if statuses == nil {
+		statuses = []string{}
+	}
+	statusesJSON, err := json.Marshal(statuses)
+	if err != nil {
+		return err
+	}
+	subscription.Name = strings.TrimSpace(req.Name)
+	subscription.URL = req.URL
+	subscription.JobID = req.JobID
+	subscription.Statuses = datatypes.JSON(statusesJSON)
+	subscription.RetryPolicy = nil
+	if req.RetryPolicy != nil {
+		retryPolicyJSON, err := json.Marshal(req.RetryPolicy)
+		if err != nil {
+			return err
+		}
+		subscription.RetryPolicy = datatypes.JSON(retryPolicyJSON)
+	}
+	if req.Secret != "" {
+		subscription.Secret = req.Secret
+	}
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+// validateWebhookRequest checks the fields of a webhook request that do not need the database.
+func validateWebhookRequest(req *dto.UpdateWebhookRequest) []dto.FieldError {
+	var fields []dto.FieldError
+	invalid := func(field, format string, args ...interface{}) {
+		fields = append(fields, dto.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if name := strings.TrimSpace(req.Name); name == "" {
+		invalid("name", "is required")
+	} else if len(name) > maxWebhookNameLength {
+		invalid("name", "must be at most %d characters", maxWebhookNameLength)
+	}
+	if parsed, err := url.Parse(req.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		invalid("url", "must be an absolute http or https URL")
+	}
+	if len(req.Secret) > maxWebhookSecretLength {
+		invalid("secret", "must be at most %d characters", maxWebhookSecretLength)
+	}
+
+	allowed := make([]string, 0, len(entity.WebhookEventStatuses))
+	for _, status := range entity.WebhookEventStatuses {
+		allowed = append(allowed, string(status))
+	}
+	for i, status := range req.Statuses {
+		if !slices.Contains(allowed, status) {
+			invalid(fmt.Sprintf("statuses[%d]", i), "must be one of %s", strings.Join(allowed, ", "))
+		}
+	}
+
+	if req.RetryPolicy != nil {
+		if req.RetryPolicy.MaxRetries < 0 {
+			invalid("retry_policy.max_retries", "must not be negative")
+		}
+		if req.RetryPolicy.InitialInterval != "" {
+			if interval, err := time.ParseDuration(req.RetryPolicy.InitialInterval); err != nil || interval <= 0 {
+				invalid("retry_policy.initial_interval", "must be a positive duration such as 30s")
+			}
+		}
+	}
+	return fields
+}
+
+// newWebhookSecret generates a random webhook secret.
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+func mapToWebhookResponse(subscription *entity.WebhookSubscription) *dto.WebhookResponse {
+	statuses := []string{}
+	_ = json.Unmarshal(subscription.Statuses, &statuses)
+	policy := subscription.GetRetryPolicy()
+	return &dto.WebhookResponse{
+		ID:       subscription.ID,
+		Name:     subscription.Name,
+		URL:      subscription.URL,
+		JobID:    subscription.JobID,
+		Statuses: statuses,
+		RetryPolicy: dto.RetryPolicyDTO{
+			MaxRetries:      policy.MaxRetries,
+			BackoffStrategy: string(policy.BackoffStrategy),
+			InitialInterval: policy.InitialInterval,
+		},
+		IsActive:  subscription.IsActive,
+		CreatedAt: subscription.CreatedAt,
+		UpdatedAt: subscription.UpdatedAt,
+	}
+}
+
+func mapToWebhookDeliveryResponse(delivery *entity.WebhookDelivery) *dto.WebhookDeliveryResponse {
+	response := &dto.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		ExecutionID:    delivery.ExecutionID,
+		JobID:          delivery.JobID,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseStatus: int(delivery.ResponseStatus.Int32),
+		LastError:      delivery.LastError.String,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		Payload:        json.RawMessage(delivery.Payload),
+	}
+	if delivery.Status == entity.WebhookDeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+	return response
+}
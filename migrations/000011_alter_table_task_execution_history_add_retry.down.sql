@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_task_execution_history_retry_of_id;
+
+ALTER TABLE task_execution_history
+DROP COLUMN IF EXISTS retry_of_id;
+
+ALTER TABLE task_execution_history
+DROP COLUMN IF EXISTS attempt;
@@ -0,0 +1,7 @@
+ALTER TABLE task_execution_history
+ADD COLUMN IF NOT EXISTS attempt INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE task_execution_history
+ADD COLUMN IF NOT EXISTS retry_of_id INTEGER REFERENCES task_execution_history(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_task_execution_history_retry_of_id ON task_execution_history(retry_of_id);
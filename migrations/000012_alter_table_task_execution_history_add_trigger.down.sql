@@ -0,0 +1,12 @@
+ALTER TABLE task_execution_history
+DROP COLUMN IF EXISTS payload_override;
+
+ALTER TABLE task_execution_history
+DROP COLUMN IF EXISTS trigger_type;
+
+-- Executions that were not started by a schedule cannot be represented once schedule_id is required again.
+DELETE FROM task_execution_history
+WHERE schedule_id IS NULL;
+
+ALTER TABLE task_execution_history
+ALTER COLUMN schedule_id SET NOT NULL;
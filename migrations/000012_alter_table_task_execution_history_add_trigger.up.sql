@@ -0,0 +1,8 @@
+ALTER TABLE task_execution_history
+ALTER COLUMN schedule_id DROP NOT NULL;
+
+ALTER TABLE task_execution_history
+ADD COLUMN IF NOT EXISTS trigger_type VARCHAR(50) NOT NULL DEFAULT 'schedule';
+
+ALTER TABLE task_execution_history
+ADD COLUMN IF NOT EXISTS payload_override JSONB;
@@ -0,0 +1,11 @@
+ALTER TABLE task_execution_history
+DROP COLUMN IF EXISTS scheduled_at;
+
+ALTER TABLE task_schedules
+DROP COLUMN IF EXISTS misfire_max_runs;
+
+ALTER TABLE task_schedules
+DROP COLUMN IF EXISTS misfire_grace_seconds;
+
+ALTER TABLE task_schedules
+DROP COLUMN IF EXISTS misfire_policy;
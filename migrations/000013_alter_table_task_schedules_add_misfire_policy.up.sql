@@ -0,0 +1,11 @@
+ALTER TABLE task_schedules
+ADD COLUMN IF NOT EXISTS misfire_policy VARCHAR(20) NOT NULL DEFAULT 'run_once';
+
+ALTER TABLE task_schedules
+ADD COLUMN IF NOT EXISTS misfire_grace_seconds INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE task_schedules
+ADD COLUMN IF NOT EXISTS misfire_max_runs INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE task_execution_history
+ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,2 @@
+ALTER TABLE task_schedules
+DROP COLUMN IF EXISTS timezone;
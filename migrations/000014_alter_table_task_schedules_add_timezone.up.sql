@@ -0,0 +1,2 @@
+ALTER TABLE task_schedules
+ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta';
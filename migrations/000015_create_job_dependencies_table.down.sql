@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_task_execution_history_triggered_by_id;
+
+ALTER TABLE task_execution_history
+DROP COLUMN IF EXISTS triggered_by_id;
+
+DROP TABLE IF EXISTS job_dependencies;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS job_dependencies (
+    job_id INTEGER NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    depends_on_job_id INTEGER NOT NULL REFERENCES jobs(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (job_id, depends_on_job_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_dependencies_depends_on_job_id ON job_dependencies(depends_on_job_id);
+
+ALTER TABLE task_execution_history
+ADD COLUMN IF NOT EXISTS triggered_by_id INTEGER REFERENCES task_execution_history(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_task_execution_history_triggered_by_id ON task_execution_history(triggered_by_id);
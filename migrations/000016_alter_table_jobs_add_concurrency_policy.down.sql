@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_task_execution_history_job_id_status;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS concurrency_policy;
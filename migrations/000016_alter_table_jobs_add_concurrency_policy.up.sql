@@ -0,0 +1,4 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS concurrency_policy VARCHAR(20) NOT NULL DEFAULT 'allow';
+
+CREATE INDEX IF NOT EXISTS idx_task_execution_history_job_id_status ON task_execution_history(job_id, status);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_task_execution_history_started_at;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_task_execution_history_started_at ON task_execution_history(started_at, id);
@@ -0,0 +1,9 @@
+ALTER TABLE task_schedules
+DROP COLUMN IF EXISTS paused_until;
+ALTER TABLE task_schedules
+DROP COLUMN IF EXISTS paused;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS paused_until;
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS paused;
@@ -0,0 +1,9 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS paused BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS paused_until TIMESTAMPTZ;
+
+ALTER TABLE task_schedules
+ADD COLUMN IF NOT EXISTS paused BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE task_schedules
+ADD COLUMN IF NOT EXISTS paused_until TIMESTAMPTZ;
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_task_execution_history_active;
+
+-- Older versions only know running executions.
+UPDATE task_execution_history
+SET status = 'running'
+WHERE status = 'queued';
+
+UPDATE task_execution_history
+SET status = 'failed'
+WHERE status = 'lost';
+
+ALTER TABLE task_execution_history
+DROP COLUMN IF EXISTS heartbeat_at;
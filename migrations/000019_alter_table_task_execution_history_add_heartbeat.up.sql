@@ -0,0 +1,5 @@
+ALTER TABLE task_execution_history
+ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_task_execution_history_active ON task_execution_history(status, heartbeat_at)
+WHERE status IN ('queued', 'running');
@@ -0,0 +1,12 @@
+-- Versions are kept after their job is deleted, so job_id does not reference jobs.
+CREATE TABLE IF NOT EXISTS job_versions (
+    id SERIAL PRIMARY KEY,
+    job_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    action VARCHAR(30) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (job_id, version)
+);
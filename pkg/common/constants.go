@@ -5,6 +5,20 @@ const (
 	RedisStreamStockAnalyzer          = "stock.analyzer"
 	RedisStreamStockPositionMonitor   = "stock.position.monitor"
 
+	// RedisChannelTaskExecutionCancel is the pub/sub channel carrying IDs of executions to cancel.
+	RedisChannelTaskExecutionCancel = "schedule.task.execution.cancel"
+
 	RedisStreamGroup    = "executor-group"
-	RedisStreamConsumer = "executor-consumer"
+	RedisStreamConsumer = "executor-consumer" // prefix of the consumer name of every executor instance
+
+	// RedisKeyExecutorConsumer marks the consumer of a live executor instance, by consumer name.
+	RedisKeyExecutorConsumer = "executor_consumer:%s"
+	// RedisKeyRateLimit holds the usage of a window of a shared rate limiter, by limiter and window.
+	RedisKeyRateLimit = "rate_limit:%s:%s"
 )
+
+// RedisExecutorStreams lists the streams the executor consumer group reads.
+var RedisExecutorStreams = []string{RedisStreamSchedulerTaskExecution, RedisStreamStockAnalyzer, RedisStreamStockPositionMonitor}
+
+// RedisDeadLetterStreams lists the streams whose failed messages are moved to a dead-letter stream.
+var RedisDeadLetterStreams = []string{RedisStreamStockAnalyzer, RedisStreamStockPositionMonitor}
@@ -45,6 +45,14 @@ type Redis struct {
 	StreamMaxLen int64  `mapstructure:"stream_max_len"`
 }
 
+// Tracing holds OpenTelemetry tracing configuration.
+type Tracing struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Endpoint    string  `mapstructure:"endpoint"`     // OTLP/HTTP collector address, e.g. "localhost:4318"
+	Insecure    bool    `mapstructure:"insecure"`     // send spans over plain HTTP instead of HTTPS
+	SampleRatio float64 `mapstructure:"sample_ratio"` // share of new traces that are recorded, defaults to 1
+}
+
 // API holds API server configuration.
 type API struct {
 	Host string `mapstructure:"host"`
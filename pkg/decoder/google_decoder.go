@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/metrics"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -46,7 +47,8 @@ type GoogleDecoder struct {
 // NewGoogleDecoder creates a new GoogleDecoder instance
 func NewGoogleDecoder(logger *logger.Logger) *GoogleDecoder {
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Timeout:   30 * time.Second,
+		Transport: metrics.NewTransport(metrics.ProviderGoogleNews, nil),
 	}
 
 	return &GoogleDecoder{
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -54,18 +55,26 @@ func (l *Logger) With(fields ...zap.Field) *Logger {
 	return &Logger{l.Logger.With(fields...)}
 }
 
-// FromContext retrieves a logger from context if it exists, or returns the default logger
+// FromContext retrieves a logger from context if it exists, or returns the default logger.
+// When the context carries a span, the logger adds its trace_id and span_id to every entry.
 func (l *Logger) FromContext(ctx context.Context) *Logger {
 	if ctx == nil {
 		return l
 	}
 
-	loggerFromCtx, ok := ctx.Value(loggerContextKey).(*Logger)
-	if !ok || loggerFromCtx == nil {
-		return l
+	log := l
+	if loggerFromCtx, ok := ctx.Value(loggerContextKey).(*Logger); ok && loggerFromCtx != nil {
+		log = loggerFromCtx
+	}
+
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		log = log.With(
+			zap.String("trace_id", spanCtx.TraceID().String()),
+			zap.String("span_id", spanCtx.SpanID().String()),
+		)
 	}
 
-	return loggerFromCtx
+	return log
 }
 
 // Debug logs a debug message
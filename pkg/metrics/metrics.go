@@ -0,0 +1,15 @@
+// Package metrics holds the Prometheus metrics shared by both services and serves the
+// /metrics endpoint.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves every metric registered with the default Prometheus registry, including the
+// Go runtime and process metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
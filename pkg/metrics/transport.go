@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Providers of outbound calls, used as the provider label of the outbound metrics.
+const (
+	ProviderYahooFinance = "yahoo_finance"
+	ProviderTradingView  = "tradingview"
+	ProviderGemini       = "gemini"
+	ProviderTelegram     = "telegram"
+	ProviderGoogleNews   = "google_news"
+	ProviderNewsSite     = "news_site"
+)
+
+var (
+	outboundRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "outbound_request_duration_seconds",
+		Help: "Time until the response headers of outbound HTTP requests arrived, by provider.",
+	}, []string{"provider"})
+	outboundRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbound_request_errors_total",
+		Help: "Outbound HTTP requests that failed or got a 4xx or 5xx response, by provider.",
+	}, []string{"provider"})
+)
+
+// NewTransport wraps base, or http.DefaultTransport when base is nil, so every request records
+// its latency and whether it failed under the given provider.
+func NewTransport(provider string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{
+		base:     base,
+		duration: outboundRequestDuration.WithLabelValues(provider),
+		errors:   outboundRequestErrors.WithLabelValues(provider),
+	}
+}
+
+type transport struct {
+	base     http.RoundTripper
+	duration prometheus.Observer
+	errors   prometheus.Counter
+}
+
+// RoundTrip sends the request with the wrapped transport and records the outcome.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	t.duration.Observe(time.Since(start).Seconds())
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		t.errors.Inc()
+	}
+	return resp, err
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportRecordsOutcome(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport("test_provider", nil)}
+	for _, path := range []string{"/ok", "/fail"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	_, err := client.Get("http://127.0.0.1:0")
+	require.Error(t, err)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(outboundRequestErrors.WithLabelValues("test_provider")))
+	assert.Equal(t, 1, testutil.CollectAndCount(outboundRequestDuration))
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"golang-stock-scryper/pkg/tracing"
+
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
@@ -59,6 +61,9 @@ func NewDB(cfg Config) (*DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database using GORM: %w", err)
 	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register GORM tracing: %w", err)
+	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -0,0 +1,297 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"golang-stock-scryper/pkg/common"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+)
+
+// Unit is what a window counts.
+type Unit string
+
+const (
+	UnitRequests Unit = "requests"
+	UnitTokens   Unit = "tokens"
+)
+
+const (
+	// maxWaitJitter spreads the retries of instances waiting for the same window.
+	maxWaitJitter = 50 * time.Millisecond
+	// quotaMetricsTimeout bounds the Redis call made for one scrape of the quota metrics.
+	quotaMetricsTimeout = 5 * time.Second
+)
+
+// ErrCostExceedsLimit is returned when a single call costs more than a window allows, so it
+// could never be let through.
+var ErrCostExceedsLimit = errors.New("cost exceeds the limit of a rate limit window")
+
+var (
+	waitSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rate_limit_wait_seconds_total",
+		Help: "Time this instance spent waiting for a shared rate limiter, by limiter.",
+	}, []string{"limiter"})
+)
+
+// reserveScript checks every window of a limiter and, when all of them have room for their cost,
+// records the cost in each of them, all in one step. Rolling windows (KEYS[i]) are sorted sets of
+// "<id>:<cost>" members scored by the time they were recorded; fixed windows are counters whose
+// key names the window. ARGV holds the current time in milliseconds, a unique id and "reserve" or
+// "peek", followed by kind, limit, period (rolling) or reset time (fixed) in milliseconds and cost
+// per window. It returns whether the call was denied, how long to wait in milliseconds before
+// trying again, and the amount used per window.
+var reserveScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local reserve = ARGV[3] == 'reserve'
+local denied = 0
+local wait = 0
+local used = {}
+local function cost_of(member)
+	return tonumber(string.match(member, ':(%d+)$'))
+end
+for i = 1, #KEYS do
+	local base = 3 + (i - 1) * 4
+	local kind = ARGV[base + 1]
+	local limit = tonumber(ARGV[base + 2])
+	local period = tonumber(ARGV[base + 3])
+	local cost = tonumber(ARGV[base + 4])
+	if kind == 'rolling' then
+		redis.call('ZREMRANGEBYSCORE', KEYS[i], '-inf', now - period)
+		local entries = redis.call('ZRANGE', KEYS[i], 0, -1, 'WITHSCORES')
+		local sum = 0
+		for j = 1, #entries, 2 do
+			sum = sum + cost_of(entries[j])
+		end
+		used[i] = sum
+		if reserve and sum + cost > limit then
+			denied = 1
+			local excess = sum + cost - limit
+			for j = 1, #entries, 2 do
+				excess = excess - cost_of(entries[j])
+				if excess <= 0 then
+					wait = math.max(wait, tonumber(entries[j + 1]) + period - now)
+					break
+				end
+			end
+		end
+	else
+		used[i] = tonumber(redis.call('GET', KEYS[i]) or '0')
+		if reserve and used[i] + cost > limit then
+			denied = 1
+			wait = math.max(wait, period - now)
+		end
+	end
+end
+if reserve and denied == 0 then
+	for i = 1, #KEYS do
+		local base = 3 + (i - 1) * 4
+		local period = tonumber(ARGV[base + 3])
+		local cost = tonumber(ARGV[base + 4])
+		if ARGV[base + 1] == 'rolling' then
+			redis.call('ZADD', KEYS[i], now, ARGV[2] .. ':' .. cost)
+			redis.call('PEXPIRE', KEYS[i], period)
+		else
+			redis.call('INCRBY', KEYS[i], cost)
+			redis.call('PEXPIREAT', KEYS[i], period)
+		end
+		used[i] = used[i] + cost
+	end
+end
+return {denied, wait, unpack(used)}
+`)
+
+// Window is a limit on the requests or tokens used within a period.
+type Window struct {
+	Unit   Unit
+	Limit  int64
+	Period time.Duration // length of a rolling window
+	// Location, when set, makes the window a calendar day that resets at midnight in Location
+	// instead of a rolling window.
+	Location *time.Location
+}
+
+// PerMinute returns a rolling window of one minute.
+func PerMinute(unit Unit, limit int64) Window {
+	return Window{Unit: unit, Limit: limit, Period: time.Minute}
+}
+
+// PerDay returns a window of a calendar day that resets at midnight in loc, like the daily
+// quotas of most APIs.
+func PerDay(unit Unit, limit int64, loc *time.Location) Window {
+	return Window{Unit: unit, Limit: limit, Period: 24 * time.Hour, Location: loc}
+}
+
+// Name returns the name of the window, e.g. requests_per_minute.
+func (w Window) Name() string {
+	switch {
+	case w.Location != nil:
+		return string(w.Unit) + "_per_day"
+	case w.Period == time.Minute:
+		return string(w.Unit) + "_per_minute"
+	}
+	return string(w.Unit) + "_per_" + w.Period.String()
+}
+
+// cost returns what a call using tokens tokens costs in the window.
+func (w Window) cost(tokens int64) int64 {
+	if w.Unit == UnitTokens {
+		return tokens
+	}
+	return 1
+}
+
+// Quota is the usage of a window.
+type Quota struct {
+	Window    Window
+	Used      int64
+	Remaining int64
+}
+
+// SharedLimiter limits the calls of every instance sharing a Redis to the same windows, e.g. the
+// per-minute and per-day quotas of an external API. A call is only let through when every window
+// has room for it. Instances use their own clock, which should be kept in sync.
+type SharedLimiter struct {
+	name        string
+	redisClient *redis.Client
+	windows     []Window
+	now         func() time.Time
+	// remainingDesc and limitDesc carry the name of the limiter as a constant label, so every
+	// limiter can be registered as a collector of its own.
+	remainingDesc *prometheus.Desc
+	limitDesc     *prometheus.Desc
+}
+
+// NewSharedLimiter creates a limiter named name with the given windows. Windows without a limit
+// are left out.
+func NewSharedLimiter(redisClient *redis.Client, name string, windows ...Window) *SharedLimiter {
+	limited := make([]Window, 0, len(windows))
+	for _, window := range windows {
+		if window.Limit > 0 {
+			limited = append(limited, window)
+		}
+	}
+	labels := prometheus.Labels{"limiter": name}
+	return &SharedLimiter{
+		name:        name,
+		redisClient: redisClient,
+		windows:     limited,
+		now:         time.Now,
+		remainingDesc: prometheus.NewDesc("rate_limit_remaining",
+			"Requests or tokens left in a window of a shared rate limiter.", []string{"window"}, labels),
+		limitDesc: prometheus.NewDesc("rate_limit_limit",
+			"Requests or tokens allowed in a window of a shared rate limiter.", []string{"window"}, labels),
+	}
+}
+
+// Wait blocks until every window has room for a call using tokens tokens and records the call.
+// Request windows count the call once, token windows count its tokens. It returns the quotas
+// left after the call.
+func (l *SharedLimiter) Wait(ctx context.Context, tokens int64) ([]Quota, error) {
+	for _, window := range l.windows {
+		if window.cost(tokens) > window.Limit {
+			return nil, fmt.Errorf("%w: %s %s needs %d, limit is %d", ErrCostExceedsLimit, l.name, window.Name(), window.cost(tokens), window.Limit)
+		}
+	}
+
+	started := l.now()
+	defer func() { waitSeconds.WithLabelValues(l.name).Add(l.now().Sub(started).Seconds()) }()
+	for {
+		quotas, wait, err := l.run(ctx, tokens, true)
+		if err != nil {
+			return nil, err
+		}
+		if wait == 0 {
+			return quotas, nil
+		}
+
+		timer := time.NewTimer(wait + rand.N(maxWaitJitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Quotas returns the current usage of every window without recording a call.
+func (l *SharedLimiter) Quotas(ctx context.Context) ([]Quota, error) {
+	quotas, _, err := l.run(ctx, 0, false)
+	return quotas, err
+}
+
+// run runs reserveScript. It returns how long to wait before trying again when the call was
+// denied, and zero otherwise.
+func (l *SharedLimiter) run(ctx context.Context, tokens int64, reserve bool) ([]Quota, time.Duration, error) {
+	if len(l.windows) == 0 {
+		return nil, 0, nil
+	}
+
+	now := l.now()
+	mode := "peek"
+	if reserve {
+		mode = "reserve"
+	}
+	keys := make([]string, 0, len(l.windows))
+	args := []interface{}{now.UnixMilli(), strconv.FormatUint(rand.Uint64(), 36), mode}
+	for _, window := range l.windows {
+		key := fmt.Sprintf(common.RedisKeyRateLimit, l.name, window.Name())
+		if window.Location != nil {
+			day := now.In(window.Location)
+			resetAt := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, window.Location)
+			keys = append(keys, key+":"+day.Format(time.DateOnly))
+			args = append(args, "fixed", window.Limit, resetAt.UnixMilli(), window.cost(tokens))
+			continue
+		}
+		keys = append(keys, key)
+		args = append(args, "rolling", window.Limit, window.Period.Milliseconds(), window.cost(tokens))
+	}
+
+	result, err := reserveScript.Run(ctx, l.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, 0, fmt.Errorf("rate limiter %s: %w", l.name, err)
+	}
+	if len(result) != 2+len(l.windows) {
+		return nil, 0, fmt.Errorf("rate limiter %s: unexpected reply %v", l.name, result)
+	}
+
+	quotas := make([]Quota, 0, len(l.windows))
+	for i, window := range l.windows {
+		used := result[2+i]
+		quotas = append(quotas, Quota{Window: window, Used: used, Remaining: max(window.Limit-used, 0)})
+	}
+	if result[0] == 1 {
+		return quotas, max(time.Duration(result[1])*time.Millisecond, time.Millisecond), nil
+	}
+	return quotas, 0, nil
+}
+
+// Describe implements prometheus.Collector.
+func (l *SharedLimiter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- l.remainingDesc
+	ch <- l.limitDesc
+}
+
+// Collect implements prometheus.Collector. The quotas are read from Redis on every scrape and
+// left out when Redis cannot be read.
+func (l *SharedLimiter) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), quotaMetricsTimeout)
+	defer cancel()
+
+	quotas, err := l.Quotas(ctx)
+	if err != nil {
+		return
+	}
+	for _, quota := range quotas {
+		ch <- prometheus.MustNewConstMetric(l.remainingDesc, prometheus.GaugeValue, float64(quota.Remaining), quota.Window.Name())
+		ch <- prometheus.MustNewConstMetric(l.limitDesc, prometheus.GaugeValue, float64(quota.Window.Limit), quota.Window.Name())
+	}
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowName(t *testing.T) {
+	assert.Equal(t, "requests_per_minute", PerMinute(UnitRequests, 15).Name())
+	assert.Equal(t, "tokens_per_minute", PerMinute(UnitTokens, 1_000_000).Name())
+	assert.Equal(t, "requests_per_day", PerDay(UnitRequests, 1500, time.UTC).Name())
+	assert.Equal(t, "requests_per_1h0m0s", Window{Unit: UnitRequests, Limit: 100, Period: time.Hour}.Name())
+}
+
+func TestSharedLimiterWithoutLimitsDoesNotWait(t *testing.T) {
+	limiter := NewSharedLimiter(nil, "gemini", PerMinute(UnitRequests, 0), PerDay(UnitRequests, 0, time.UTC))
+
+	quotas, err := limiter.Wait(context.Background(), 5000)
+	require.NoError(t, err)
+	assert.Empty(t, quotas)
+}
+
+func TestSharedLimiterRejectsCostAboveLimit(t *testing.T) {
+	limiter := NewSharedLimiter(nil, "gemini", PerMinute(UnitRequests, 15), PerMinute(UnitTokens, 1000))
+
+	_, err := limiter.Wait(context.Background(), 1001)
+	assert.ErrorIs(t, err, ErrCostExceedsLimit)
+}
+
+func TestSharedLimiterFailsWithoutRedis(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	limiter := NewSharedLimiter(client, "yahoo_finance", PerMinute(UnitRequests, 15))
+
+	_, err := limiter.Wait(context.Background(), 0)
+	assert.Error(t, err)
+}
+
+func TestSharedLimitersRegisterWithOneRegistry(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0", MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	registry := prometheus.NewRegistry()
+
+	require.NoError(t, registry.Register(NewSharedLimiter(client, "yahoo_finance", PerMinute(UnitRequests, 15))))
+	require.NoError(t, registry.Register(NewSharedLimiter(client, "gemini", PerMinute(UnitRequests, 15), PerDay(UnitRequests, 1500, time.UTC))))
+
+	// Quotas that cannot be read from Redis are left out of a scrape.
+	_, err := registry.Gather()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,89 @@
+package stream
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// deadLetterSuffix names the dead-letter stream of a stream.
+const deadLetterSuffix = ".dlq"
+
+// Fields of a dead-letter stream entry.
+const (
+	FieldPayload       = "payload"
+	FieldSourceStream  = "source_stream"
+	FieldSourceID      = "source_id"
+	FieldError         = "error"
+	FieldAttempts      = "attempts"
+	FieldFirstFailedAt = "first_failed_at"
+	FieldLastFailedAt  = "last_failed_at"
+	FieldConsumer      = "consumer"
+)
+
+// DeadLetterStream returns the name of the dead-letter stream of stream.
+func DeadLetterStream(stream string) string {
+	return stream + deadLetterSuffix
+}
+
+// DeadLetter is a message that could not be processed, as kept in the dead-letter stream of its
+// source stream.
+type DeadLetter struct {
+	ID            string // ID of the entry in the dead-letter stream, empty until it is added
+	SourceStream  string
+	SourceID      string // ID of the message in the source stream
+	Payload       string // payload field of the message, unchanged
+	Error         string // error of the last attempt
+	Attempts      int64
+	FirstFailedAt time.Time
+	LastFailedAt  time.Time
+	Consumer      string // consumer that gave up on the message
+}
+
+// Values returns the fields of the dead-letter stream entry.
+func (d DeadLetter) Values() map[string]interface{} {
+	return map[string]interface{}{
+		FieldPayload:       d.Payload,
+		FieldSourceStream:  d.SourceStream,
+		FieldSourceID:      d.SourceID,
+		FieldError:         d.Error,
+		FieldAttempts:      d.Attempts,
+		FieldFirstFailedAt: d.FirstFailedAt.UTC().Format(time.RFC3339Nano),
+		FieldLastFailedAt:  d.LastFailedAt.UTC().Format(time.RFC3339Nano),
+		FieldConsumer:      d.Consumer,
+	}
+}
+
+// ParseDeadLetter reads a dead-letter stream entry.
+func ParseDeadLetter(message redis.XMessage) (DeadLetter, error) {
+	letter := DeadLetter{
+		ID:           message.ID,
+		Payload:      stringValue(message.Values, FieldPayload),
+		SourceStream: stringValue(message.Values, FieldSourceStream),
+		SourceID:     stringValue(message.Values, FieldSourceID),
+		Error:        stringValue(message.Values, FieldError),
+		Consumer:     stringValue(message.Values, FieldConsumer),
+	}
+	if letter.SourceStream == "" {
+		return DeadLetter{}, fmt.Errorf("dead letter %s has no %s", message.ID, FieldSourceStream)
+	}
+
+	var err error
+	if letter.Attempts, err = strconv.ParseInt(stringValue(message.Values, FieldAttempts), 10, 64); err != nil {
+		return DeadLetter{}, fmt.Errorf("dead letter %s: invalid %s: %w", message.ID, FieldAttempts, err)
+	}
+	if letter.FirstFailedAt, err = time.Parse(time.RFC3339Nano, stringValue(message.Values, FieldFirstFailedAt)); err != nil {
+		return DeadLetter{}, fmt.Errorf("dead letter %s: invalid %s: %w", message.ID, FieldFirstFailedAt, err)
+	}
+	if letter.LastFailedAt, err = time.Parse(time.RFC3339Nano, stringValue(message.Values, FieldLastFailedAt)); err != nil {
+		return DeadLetter{}, fmt.Errorf("dead letter %s: invalid %s: %w", message.ID, FieldLastFailedAt, err)
+	}
+	return letter, nil
+}
+
+func stringValue(values map[string]interface{}, field string) string {
+	value, _ := values[field].(string)
+	return value
+}
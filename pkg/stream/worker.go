@@ -0,0 +1,319 @@
+// Package stream processes Redis stream messages with a consumer group: it decodes their JSON
+// payload, retries failed messages with backoff and moves messages that keep failing to a
+// dead-letter stream.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"golang-stock-scryper/pkg/logger"
+	"golang-stock-scryper/pkg/tracing"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// payloadField is the message field holding the JSON payload.
+	payloadField = "payload"
+	// failuresSuffix names the hash holding the first failure time of failing messages.
+	failuresSuffix = ".failures"
+
+	defaultBatchSize     = 1
+	defaultBlock         = 2 * time.Second
+	defaultRetryDelay    = time.Minute
+	defaultRetryMaxDelay = time.Hour
+	// retryScanSize is the number of pending messages looked at per retry, since messages that
+	// are still backing off are skipped. Further retries go on after the last message looked at.
+	retryScanSize = 100
+)
+
+// Message is a decoded stream message.
+type Message[T any] struct {
+	ID      string
+	Payload T
+	Attempt int64 // 1 for the first delivery
+}
+
+// Handler processes a message. A message is acknowledged when its handler returns nil and
+// retried otherwise.
+type Handler[T any] func(ctx context.Context, message Message[T]) error
+
+// Traced is implemented by payloads that carry the trace context of their producer, so the
+// processing span continues the producer's trace.
+type Traced interface {
+	GetTraceContext() map[string]string
+}
+
+// Config holds the configuration of a Worker.
+type Config struct {
+	Stream        string
+	Group         string
+	Consumer      string
+	BatchSize     int64         // messages read or retried at once, defaults to 1
+	Block         time.Duration // how long a read waits for new messages, defaults to 2s
+	RetryDelay    time.Duration // idle time of a failed message before its first retry, doubled for every further retry, defaults to 1m
+	RetryMaxDelay time.Duration // upper bound of the retry delay, defaults to 1h
+	MaxRetries    int           // retries after the first attempt before a message is dead-lettered
+	// OnDeadLetter, when set, is called after a message was moved to the dead-letter stream.
+	OnDeadLetter func(ctx context.Context, letter DeadLetter)
+}
+
+// Worker reads the messages of one stream as one consumer of a consumer group and hands their
+// payload to a handler. Failed messages stay pending and are claimed again by Retry, by any
+// consumer of the group, once their retry delay has passed. A message that fails MaxRetries
+// retries, or whose payload cannot be decoded, is moved to the dead-letter stream of its stream
+// with its error, attempts and failure times.
+type Worker[T any] struct {
+	cfg         Config
+	redisClient *redis.Client
+	handler     Handler[T]
+	logger      *logger.Logger
+	now         func() time.Time
+	// retryCursor is the ID of the last pending message the previous retry looked at, empty to
+	// start at the oldest one.
+	retryCursor string
+}
+
+// NewWorker creates a new Worker, using defaults for empty configuration values.
+func NewWorker[T any](redisClient *redis.Client, cfg Config, handler Handler[T], log *logger.Logger) *Worker[T] {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = defaultBlock
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = defaultRetryDelay
+	}
+	if cfg.RetryMaxDelay < cfg.RetryDelay {
+		cfg.RetryMaxDelay = max(defaultRetryMaxDelay, cfg.RetryDelay)
+	}
+	return &Worker[T]{
+		cfg:         cfg,
+		redisClient: redisClient,
+		handler:     handler,
+		logger:      log,
+		now:         time.Now,
+	}
+}
+
+// Read reads a batch of new messages, waiting up to the block time for them, and handles them
+// one after the other.
+func (w *Worker[T]) Read(ctx context.Context) {
+	streams, err := w.redisClient.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    w.cfg.Group,
+		Consumer: w.cfg.Consumer,
+		Streams:  []string{w.cfg.Stream, ">"}, // ">" means only new messages
+		Count:    w.cfg.BatchSize,
+		Block:    w.cfg.Block,
+	}).Result()
+	if err != nil {
+		// Ignore context cancellation and timeout errors, as they are expected during shutdown or idle periods.
+		if err == context.Canceled || err == redis.Nil {
+			return
+		}
+		w.logger.Error("Failed to read from stream", logger.ErrorField(err), logger.StringField("stream", w.cfg.Stream))
+		return
+	}
+
+	for _, stream := range streams {
+		for _, message := range stream.Messages {
+			w.handle(ctx, message, 1)
+		}
+	}
+}
+
+// Retry claims a batch of failed messages whose retry delay has passed and handles them again.
+// Each call looks at the next part of the pending list, starting over at the oldest message once
+// the end was reached, so messages behind ones that are still backing off are retried as well.
+// Retry must not be called concurrently.
+func (w *Worker[T]) Retry(ctx context.Context) {
+	start := "-"
+	if w.retryCursor != "" {
+		start = "(" + w.retryCursor // exclusive, so the scan goes on after the cursor
+	}
+	pending, err := w.redisClient.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: w.cfg.Stream,
+		Group:  w.cfg.Group,
+		Idle:   w.cfg.RetryDelay,
+		Start:  start,
+		End:    "+",
+		Count:  retryScanSize,
+	}).Result()
+	if err != nil {
+		w.logger.Error("Failed to list pending messages", logger.ErrorField(err), logger.StringField("stream", w.cfg.Stream))
+		return
+	}
+
+	var retried int64
+	looked := 0
+	for _, entry := range pending {
+		if retried >= w.cfg.BatchSize || ctx.Err() != nil {
+			break
+		}
+		looked++
+		delay := w.retryDelay(entry.RetryCount)
+		if entry.Idle < delay {
+			continue
+		}
+
+		// Claiming with the delay as minimum idle time fails when another consumer claimed the
+		// message in the meantime.
+		messages, err := w.redisClient.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   w.cfg.Stream,
+			Group:    w.cfg.Group,
+			Consumer: w.cfg.Consumer,
+			MinIdle:  delay,
+			Messages: []string{entry.ID},
+		}).Result()
+		if err != nil {
+			w.logger.Error("Failed to claim pending message", logger.ErrorField(err), logger.StringField("stream", w.cfg.Stream), logger.StringField("message_id", entry.ID))
+			continue
+		}
+		for _, message := range messages {
+			retried++
+			w.logger.Info("Retrying stream message", logger.StringField("stream", w.cfg.Stream), logger.StringField("message_id", message.ID), logger.Field("attempt", entry.RetryCount+1))
+			w.handle(ctx, message, entry.RetryCount+1)
+		}
+	}
+	w.retryCursor = nextRetryCursor(w.retryCursor, pending, looked)
+}
+
+// nextRetryCursor returns where the next retry starts after a retry that listed pending and
+// looked at the first looked of them: after the last one looked at, or at the oldest message
+// once the whole pending list was looked at.
+func nextRetryCursor(cursor string, pending []redis.XPendingExt, looked int) string {
+	switch {
+	case looked < len(pending) && looked > 0:
+		return pending[looked-1].ID
+	case looked < len(pending):
+		return cursor
+	case len(pending) < retryScanSize:
+		return ""
+	}
+	return pending[len(pending)-1].ID
+}
+
+// retryDelay returns how long a message that was delivered deliveries times stays idle before it
+// is retried.
+func (w *Worker[T]) retryDelay(deliveries int64) time.Duration {
+	delay := w.cfg.RetryDelay
+	for i := int64(1); i < deliveries && delay < w.cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, w.cfg.RetryMaxDelay)
+}
+
+func (w *Worker[T]) handle(ctx context.Context, message redis.XMessage, attempt int64) {
+	raw, _ := message.Values[payloadField].(string)
+	var payload T
+	if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+		w.logger.Error("Failed to decode stream message", logger.ErrorField(err), logger.StringField("stream", w.cfg.Stream), logger.StringField("message_id", message.ID))
+		w.deadLetter(ctx, message.ID, raw, fmt.Errorf("decode payload: %w", err), attempt)
+		return
+	}
+
+	var carrier map[string]string
+	if traced, ok := any(payload).(Traced); ok {
+		carrier = traced.GetTraceContext()
+	}
+	ctx, span := tracing.StartProcess(ctx, w.cfg.Stream, carrier, attribute.Int64("messaging.redis.attempt", attempt))
+	defer span.End()
+
+	err := w.handler(ctx, Message[T]{ID: message.ID, Payload: payload, Attempt: attempt})
+	if err == nil {
+		w.ack(ctx, message.ID)
+		return
+	}
+	tracing.RecordError(span, err)
+
+	if attempt > int64(w.cfg.MaxRetries) {
+		w.logger.ErrorContext(ctx, "Stream message retries exhausted",
+			logger.ErrorField(err),
+			logger.StringField("stream", w.cfg.Stream),
+			logger.StringField("message_id", message.ID),
+			logger.Field("attempt", attempt),
+			logger.IntField("max_retries", w.cfg.MaxRetries),
+		)
+		w.deadLetter(ctx, message.ID, raw, err, attempt)
+		return
+	}
+
+	w.logger.WarnContext(ctx, "Stream message failed, it will be retried",
+		logger.ErrorField(err),
+		logger.StringField("stream", w.cfg.Stream),
+		logger.StringField("message_id", message.ID),
+		logger.Field("attempt", attempt),
+		logger.Field("retry_in", w.retryDelay(attempt).String()),
+	)
+	if err := w.redisClient.HSetNX(ctx, w.failuresKey(), message.ID, w.now().UTC().Format(time.RFC3339Nano)).Err(); err != nil {
+		w.logger.Error("Failed to record message failure", logger.ErrorField(err), logger.StringField("stream", w.cfg.Stream), logger.StringField("message_id", message.ID))
+	}
+}
+
+// ack acknowledges and deletes a processed message.
+func (w *Worker[T]) ack(ctx context.Context, messageID string) {
+	_, err := w.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAck(ctx, w.cfg.Stream, w.cfg.Group, messageID)
+		pipe.XDel(ctx, w.cfg.Stream, messageID)
+		pipe.HDel(ctx, w.failuresKey(), messageID)
+		return nil
+	})
+	if err != nil {
+		w.logger.Error("Failed to acknowledge stream message", logger.ErrorField(err), logger.StringField("stream", w.cfg.Stream), logger.StringField("message_id", messageID))
+	}
+}
+
+// deadLetter moves a message to the dead-letter stream. The message is added there and removed
+// from its stream atomically, and stays pending to be retried again when that fails.
+func (w *Worker[T]) deadLetter(ctx context.Context, messageID, payload string, cause error, attempt int64) {
+	now := w.now()
+	firstFailedAt := now
+	if value, err := w.redisClient.HGet(ctx, w.failuresKey(), messageID).Result(); err == nil {
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			firstFailedAt = t
+		}
+	}
+
+	letter := DeadLetter{
+		SourceStream:  w.cfg.Stream,
+		SourceID:      messageID,
+		Payload:       payload,
+		Error:         cause.Error(),
+		Attempts:      attempt,
+		FirstFailedAt: firstFailedAt,
+		LastFailedAt:  now,
+		Consumer:      w.cfg.Consumer,
+	}
+	var add *redis.StringCmd
+	_, err := w.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		add = pipe.XAdd(ctx, &redis.XAddArgs{Stream: DeadLetterStream(w.cfg.Stream), Values: letter.Values()})
+		pipe.XAck(ctx, w.cfg.Stream, w.cfg.Group, messageID)
+		pipe.XDel(ctx, w.cfg.Stream, messageID)
+		pipe.HDel(ctx, w.failuresKey(), messageID)
+		return nil
+	})
+	if err != nil {
+		w.logger.Error("Failed to move stream message to the dead-letter stream", logger.ErrorField(err), logger.StringField("stream", w.cfg.Stream), logger.StringField("message_id", messageID))
+		return
+	}
+	letter.ID = add.Val()
+	w.logger.Warn("Moved stream message to the dead-letter stream",
+		logger.StringField("stream", w.cfg.Stream),
+		logger.StringField("message_id", messageID),
+		logger.StringField("dead_letter_id", letter.ID),
+		logger.Field("attempts", attempt),
+	)
+
+	if w.cfg.OnDeadLetter != nil {
+		w.cfg.OnDeadLetter(ctx, letter)
+	}
+}
+
+func (w *Worker[T]) failuresKey() string {
+	return w.cfg.Stream + failuresSuffix
+}
@@ -0,0 +1,75 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryDelayDoublesUpToMaxDelay(t *testing.T) {
+	w := NewWorker[struct{}](nil, Config{RetryDelay: time.Minute, RetryMaxDelay: 5 * time.Minute}, nil, nil)
+
+	delays := make([]time.Duration, 0, 5)
+	for deliveries := int64(1); deliveries <= 5; deliveries++ {
+		delays = append(delays, w.retryDelay(deliveries))
+	}
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}, delays)
+}
+
+func TestNewWorkerDefaults(t *testing.T) {
+	w := NewWorker[struct{}](nil, Config{}, func(ctx context.Context, message Message[struct{}]) error { return nil }, nil)
+
+	assert.Equal(t, int64(defaultBatchSize), w.cfg.BatchSize)
+	assert.Equal(t, defaultBlock, w.cfg.Block)
+	assert.Equal(t, defaultRetryDelay, w.cfg.RetryDelay)
+	assert.Equal(t, defaultRetryMaxDelay, w.cfg.RetryMaxDelay)
+}
+
+func TestDeadLetterRoundTrip(t *testing.T) {
+	letter := DeadLetter{
+		SourceStream:  "stock.analyzer",
+		SourceID:      "1718000000000-0",
+		Payload:       `{"stock_code":"BBCA"}`,
+		Error:         "quota exceeded",
+		Attempts:      4,
+		FirstFailedAt: time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC),
+		LastFailedAt:  time.Date(2024, 6, 10, 9, 15, 0, 0, time.UTC),
+		Consumer:      "executor-consumer-pod-a-1",
+	}
+
+	// Redis returns every field as a string.
+	values := make(map[string]interface{})
+	for field, value := range letter.Values() {
+		values[field] = fmt.Sprint(value)
+	}
+	parsed, err := ParseDeadLetter(redis.XMessage{ID: "1718000900000-0", Values: values})
+	require.NoError(t, err)
+
+	letter.ID = "1718000900000-0"
+	assert.Equal(t, letter, parsed)
+	assert.Equal(t, "stock.analyzer.dlq", DeadLetterStream(letter.SourceStream))
+}
+
+func TestParseDeadLetterRejectsForeignEntries(t *testing.T) {
+	_, err := ParseDeadLetter(redis.XMessage{ID: "1-0", Values: map[string]interface{}{"payload": "{}"}})
+
+	assert.Error(t, err)
+}
+
+func TestNextRetryCursor(t *testing.T) {
+	full := make([]redis.XPendingExt, retryScanSize)
+	for i := range full {
+		full[i].ID = fmt.Sprintf("%d-0", i+1)
+	}
+
+	assert.Equal(t, "100-0", nextRetryCursor("", full, retryScanSize), "a full page goes on after its last message")
+	assert.Equal(t, "", nextRetryCursor("100-0", full[:40], 40), "the end of the pending list starts over")
+	assert.Equal(t, "", nextRetryCursor("100-0", nil, 0), "an empty page starts over")
+	assert.Equal(t, "7-0", nextRetryCursor("", full, 7), "a retry that stopped early goes on after the last message looked at")
+	assert.Equal(t, "50-0", nextRetryCursor("50-0", full, 0), "a retry that looked at nothing keeps its cursor")
+}
@@ -1,15 +1,24 @@
 package telegram
 
 import (
+	"context"
+	"net/http"
+
+	"golang-stock-scryper/pkg/metrics"
+	"golang-stock-scryper/pkg/tracing"
 	"golang-stock-scryper/pkg/utils"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Notifier defines the interface for a Telegram notifier.
 type Notifier interface {
-	SendMessage(text string, msgConfig ...tgbotapi.MessageConfig) error
-	SendMessageUser(text string, chatID int64, msgConfig ...tgbotapi.MessageConfig) error
+	SendMessage(ctx context.Context, text string, msgConfig ...tgbotapi.MessageConfig) error
+	SendMessageUser(ctx context.Context, text string, chatID int64, msgConfig ...tgbotapi.MessageConfig) error
+	Ping(ctx context.Context) error
 }
 
 // client is an implementation of Notifier.
@@ -20,7 +29,8 @@ type client struct {
 
 // NewClient creates a new Telegram notifier client.
 func NewClient(botToken string, chatID int64) (Notifier, error) {
-	bot, err := tgbotapi.NewBotAPI(botToken)
+	httpClient := &http.Client{Transport: metrics.NewTransport(metrics.ProviderTelegram, nil)}
+	bot, err := tgbotapi.NewBotAPIWithClient(botToken, tgbotapi.APIEndpoint, httpClient)
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +41,7 @@ func NewClient(botToken string, chatID int64) (Notifier, error) {
 }
 
 // SendMessage sends a message to the configured Telegram chat.
-func (c *client) SendMessage(text string, msgConfig ...tgbotapi.MessageConfig) error {
+func (c *client) SendMessage(ctx context.Context, text string, msgConfig ...tgbotapi.MessageConfig) error {
 	parseMode := tgbotapi.ModeMarkdownV2
 
 	if len(msgConfig) > 0 {
@@ -43,12 +53,11 @@ func (c *client) SendMessage(text string, msgConfig ...tgbotapi.MessageConfig) e
 
 	msg := tgbotapi.NewMessage(c.chatID, text)
 	msg.ParseMode = parseMode
-	_, err := c.bot.Send(msg)
-	return err
+	return c.send(ctx, msg)
 }
 
 // SendMessageUser sends a message to user
-func (c *client) SendMessageUser(text string, chatID int64, msgConfig ...tgbotapi.MessageConfig) error {
+func (c *client) SendMessageUser(ctx context.Context, text string, chatID int64, msgConfig ...tgbotapi.MessageConfig) error {
 	parseMode := tgbotapi.ModeMarkdownV2
 
 	if len(msgConfig) > 0 {
@@ -60,6 +69,28 @@ func (c *client) SendMessageUser(text string, chatID int64, msgConfig ...tgbotap
 
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = parseMode
+	return c.send(ctx, msg)
+}
+
+// Ping checks that the bot token is still accepted by the Telegram API.
+func (c *client) Ping(ctx context.Context) error {
+	_, span := tracing.Tracer().Start(ctx, metrics.ProviderTelegram+" getMe", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.PeerService(metrics.ProviderTelegram)))
+	defer span.End()
+
+	_, err := c.bot.GetMe()
+	tracing.RecordError(span, err)
+	return err
+}
+
+// send sends msg within a client span. The bot API client does not take a context, so the span
+// is recorded here rather than by the HTTP transport.
+func (c *client) send(ctx context.Context, msg tgbotapi.MessageConfig) error {
+	_, span := tracing.Tracer().Start(ctx, metrics.ProviderTelegram+" sendMessage", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.PeerService(metrics.ProviderTelegram), attribute.Int64("telegram.chat_id", msg.ChatID)))
+	defer span.End()
+
 	_, err := c.bot.Send(msg)
+	tracing.RecordError(span, err)
 	return err
 }
@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTransport wraps base, or http.DefaultTransport when base is nil, so every request made
+// within a trace records a client span named after the provider and propagates the trace
+// context to it. Requests outside of a trace are sent unchanged.
+func NewTransport(provider string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{
+		base: base,
+		traced: otelhttp.NewTransport(base,
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+				return provider + " " + r.Method
+			}),
+			otelhttp.WithSpanOptions(trace.WithAttributes(semconv.PeerService(provider))),
+		),
+	}
+}
+
+type transport struct {
+	base   http.RoundTripper
+	traced http.RoundTripper
+}
+
+// RoundTrip sends the request through the traced transport when its context carries a span.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !trace.SpanContextFromContext(req.Context()).IsValid() {
+		return t.base.RoundTrip(req)
+	}
+	return t.traced.RoundTrip(req)
+}
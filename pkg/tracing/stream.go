@@ -0,0 +1,37 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartPublish starts the producer span of a message added to a Redis stream. The carrier of the
+// message is taken from the returned context with Inject.
+func StartPublish(ctx context.Context, stream string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, "publish "+stream,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(streamAttributes(stream, "publish")...),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// StartProcess starts the consumer span of a message read from a Redis stream, continuing the
+// trace of the producer recorded in carrier.
+func StartProcess(ctx context.Context, stream string, carrier map[string]string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(Extract(ctx, carrier), "process "+stream,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(streamAttributes(stream, "process")...),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func streamAttributes(stream, operation string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("redis"),
+		semconv.MessagingDestinationName(stream),
+		semconv.MessagingOperationName(operation),
+	}
+}
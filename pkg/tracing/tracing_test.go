@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestRecorder installs a tracer provider that records every span in memory.
+func newTestRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestStreamSpansContinueTheProducerTrace(t *testing.T) {
+	recorder := newTestRecorder(t)
+
+	publishCtx, publishSpan := StartPublish(context.Background(), "stock.analyzer")
+	carrier := Inject(publishCtx)
+	publishSpan.End()
+	require.Contains(t, carrier, "traceparent")
+
+	_, processSpan := StartProcess(context.Background(), "stock.analyzer", carrier)
+	processSpan.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "publish stock.analyzer", spans[0].Name())
+	assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind())
+	assert.Equal(t, "process stock.analyzer", spans[1].Name())
+	assert.Equal(t, trace.SpanKindConsumer, spans[1].SpanKind())
+	assert.Equal(t, spans[0].SpanContext().TraceID(), spans[1].SpanContext().TraceID())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), spans[1].Parent().SpanID())
+}
+
+func TestInjectWithoutSpan(t *testing.T) {
+	newTestRecorder(t)
+
+	assert.Nil(t, Inject(context.Background()))
+}
+
+func TestTransportTracesRequestsWithinATrace(t *testing.T) {
+	recorder := newTestRecorder(t)
+	var traceparents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: NewTransport("test_provider", nil)}
+
+	get := func(ctx context.Context) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	get(context.Background())
+	ctx, parent := Tracer().Start(context.Background(), "parent")
+	get(ctx)
+	parent.End()
+
+	require.Len(t, traceparents, 2)
+	assert.Empty(t, traceparents[0], "requests outside of a trace are sent unchanged")
+	assert.NotEmpty(t, traceparents[1])
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "test_provider GET", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}